@@ -1,89 +1,45 @@
 package main
 
 import (
+	"fmt"
 	"log"
-	"net/http"
 	"os"
+	"strings"
 
-	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/handlers"
-	customMiddleware "github.com/CristianSsousa/go-api-actions-ci-cd/internal/middleware"
-	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
-	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	chiCors "github.com/go-chi/cors"
-	chiRender "github.com/go-chi/render"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/config"
 )
 
-func main() {
-	// Inicializa repositórios
-	userRepo := repositories.NewUserRepository()
-	productRepo := repositories.NewProductRepository()
-
-	// Inicializa serviços
-	userService := services.NewUserService(userRepo)
-	productService := services.NewProductService(productRepo)
-
-	// Inicializa handlers
-	userHandler := handlers.NewUserHandler(userService)
-	productHandler := handlers.NewProductHandler(productService)
-	healthHandler := handlers.NewHealthHandler()
-
-	// Configura router
-	r := chi.NewRouter()
-
-	// Middlewares globais
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Recoverer)
-	r.Use(customMiddleware.Logger)
-	r.Use(chiRender.SetContentType(chiRender.ContentTypeJSON))
-
-	// CORS
-	r.Use(chiCors.Handler(chiCors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
+const usage = `Uso:
+  api [serve] [-require-migrations]   inicia o servidor HTTP
+  api migrate up                      aplica todas as migrações pendentes
+  api migrate down N                  reverte as últimas N migrações
+  api migrate status                  lista as migrações e seu estado`
 
-	// Rotas de health check
-	r.Get("/health", healthHandler.Check)
-	r.Get("/", healthHandler.Check)
-
-	// Rotas de usuários
-	r.Route("/api/users", func(r chi.Router) {
-		r.Get("/", userHandler.GetAll)
-		r.Get("/{id}", userHandler.GetByID)
-		r.Post("/", userHandler.Create)
-		r.Put("/{id}", userHandler.Update)
-		r.Delete("/{id}", userHandler.Delete)
-	})
-
-	// Rotas de produtos
-	r.Route("/api/products", func(r chi.Router) {
-		r.Get("/", productHandler.GetAll)
-		r.Get("/{id}", productHandler.GetByID)
-		r.Get("/category/{category}", productHandler.GetByCategory)
-		r.Post("/", productHandler.Create)
-		r.Put("/{id}", productHandler.Update)
-		r.Delete("/{id}", productHandler.Delete)
-	})
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Erro: ", err)
+	}
 
-	// Obtém a porta do ambiente ou usa 8080 como padrão
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Sem subcomando (ou apenas flags), o comportamento padrão é iniciar o servidor
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	log.Printf("Servidor iniciado na porta %s", port)
-	log.Printf("Health check: http://localhost:%s/health", port)
-	log.Printf("API de usuários: http://localhost:%s/api/users", port)
-	log.Printf("API de produtos: http://localhost:%s/api/products", port)
+	switch command {
+	case "serve":
+		err = runServe(cfg, args)
+	case "migrate":
+		err = runMigrate(cfg, args)
+	case "help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
 
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatal("Erro ao iniciar servidor:", err)
+	if err != nil {
+		log.Fatal("Erro: ", err)
 	}
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/config"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/database"
+)
+
+// runMigrate executa os subcomandos "migrate up", "migrate down N" e "migrate status"
+func runMigrate(cfg config.Config, args []string) error {
+	if cfg.Database.Driver != config.DriverSQLite {
+		return fmt.Errorf("migrações exigem DB_DRIVER=%s (atual: %s)", config.DriverSQLite, cfg.Database.Driver)
+	}
+	if len(args) == 0 {
+		return errors.New("informe o subcomando: up, down N ou status")
+	}
+
+	db, err := database.OpenSQLite(cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("aplicada  %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("nenhuma migração pendente")
+		}
+		return err
+
+	case "down":
+		if len(args) < 2 {
+			return errors.New("uso: api migrate down N")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("%w: %s", database.ErrInvalidSteps, args[1])
+		}
+		reverted, err := migrator.Down(ctx, n)
+		for _, m := range reverted {
+			fmt.Printf("revertida %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("nenhuma migração aplicada")
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSÃO\tNOME\tESTADO\tAPLICADA EM")
+		for _, s := range statuses {
+			state := "pendente"
+			if s.Applied {
+				state = "aplicada"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, s.AppliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("subcomando de migração desconhecido: %s", args[0])
+	}
+}
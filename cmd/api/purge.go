@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/config"
+)
+
+// purgeTarget associa um conjunto de registros à função que expurga sua lixeira
+type purgeTarget struct {
+	name  string
+	purge func(ctx context.Context, retention time.Duration) (int, error)
+}
+
+// runPurger remove periodicamente os registros que estão na lixeira há mais
+// tempo que a retenção configurada, até que ctx seja cancelado
+func runPurger(ctx context.Context, cfg config.TrashConfig, targets ...purgeTarget) {
+	if cfg.Retention == 0 {
+		log.Printf("Limpeza da lixeira desativada (TRASH_RETENTION=0)")
+		return
+	}
+
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, target := range targets {
+			purged, err := target.purge(ctx, cfg.Retention)
+			if err != nil {
+				log.Printf("Erro ao limpar a lixeira de %s: %v", target.name, err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Lixeira de %s: %d registro(s) removido(s) definitivamente", target.name, purged)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runCartPurger remove periodicamente os carrinhos expirados, até que ctx
+// seja cancelado. Carrinhos expirados já são ignorados nas leituras; a
+// limpeza apenas libera o espaço ocupado por eles, mesmo com a remoção
+// definitiva da lixeira desativada.
+func runCartPurger(ctx context.Context, interval time.Duration, purge func(ctx context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := purge(ctx)
+		if err != nil {
+			log.Printf("Erro ao remover carrinhos expirados: %v", err)
+		} else if purged > 0 {
+			log.Printf("Carrinhos expirados: %d removido(s)", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runPriceScheduler aplica periodicamente os inícios e términos de
+// agendamentos de preço devidos, até que ctx seja cancelado. As leituras de
+// produtos também aplicam os agendamentos devidos, então o intervalo define
+// apenas a defasagem máxima dos produtos que ninguém consultou.
+func runPriceScheduler(ctx context.Context, interval time.Duration, apply func(ctx context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := apply(ctx)
+		if err != nil {
+			log.Printf("Erro ao aplicar agendamentos de preço: %v", err)
+		} else if applied > 0 {
+			log.Printf("Agendamentos de preço: %d aplicado(s)", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/config"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/cursor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/database"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/handlers"
+	customMiddleware "github.com/CristianSsousa/go-api-actions-ci-cd/internal/middleware"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	chiCors "github.com/go-chi/cors"
+	chiRender "github.com/go-chi/render"
+)
+
+// runServe inicia o servidor HTTP
+func runServe(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	requireMigrations := flags.Bool("require-migrations", false, "recusa iniciar se houver migrações pendentes em vez de aplicá-las")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Inicializa repositórios
+	st, err := newStores(cfg.Database, *requireMigrations)
+	if err != nil {
+		return fmt.Errorf("inicializar repositórios: %w", err)
+	}
+	if st.db != nil {
+		defer st.db.Close()
+	}
+
+	cursors, err := newCursorCodec(cfg.CursorSecret)
+	if err != nil {
+		return fmt.Errorf("inicializar cursores: %w", err)
+	}
+
+	// Inicializa serviços
+	userService := services.NewUserService(st.users, cursors)
+	categoryService := services.NewCategoryService(st.categories, st.products)
+	productService := services.NewProductService(st.products, st.categories, cursors, cfg.Stock.ReservationTTL)
+	cartService := services.NewCartService(st.carts, userService, productService, cfg.Cart.TTL)
+	promotionService := services.NewPromotionService(st.promotions, st.categories, userService, productService, cartService)
+	orderService := services.NewOrderService(st.orders, userService, productService, promotionService, cursors)
+
+	// Limpeza periódica da lixeira
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go runPurger(purgeCtx, cfg.Trash,
+		purgeTarget{name: "usuários", purge: userService.Purge},
+		purgeTarget{name: "produtos", purge: productService.Purge},
+	)
+	go runCartPurger(purgeCtx, cfg.Cart.PurgeInterval, cartService.PurgeExpired)
+
+	// Aplicação dos agendamentos de preço
+	go runPriceScheduler(purgeCtx, cfg.Prices.ScheduleInterval, productService.ApplyPriceSchedules)
+
+	// Inicializa handlers
+	userHandler := handlers.NewUserHandler(userService)
+	productHandler := handlers.NewProductHandler(productService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	healthHandler := handlers.NewHealthHandler()
+
+	// Configura router
+	r := chi.NewRouter()
+
+	// Middlewares globais
+	r.Use(middleware.RequestID)
+	r.Use(middleware.RealIP)
+	r.Use(middleware.Recoverer)
+	r.Use(customMiddleware.Logger)
+	r.Use(chiRender.SetContentType(chiRender.ContentTypeJSON))
+	r.Use(customMiddleware.Language)
+	r.Use(customMiddleware.Actor)
+
+	// CORS
+	r.Use(chiCors.Handler(chiCors.Options{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "If-None-Match", "X-Actor"},
+		ExposedHeaders:   []string{"Link", "ETag"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// Rotas de health check
+	r.Get("/health", healthHandler.Check)
+	r.Get("/", healthHandler.Check)
+
+	// Rotas de usuários
+	r.Route("/api/users", func(r chi.Router) {
+		r.Get("/", userHandler.GetAll)
+		r.Get("/{id}", userHandler.GetByID)
+		r.Post("/", userHandler.Create)
+		r.Put("/{id}", userHandler.Update)
+		r.Patch("/{id}", userHandler.Patch)
+		r.Delete("/{id}", userHandler.Delete)
+		r.Post("/{id}/activate", userHandler.Activate)
+		r.Post("/{id}/deactivate", userHandler.Deactivate)
+		r.Get("/{id}/cart", cartHandler.Get)
+		r.Delete("/{id}/cart", cartHandler.Clear)
+		r.Post("/{id}/cart/items", cartHandler.AddItem)
+		r.Put("/{id}/cart/items/{productID}", cartHandler.UpdateItem)
+		r.Delete("/{id}/cart/items/{productID}", cartHandler.RemoveItem)
+		r.Post("/{id}/cart/evaluate", promotionHandler.EvaluateCart)
+	})
+
+	// Rotas de produtos
+	r.Route("/api/products", func(r chi.Router) {
+		r.Get("/", productHandler.GetAll)
+		r.Get("/search", productHandler.Search)
+		r.Get("/{id}", productHandler.GetByID)
+		r.Get("/category/{slug}", productHandler.GetByCategory)
+		r.Post("/", productHandler.Create)
+		r.Put("/{id}", productHandler.Update)
+		r.Patch("/{id}", productHandler.Patch)
+		r.Delete("/{id}", productHandler.Delete)
+		r.Post("/{id}/activate", productHandler.Activate)
+		r.Post("/{id}/deactivate", productHandler.Deactivate)
+		r.Get("/{id}/stock/movements", productHandler.GetMovements)
+		r.Post("/{id}/stock/increment", productHandler.IncrementStock)
+		r.Post("/{id}/stock/decrement", productHandler.DecrementStock)
+		r.Post("/{id}/reservations", productHandler.Reserve)
+		r.Get("/{id}/variants", productHandler.GetVariants)
+		r.Post("/{id}/variants", productHandler.CreateVariant)
+		r.Get("/{id}/variants/{variantID}", productHandler.GetVariant)
+		r.Put("/{id}/variants/{variantID}", productHandler.UpdateVariant)
+		r.Delete("/{id}/variants/{variantID}", productHandler.DeleteVariant)
+		r.Get("/{id}/prices", productHandler.GetPriceHistory)
+		r.Get("/{id}/prices/schedules", productHandler.GetPriceSchedules)
+		r.Post("/{id}/prices/schedules", productHandler.SchedulePrice)
+		r.Delete("/{id}/prices/schedules/{scheduleID}", productHandler.CancelPriceSchedule)
+	})
+
+	// Rotas de categorias
+	r.Route("/api/categories", func(r chi.Router) {
+		r.Get("/", categoryHandler.GetTree)
+		r.Get("/{id}", categoryHandler.GetByID)
+		r.Post("/", categoryHandler.Create)
+		r.Put("/{id}", categoryHandler.Update)
+		r.Delete("/{id}", categoryHandler.Delete)
+	})
+
+	// Rotas de promoções e cupons
+	r.Route("/api/promotions", func(r chi.Router) {
+		r.Get("/", promotionHandler.GetAll)
+		r.Get("/{id}", promotionHandler.GetByID)
+		r.Post("/", promotionHandler.Create)
+		r.Put("/{id}", promotionHandler.Update)
+		r.Delete("/{id}", promotionHandler.Delete)
+		r.Post("/evaluate", promotionHandler.Evaluate)
+	})
+
+	// Rotas da lixeira
+	r.Route("/api/trash", func(r chi.Router) {
+		r.Get("/users", userHandler.GetTrash)
+		r.Post("/users/{id}/restore", userHandler.Restore)
+		r.Get("/products", productHandler.GetTrash)
+		r.Post("/products/{id}/restore", productHandler.Restore)
+	})
+
+	// Rotas das reservas de estoque
+	r.Route("/api/reservations", func(r chi.Router) {
+		r.Get("/{id}", productHandler.GetReservation)
+		r.Post("/{id}/commit", productHandler.CommitReservation)
+		r.Post("/{id}/release", productHandler.ReleaseReservation)
+	})
+
+	// Rotas de pedidos
+	r.Route("/api/orders", func(r chi.Router) {
+		r.Get("/", orderHandler.GetAll)
+		r.Get("/{id}", orderHandler.GetByID)
+		r.Post("/", orderHandler.Create)
+		r.Post("/{id}/pay", orderHandler.Pay)
+		r.Post("/{id}/ship", orderHandler.Ship)
+		r.Post("/{id}/cancel", orderHandler.Cancel)
+		r.Post("/{id}/refund", orderHandler.Refund)
+	})
+
+	port := cfg.Port
+
+	log.Printf("Servidor iniciado na porta %s (persistência: %s)", port, cfg.Database.Driver)
+	log.Printf("Health check: http://localhost:%s/health", port)
+	log.Printf("API de usuários: http://localhost:%s/api/users", port)
+	log.Printf("API de produtos: http://localhost:%s/api/products", port)
+	log.Printf("API de categorias: http://localhost:%s/api/categories", port)
+	log.Printf("API de pedidos: http://localhost:%s/api/orders", port)
+	log.Printf("API de promoções: http://localhost:%s/api/promotions", port)
+
+	if err := http.ListenAndServe(":"+port, r); err != nil {
+		return fmt.Errorf("iniciar servidor: %w", err)
+	}
+	return nil
+}
+
+// stores agrupa os repositórios da aplicação e a conexão que os sustenta,
+// nula no driver em memória
+type stores struct {
+	users      repositories.UserStore
+	products   repositories.ProductStore
+	categories repositories.CategoryStore
+	orders     repositories.OrderStore
+	carts      repositories.CartStore
+	promotions repositories.PromotionStore
+	db         *sql.DB
+}
+
+// newStores cria os repositórios de acordo com o driver configurado.
+// No SQLite, as migrações pendentes são aplicadas, a menos que requireMigrations
+// esteja ativo, caso em que a inicialização é recusada.
+func newStores(cfg config.DatabaseConfig, requireMigrations bool) (stores, error) {
+	switch cfg.Driver {
+	case config.DriverMemory:
+		return stores{
+			users:      repositories.NewUserRepository(),
+			products:   repositories.NewProductRepository(),
+			categories: repositories.NewCategoryRepository(),
+			orders:     repositories.NewOrderRepository(),
+			carts:      repositories.NewCartRepository(),
+			promotions: repositories.NewPromotionRepository(),
+		}, nil
+	case config.DriverSQLite:
+		db, err := database.OpenSQLite(cfg.Path)
+		if err != nil {
+			return stores{}, err
+		}
+		if err := prepareSchema(db, requireMigrations); err != nil {
+			db.Close()
+			return stores{}, err
+		}
+		return stores{
+			users:      repositories.NewSQLiteUserRepository(db),
+			products:   repositories.NewSQLiteProductRepository(db),
+			categories: repositories.NewSQLiteCategoryRepository(db),
+			orders:     repositories.NewSQLiteOrderRepository(db),
+			carts:      repositories.NewSQLiteCartRepository(db),
+			promotions: repositories.NewSQLitePromotionRepository(db),
+			db:         db,
+		}, nil
+	default:
+		return stores{}, fmt.Errorf("driver de banco desconhecido: %q", cfg.Driver)
+	}
+}
+
+// newCursorCodec cria o codec de cursores, gerando uma chave aleatória se nenhuma foi configurada
+func newCursorCodec(secret string) (*cursor.Codec, error) {
+	if secret != "" {
+		return cursor.NewCodec([]byte(secret)), nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	log.Printf("CURSOR_SECRET não definido: usando chave aleatória (cursores expiram ao reiniciar)")
+	return cursor.NewCodec(key), nil
+}
+
+// prepareSchema aplica as migrações pendentes ou falha se requireMigrations estiver ativo
+func prepareSchema(db *sql.DB, requireMigrations bool) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if requireMigrations {
+		return migrator.EnsureUpToDate(ctx)
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		log.Printf("Migração aplicada: %04d_%s", m.Version, m.Name)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+// Package actor identifica quem realizou uma operação, para que os históricos
+// (como as movimentações de estoque) registrem o responsável por cada alteração.
+//
+// A API ainda não autentica as requisições: o ator é informado pelo cliente no
+// header X-Actor e serve apenas para auditoria, não para autorização.
+package actor
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Header é o header HTTP que identifica o ator da requisição
+	Header = "X-Actor"
+	// Anonymous identifica requisições sem ator informado
+	Anonymous = "anonymous"
+	// System identifica alterações feitas pela própria aplicação
+	System = "system"
+	// maxLength é o tamanho máximo, em caracteres, de um identificador
+	maxLength = 100
+)
+
+type contextKey struct{}
+
+// Normalize retorna o identificador sem espaços nas bordas e sem caracteres de
+// controle, limitado a maxLength caracteres; vazio resulta em Anonymous
+func Normalize(name string) string {
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" {
+		return Anonymous
+	}
+	if utf8.RuneCountInString(name) > maxLength {
+		name = string([]rune(name)[:maxLength])
+	}
+	return name
+}
+
+// WithName retorna uma cópia do contexto com o ator da operação
+func WithName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, Normalize(name))
+}
+
+// FromContext retorna o ator do contexto, ou Anonymous se nenhum foi definido
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(contextKey{}).(string); ok {
+		return name
+	}
+	return Anonymous
+}
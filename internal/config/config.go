@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Drivers de persistência suportados
+const (
+	DriverMemory = "memory"
+	DriverSQLite = "sqlite"
+)
+
+// Config representa a configuração da aplicação carregada do ambiente
+type Config struct {
+	Port     string
+	Database DatabaseConfig
+	// CursorSecret assina os cursores de paginação; se vazio, uma chave
+	// aleatória é gerada na inicialização e os cursores expiram a cada restart
+	CursorSecret string
+	Trash        TrashConfig
+	Stock        StockConfig
+	Cart         CartConfig
+	Prices       PriceConfig
+}
+
+// DatabaseConfig representa a configuração da camada de persistência
+type DatabaseConfig struct {
+	Driver string
+	Path   string
+}
+
+// TrashConfig controla a remoção definitiva dos registros na lixeira
+type TrashConfig struct {
+	// Retention é o tempo que um registro excluído permanece na lixeira;
+	// zero desativa a remoção definitiva
+	Retention time.Duration
+	// PurgeInterval é o intervalo entre as execuções da limpeza
+	PurgeInterval time.Duration
+}
+
+// StockConfig controla as reservas de estoque
+type StockConfig struct {
+	// ReservationTTL é o tempo que uma reserva retém o estoque antes de expirar
+	ReservationTTL time.Duration
+}
+
+// CartConfig controla a expiração dos carrinhos de compras
+type CartConfig struct {
+	// TTL é o tempo sem alterações após o qual um carrinho expira
+	TTL time.Duration
+	// PurgeInterval é o intervalo entre as remoções dos carrinhos expirados
+	PurgeInterval time.Duration
+}
+
+// PriceConfig controla a aplicação dos agendamentos de preço
+type PriceConfig struct {
+	// ScheduleInterval é o intervalo entre as verificações dos agendamentos
+	ScheduleInterval time.Duration
+}
+
+// Load carrega a configuração a partir das variáveis de ambiente
+func Load() (Config, error) {
+	cfg := Config{
+		Port: getEnv("PORT", "8080"),
+		Database: DatabaseConfig{
+			Driver: getEnv("DB_DRIVER", DriverMemory),
+			Path:   getEnv("DB_PATH", "data/api.db"),
+		},
+		CursorSecret: os.Getenv("CURSOR_SECRET"),
+	}
+
+	var err error
+	if cfg.Trash.Retention, err = getDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.Trash.PurgeInterval, err = getDuration("TRASH_PURGE_INTERVAL", time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.Trash.PurgeInterval <= 0 {
+		return cfg, fmt.Errorf("TRASH_PURGE_INTERVAL deve ser positivo")
+	}
+	if cfg.Stock.ReservationTTL, err = getDuration("RESERVATION_TTL", 15*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.Stock.ReservationTTL <= 0 {
+		return cfg, fmt.Errorf("RESERVATION_TTL deve ser positivo")
+	}
+	if cfg.Cart.TTL, err = getDuration("CART_TTL", 7*24*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.Cart.TTL <= 0 {
+		return cfg, fmt.Errorf("CART_TTL deve ser positivo")
+	}
+	if cfg.Cart.PurgeInterval, err = getDuration("CART_PURGE_INTERVAL", time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.Cart.PurgeInterval <= 0 {
+		return cfg, fmt.Errorf("CART_PURGE_INTERVAL deve ser positivo")
+	}
+	if cfg.Prices.ScheduleInterval, err = getDuration("PRICE_SCHEDULE_INTERVAL", time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.Prices.ScheduleInterval <= 0 {
+		return cfg, fmt.Errorf("PRICE_SCHEDULE_INTERVAL deve ser positivo")
+	}
+	return cfg, nil
+}
+
+// getEnv retorna o valor da variável de ambiente ou o valor padrão
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration lê uma duração não negativa no formato de time.ParseDuration (ex.: "720h")
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s inválido: %q (use uma duração como 720h ou 30m)", key, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,72 @@
+// Package cursor codifica posições de paginação por keyset em tokens opacos.
+//
+// O token é o JSON da posição em base64url seguido de uma assinatura
+// HMAC-SHA256, de modo que qualquer alteração feita pelo cliente é detectada.
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+var encoding = base64.RawURLEncoding
+
+// Codec assina e valida cursores com uma chave secreta
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec cria um Codec com a chave informada
+func NewCodec(secret []byte) *Codec {
+	return &Codec{secret: secret}
+}
+
+// Encode converte a posição em um token opaco assinado
+func (c *Codec) Encode(keyset models.Keyset) (string, error) {
+	payload, err := json.Marshal(keyset)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode valida a assinatura do token e retorna a posição codificada
+func (c *Codec) Decode(token string) (*models.Keyset, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var keyset models.Keyset
+	if err := json.Unmarshal(payload, &keyset); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &keyset, nil
+}
+
+// sign calcula o HMAC-SHA256 do payload
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
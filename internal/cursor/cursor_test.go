@@ -0,0 +1,83 @@
+package cursor
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var testKeyset = models.Keyset{Sort: "-price,name", Values: []interface{}{float64(59990), "Mouse"}, ID: 42}
+
+func TestRoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("segredo"))
+
+	token, err := codec.Encode(testKeyset)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, err := codec.Decode(token)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(*got, testKeyset) {
+		t.Errorf("Decode: esperado %+v, obtido %+v", testKeyset, *got)
+	}
+}
+
+func TestDecodeRejectsInvalidTokens(t *testing.T) {
+	codec := NewCodec([]byte("segredo"))
+	token, err := codec.Encode(testKeyset)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+
+	tampered := testKeyset
+	tampered.ID = 43
+	tamperedJSON, err := json.Marshal(tampered)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	otherToken, err := NewCodec([]byte("outra chave")).Encode(testKeyset)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"payload alterado", encoding.EncodeToString(tamperedJSON) + "." + signature},
+		{"assinatura alterada", payload + "." + flipFirst(signature)},
+		{"assinatura de outra chave", otherToken},
+		{"sem assinatura", payload},
+		{"assinatura vazia", payload + "."},
+		{"token vazio", ""},
+		{"payload truncado", payload[:len(payload)-4] + "." + signature},
+		{"assinatura truncada", payload + "." + signature[:len(signature)-4]},
+		{"payload fora do base64url", "!!!." + signature},
+		{"assinatura fora do base64url", payload + ".***"},
+		{"payload que não é JSON", encoding.EncodeToString([]byte("x")) + "." + encoding.EncodeToString(codec.sign([]byte("x")))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q): esperado ErrInvalidCursor, obtido %v", tt.token, err)
+			}
+		})
+	}
+}
+
+// flipFirst troca o primeiro caractere do texto base64url por outro válido;
+// o último não serve, pois pode carregar apenas bits de preenchimento
+func flipFirst(s string) string {
+	replacement := "A"
+	if s[0] == 'A' {
+		replacement = "B"
+	}
+	return replacement + s[1:]
+}
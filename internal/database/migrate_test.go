@@ -0,0 +1,282 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// openTestDB abre um banco SQLite vazio em um diretório temporário
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "api.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newTestMigrator cria um Migrator com as migrações embutidas
+func newTestMigrator(t *testing.T, db *sql.DB) *Migrator {
+	t.Helper()
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	return migrator
+}
+
+// tables retorna as tabelas do banco, exceto as internas do SQLite
+func tables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		t.Fatalf("listar tabelas: %v", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("listar tabelas: %v", err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// versions retorna as versões das migrações, na ordem
+func versions(migrations []Migration) []int {
+	var list []int
+	for _, m := range migrations {
+		list = append(list, m.Version)
+	}
+	return list
+}
+
+func TestMigratorRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := newTestMigrator(t, db)
+	all := versions(migrator.migrations)
+
+	if err := migrator.EnsureUpToDate(ctx); !errors.Is(err, ErrPendingMigrations) {
+		t.Fatalf("EnsureUpToDate antes de Up: esperado ErrPendingMigrations, obtido %v", err)
+	}
+
+	done, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := versions(done); !reflect.DeepEqual(got, all) {
+		t.Fatalf("Up: esperado %v, obtido %v", all, got)
+	}
+	if err := migrator.EnsureUpToDate(ctx); err != nil {
+		t.Fatalf("EnsureUpToDate após Up: %v", err)
+	}
+	if done, err := migrator.Up(ctx); err != nil || len(done) != 0 {
+		t.Fatalf("Up repetido: esperado nenhuma migração, obtido %v (%v)", versions(done), err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.AppliedAt == "" {
+			t.Errorf("Status: migração %04d_%s não consta como aplicada", status.Version, status.Name)
+		}
+	}
+	schema := tables(t, db)
+
+	done, err = migrator.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("Down(2): %v", err)
+	}
+	last := []int{all[len(all)-1], all[len(all)-2]}
+	if got := versions(done); !reflect.DeepEqual(got, last) {
+		t.Fatalf("Down(2): esperado %v, obtido %v", last, got)
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if got, want := versions(pending), []int{last[1], last[0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending: esperado %v, obtido %v", want, got)
+	}
+	if err := migrator.EnsureUpToDate(ctx); !errors.Is(err, ErrPendingMigrations) {
+		t.Errorf("EnsureUpToDate após Down: esperado ErrPendingMigrations, obtido %v", err)
+	}
+
+	if _, err := migrator.Down(ctx, 0); !errors.Is(err, ErrInvalidSteps) {
+		t.Errorf("Down(0): esperado ErrInvalidSteps, obtido %v", err)
+	}
+	done, err = migrator.Down(ctx, len(all)+10)
+	if err != nil {
+		t.Fatalf("Down de todas: %v", err)
+	}
+	if len(done) != len(all)-2 {
+		t.Errorf("Down de todas: esperado %d migrações, obtido %d", len(all)-2, len(done))
+	}
+	if got := tables(t, db); !reflect.DeepEqual(got, []string{"schema_migrations"}) {
+		t.Errorf("Down de todas: esperado apenas schema_migrations, obtido %v", got)
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up após Down: %v", err)
+	}
+	if got := tables(t, db); !reflect.DeepEqual(got, schema) {
+		t.Errorf("Up após Down: esperado o schema %v, obtido %v", schema, got)
+	}
+}
+
+func TestCategoriesMigrationConvertsProducts(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := newTestMigrator(t, db)
+	all := migrator.migrations
+
+	// aplica apenas as migrações anteriores às categorias
+	var before []Migration
+	for _, m := range all {
+		if m.Version < 12 {
+			before = append(before, m)
+		}
+	}
+	migrator.migrations = before
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up até 0011: %v", err)
+	}
+
+	for _, category := range []string{"Periféricos", "Perifericos", "Periféricos", "", "Eletrônicos"} {
+		if _, err := db.ExecContext(ctx, "INSERT INTO products (name, category) VALUES (?, ?)", "Produto "+category, category); err != nil {
+			t.Fatalf("inserir produto: %v", err)
+		}
+	}
+
+	migrator.migrations = all
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT name, slug FROM categories WHERE slug <> 'geral' ORDER BY slug")
+	if err != nil {
+		t.Fatalf("listar categorias: %v", err)
+	}
+	defer rows.Close()
+	var categories [][2]string
+	for rows.Next() {
+		var c [2]string
+		if err := rows.Scan(&c[0], &c[1]); err != nil {
+			t.Fatalf("listar categorias: %v", err)
+		}
+		categories = append(categories, c)
+	}
+	want := [][2]string{{"Eletrônicos", "eletronicos"}, {"Periféricos", "perifericos"}}
+	if !reflect.DeepEqual(categories, want) {
+		t.Errorf("categorias: esperado %v, obtido %v", want, categories)
+	}
+
+	products, err := db.QueryContext(ctx, "SELECT p.id, p.category, c.slug FROM products p JOIN categories c ON c.id = p.category_id ORDER BY p.id")
+	if err != nil {
+		t.Fatalf("listar produtos: %v", err)
+	}
+	defer products.Close()
+	wantProducts := [][2]string{
+		{"Periféricos", "perifericos"},
+		{"Periféricos", "perifericos"},
+		{"Periféricos", "perifericos"},
+		{"Geral", "geral"},
+		{"Eletrônicos", "eletronicos"},
+	}
+	var got [][2]string
+	for products.Next() {
+		var id int
+		var p [2]string
+		if err := products.Scan(&id, &p[0], &p[1]); err != nil {
+			t.Fatalf("listar produtos: %v", err)
+		}
+		got = append(got, p)
+	}
+	if !reflect.DeepEqual(got, wantProducts) {
+		t.Errorf("produtos: esperado %v, obtido %v", wantProducts, got)
+	}
+
+	// a volta de 0012 mantém em category o nome da categoria convertida
+	if _, err := migrator.Down(ctx, len(all)-len(before)); err != nil {
+		t.Fatalf("Down até 0011: %v", err)
+	}
+	var merged int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products WHERE category = 'Periféricos'").Scan(&merged); err != nil {
+		t.Fatalf("contar produtos: %v", err)
+	}
+	if merged != 3 {
+		t.Errorf("Down: esperado 3 produtos em Periféricos, obtido %d", merged)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int
+		invalid bool
+	}{
+		{
+			name: "ordena por versão",
+			fsys: fstest.MapFS{
+				"m/0002_b.up.sql": file("B"), "m/0002_b.down.sql": file("b"),
+				"m/0001_a.up.sql": file("A"), "m/0001_a.down.sql": file("a"),
+				"m/README.md": file("ignorado"),
+			},
+			want: []int{1, 2},
+		},
+		{
+			name:    "sem o arquivo de volta",
+			fsys:    fstest.MapFS{"m/0001_a.up.sql": file("A")},
+			invalid: true,
+		},
+		{
+			name:    "nomes divergentes",
+			fsys:    fstest.MapFS{"m/0001_a.up.sql": file("A"), "m/0001_b.down.sql": file("b")},
+			invalid: true,
+		},
+		{
+			name:    "direção desconhecida",
+			fsys:    fstest.MapFS{"m/0001_a.sideways.sql": file("A")},
+			invalid: true,
+		},
+		{
+			name:    "versão inválida",
+			fsys:    fstest.MapFS{"m/0000_a.up.sql": file("A"), "m/0000_a.down.sql": file("a")},
+			invalid: true,
+		},
+		{
+			name:    "sem versão",
+			fsys:    fstest.MapFS{"m/a.up.sql": file("A")},
+			invalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.fsys, "m")
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("loadMigrations: esperado erro, obtido %v", versions(migrations))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadMigrations: %v", err)
+			}
+			if got := versions(migrations); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadMigrations: esperado %v, obtido %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+-- IF NOT EXISTS mantém compatibilidade com bancos criados antes das migrações
+CREATE TABLE IF NOT EXISTS users (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT    NOT NULL,
+	email      TEXT    NOT NULL,
+	role       TEXT    NOT NULL,
+	active     INTEGER NOT NULL DEFAULT 1,
+	created_at TEXT    NOT NULL
+);
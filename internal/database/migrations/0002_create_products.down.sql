@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_category;
+DROP TABLE IF EXISTS products;
@@ -0,0 +1,12 @@
+-- IF NOT EXISTS mantém compatibilidade com bancos criados antes das migrações
+CREATE TABLE IF NOT EXISTS products (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT    NOT NULL,
+	description TEXT    NOT NULL DEFAULT '',
+	price       REAL    NOT NULL,
+	stock       INTEGER NOT NULL DEFAULT 0,
+	category    TEXT    NOT NULL,
+	active      INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN deleted_at TEXT;
+ALTER TABLE products ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
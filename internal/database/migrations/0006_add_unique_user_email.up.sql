@@ -0,0 +1,3 @@
+UPDATE users SET email = LOWER(TRIM(email));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email COLLATE NOCASE) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- A moeda é descartada: os valores voltam a ser interpretados em reais
+ALTER TABLE products ADD COLUMN price REAL NOT NULL DEFAULT 0;
+
+UPDATE products SET price = price_amount / 100.0;
+
+ALTER TABLE products DROP COLUMN currency;
+ALTER TABLE products DROP COLUMN price_amount;
@@ -0,0 +1,8 @@
+-- Preços passam a ser guardados em unidades menores (centavos) com a moeda
+-- ISO 4217, evitando a imprecisão do REAL. Os valores existentes estão em reais.
+ALTER TABLE products ADD COLUMN price_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN currency TEXT NOT NULL DEFAULT 'BRL';
+
+UPDATE products SET price_amount = CAST(ROUND(price * 100) AS INTEGER);
+
+ALTER TABLE products DROP COLUMN price;
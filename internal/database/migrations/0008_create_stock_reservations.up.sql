@@ -0,0 +1,10 @@
+-- Reservas de estoque; vigentes enquanto expires_at for posterior ao instante atual
+CREATE TABLE stock_reservations (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	quantity   INTEGER NOT NULL CHECK (quantity > 0),
+	created_at TEXT    NOT NULL,
+	expires_at TEXT    NOT NULL
+);
+
+CREATE INDEX idx_stock_reservations_product ON stock_reservations (product_id, expires_at);
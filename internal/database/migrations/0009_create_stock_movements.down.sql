@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS stock_movements_append_only;
+DROP INDEX IF EXISTS idx_stock_movements_product;
+DROP TABLE IF EXISTS stock_movements;
@@ -0,0 +1,26 @@
+-- Histórico de estoque, somente inclusão: quantity é a variação com sinal e
+-- balance o estoque do produto logo após a movimentação
+CREATE TABLE stock_movements (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	type       TEXT    NOT NULL CHECK (type IN ('purchase', 'sale', 'adjustment', 'return')),
+	quantity   INTEGER NOT NULL CHECK (quantity <> 0),
+	balance    INTEGER NOT NULL,
+	reason     TEXT    NOT NULL DEFAULT '',
+	actor      TEXT    NOT NULL,
+	created_at TEXT    NOT NULL
+);
+
+CREATE INDEX idx_stock_movements_product ON stock_movements (product_id, id);
+
+CREATE TRIGGER stock_movements_append_only
+BEFORE UPDATE ON stock_movements
+BEGIN
+	SELECT RAISE(ABORT, 'stock_movements aceita apenas inclusões');
+END;
+
+-- O estoque existente passa a ser o saldo inicial do histórico
+INSERT INTO stock_movements (product_id, type, quantity, balance, reason, actor, created_at)
+SELECT id, 'adjustment', stock, stock, 'saldo inicial', 'system', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM products
+WHERE stock <> 0;
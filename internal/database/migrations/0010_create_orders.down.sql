@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_items;
+DROP INDEX IF EXISTS idx_orders_status;
+DROP INDEX IF EXISTS idx_orders_user;
+DROP TABLE IF EXISTS orders;
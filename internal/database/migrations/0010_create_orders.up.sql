@@ -0,0 +1,27 @@
+-- Pedidos e seus itens. user_id e product_id não têm chave estrangeira para
+-- que a lixeira e o expurgo de usuários e produtos não afetem o histórico de
+-- pedidos; os itens guardam cópias do nome e do preço do produto.
+CREATE TABLE orders (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id      INTEGER NOT NULL,
+	status       TEXT    NOT NULL CHECK (status IN ('pending', 'paid', 'shipped', 'cancelled', 'refunded')),
+	total_amount INTEGER NOT NULL,
+	currency     TEXT    NOT NULL,
+	version      INTEGER NOT NULL DEFAULT 1,
+	created_at   TEXT    NOT NULL,
+	updated_at   TEXT    NOT NULL
+);
+
+CREATE INDEX idx_orders_user ON orders (user_id);
+CREATE INDEX idx_orders_status ON orders (status);
+
+CREATE TABLE order_items (
+	order_id       INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	position       INTEGER NOT NULL,
+	product_id     INTEGER NOT NULL,
+	name           TEXT    NOT NULL,
+	quantity       INTEGER NOT NULL CHECK (quantity > 0),
+	unit_amount    INTEGER NOT NULL,
+	reservation_id INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (order_id, position)
+);
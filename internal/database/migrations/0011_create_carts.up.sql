@@ -0,0 +1,24 @@
+-- Carrinhos de compras, um por usuário. Os itens guardam cópias do nome e do
+-- preço do produto no momento em que foram adicionados; product_id não tem
+-- chave estrangeira para que o expurgo de produtos não afete os carrinhos.
+CREATE TABLE carts (
+	user_id    INTEGER PRIMARY KEY,
+	currency   TEXT    NOT NULL,
+	created_at TEXT    NOT NULL,
+	updated_at TEXT    NOT NULL,
+	expires_at TEXT    NOT NULL
+);
+
+CREATE INDEX idx_carts_expires_at ON carts (expires_at);
+
+CREATE TABLE cart_items (
+	user_id     INTEGER NOT NULL REFERENCES carts (user_id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	product_id  INTEGER NOT NULL,
+	name        TEXT    NOT NULL,
+	quantity    INTEGER NOT NULL CHECK (quantity > 0),
+	unit_amount INTEGER NOT NULL,
+	added_at    TEXT    NOT NULL,
+	PRIMARY KEY (user_id, position),
+	UNIQUE (user_id, product_id)
+);
@@ -0,0 +1,5 @@
+-- Os produtos mantêm em category o nome da categoria, que volta a ser texto livre
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products DROP COLUMN category_id;
+DROP INDEX IF EXISTS idx_categories_parent;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,54 @@
+-- Categorias em árvore, identificadas por slug. As categorias em texto livre
+-- dos produtos são convertidas em categorias raiz: nomes com o mesmo slug
+-- ("Periféricos" e "Perifericos") viram uma só, com a grafia mais usada, e
+-- produtos sem categoria reconhecível ficam em "Geral". O slug é calculado
+-- aqui removendo os acentos comuns do português e do espanhol e trocando
+-- espaços e pontuação por hífens.
+--
+-- parent_id e products.category_id não têm chave estrangeira: a existência das
+-- categorias é verificada pelo serviço, como nos demais repositórios.
+CREATE TABLE categories (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	name      TEXT    NOT NULL,
+	slug      TEXT    NOT NULL UNIQUE,
+	parent_id INTEGER
+);
+
+CREATE INDEX idx_categories_parent ON categories (parent_id);
+
+ALTER TABLE products ADD COLUMN category_id INTEGER;
+
+CREATE TEMP TABLE category_slugs AS
+SELECT category AS name, lower(category) AS slug, COUNT(*) AS products
+FROM products
+GROUP BY category;
+
+UPDATE category_slugs SET slug = replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(slug, 'á', 'a'), 'à', 'a'), 'â', 'a'), 'ã', 'a'), 'ä', 'a'), 'Á', 'a'), 'À', 'a'), 'Â', 'a'), 'Ã', 'a'), 'Ä', 'a');
+UPDATE category_slugs SET slug = replace(replace(replace(replace(replace(replace(replace(replace(slug, 'é', 'e'), 'è', 'e'), 'ê', 'e'), 'ë', 'e'), 'É', 'e'), 'È', 'e'), 'Ê', 'e'), 'Ë', 'e');
+UPDATE category_slugs SET slug = replace(replace(replace(replace(replace(replace(replace(replace(slug, 'í', 'i'), 'ì', 'i'), 'î', 'i'), 'ï', 'i'), 'Í', 'i'), 'Ì', 'i'), 'Î', 'i'), 'Ï', 'i');
+UPDATE category_slugs SET slug = replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(slug, 'ó', 'o'), 'ò', 'o'), 'ô', 'o'), 'õ', 'o'), 'ö', 'o'), 'Ó', 'o'), 'Ò', 'o'), 'Ô', 'o'), 'Õ', 'o'), 'Ö', 'o');
+UPDATE category_slugs SET slug = replace(replace(replace(replace(replace(replace(replace(replace(slug, 'ú', 'u'), 'ù', 'u'), 'û', 'u'), 'ü', 'u'), 'Ú', 'u'), 'Ù', 'u'), 'Û', 'u'), 'Ü', 'u');
+UPDATE category_slugs SET slug = replace(replace(slug, 'ç', 'c'), 'Ç', 'c');
+UPDATE category_slugs SET slug = replace(replace(slug, 'ñ', 'n'), 'Ñ', 'n');
+UPDATE category_slugs SET slug = replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(slug, ',', ' '), '/', ' '), '&', ' '), '.', ' '), '(', ' '), ')', ' '), '_', ' '), '''', ' '), '+', ' '), ':', ' '), ';', ' '), '!', ' '), '?', ' '), '-', ' ');
+UPDATE category_slugs SET slug = replace(replace(replace(trim(slug), '    ', ' '), '  ', ' '), '  ', ' ');
+UPDATE category_slugs SET slug = replace(slug, ' ', '-');
+
+INSERT INTO categories (name, slug)
+SELECT (SELECT trim(s2.name) FROM category_slugs s2 WHERE s2.slug = s.slug ORDER BY s2.products DESC, s2.name LIMIT 1), s.slug
+FROM category_slugs s
+WHERE s.slug <> ''
+GROUP BY s.slug
+ORDER BY MIN(s.name);
+
+INSERT OR IGNORE INTO categories (name, slug) VALUES ('Geral', 'geral');
+
+UPDATE products SET category_id = (
+	SELECT c.id FROM category_slugs s JOIN categories c ON c.slug = s.slug WHERE s.name = products.category
+);
+UPDATE products SET category_id = (SELECT id FROM categories WHERE slug = 'geral') WHERE category_id IS NULL;
+UPDATE products SET category = (SELECT name FROM categories WHERE id = products.category_id);
+
+DROP TABLE category_slugs;
+
+CREATE INDEX idx_products_category_id ON products (category_id);
@@ -0,0 +1,30 @@
+-- Itens de variantes diferentes do mesmo produto não cabem na restrição
+-- original; apenas o primeiro de cada produto é mantido
+CREATE TABLE cart_items_old (
+	user_id     INTEGER NOT NULL REFERENCES carts (user_id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	product_id  INTEGER NOT NULL,
+	name        TEXT    NOT NULL,
+	quantity    INTEGER NOT NULL CHECK (quantity > 0),
+	unit_amount INTEGER NOT NULL,
+	added_at    TEXT    NOT NULL,
+	PRIMARY KEY (user_id, position),
+	UNIQUE (user_id, product_id)
+);
+
+INSERT OR IGNORE INTO cart_items_old (user_id, position, product_id, name, quantity, unit_amount, added_at)
+SELECT user_id, position, product_id, name, quantity, unit_amount, added_at
+FROM cart_items
+ORDER BY user_id, position;
+
+DROP TABLE cart_items;
+ALTER TABLE cart_items_old RENAME TO cart_items;
+
+ALTER TABLE order_items DROP COLUMN sku;
+ALTER TABLE order_items DROP COLUMN variant_id;
+ALTER TABLE stock_movements DROP COLUMN variant_id;
+ALTER TABLE stock_reservations DROP COLUMN variant_id;
+
+DROP INDEX IF EXISTS idx_product_variants_product;
+DROP TABLE IF EXISTS product_variants;
+ALTER TABLE products DROP COLUMN options;
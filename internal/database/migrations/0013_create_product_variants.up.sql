@@ -0,0 +1,45 @@
+-- Eixos de variação dos produtos, como lista JSON de {"name", "values"}
+ALTER TABLE products ADD COLUMN options TEXT NOT NULL DEFAULT '[]';
+
+-- Variantes dos produtos: options é o objeto JSON eixo -> valor e
+-- price_amount, quando não nulo, substitui o preço do produto na mesma moeda.
+-- O estoque de um produto com variantes é a soma do estoque delas.
+CREATE TABLE product_variants (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id   INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	sku          TEXT    NOT NULL UNIQUE COLLATE NOCASE,
+	options      TEXT    NOT NULL,
+	price_amount INTEGER,
+	stock        INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+	active       INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_product_variants_product ON product_variants (product_id, id);
+
+-- variant_id é zero nos registros sem variante
+ALTER TABLE stock_reservations ADD COLUMN variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE stock_movements ADD COLUMN variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN sku TEXT NOT NULL DEFAULT '';
+
+-- Um carrinho pode ter várias variantes do mesmo produto
+CREATE TABLE cart_items_new (
+	user_id     INTEGER NOT NULL REFERENCES carts (user_id) ON DELETE CASCADE,
+	position    INTEGER NOT NULL,
+	product_id  INTEGER NOT NULL,
+	variant_id  INTEGER NOT NULL DEFAULT 0,
+	sku         TEXT    NOT NULL DEFAULT '',
+	name        TEXT    NOT NULL,
+	quantity    INTEGER NOT NULL CHECK (quantity > 0),
+	unit_amount INTEGER NOT NULL,
+	added_at    TEXT    NOT NULL,
+	PRIMARY KEY (user_id, position),
+	UNIQUE (user_id, product_id, variant_id)
+);
+
+INSERT INTO cart_items_new (user_id, position, product_id, name, quantity, unit_amount, added_at)
+SELECT user_id, position, product_id, name, quantity, unit_amount, added_at
+FROM cart_items;
+
+DROP TABLE cart_items;
+ALTER TABLE cart_items_new RENAME TO cart_items;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_price_schedules_open;
+DROP INDEX IF EXISTS idx_price_schedules_product;
+DROP TABLE IF EXISTS price_schedules;
+DROP TRIGGER IF EXISTS price_changes_append_only;
+DROP INDEX IF EXISTS idx_price_changes_product;
+DROP TABLE IF EXISTS price_changes;
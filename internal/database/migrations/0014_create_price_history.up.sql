@@ -0,0 +1,46 @@
+-- Histórico de preços, somente inclusão: previous_amount é nulo no cadastro e
+-- schedule_id identifica o agendamento que aplicou a alteração, quando houver
+CREATE TABLE price_changes (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id        INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	amount            INTEGER NOT NULL,
+	currency          TEXT    NOT NULL,
+	previous_amount   INTEGER,
+	previous_currency TEXT,
+	reason            TEXT    NOT NULL DEFAULT '',
+	actor             TEXT    NOT NULL,
+	schedule_id       INTEGER,
+	created_at        TEXT    NOT NULL
+);
+
+CREATE INDEX idx_price_changes_product ON price_changes (product_id, id);
+
+CREATE TRIGGER price_changes_append_only
+BEFORE UPDATE ON price_changes
+BEGIN
+	SELECT RAISE(ABORT, 'price_changes aceita apenas inclusões');
+END;
+
+-- Agendamentos de preço: previous_amount guarda, enquanto o agendamento está
+-- ativo, o preço a restaurar em ends_at (na mesma moeda)
+CREATE TABLE price_schedules (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	product_id      INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+	amount          INTEGER NOT NULL,
+	currency        TEXT    NOT NULL,
+	starts_at       TEXT    NOT NULL,
+	ends_at         TEXT,
+	status          TEXT    NOT NULL CHECK (status IN ('scheduled', 'active', 'completed', 'cancelled')),
+	previous_amount INTEGER,
+	reason          TEXT    NOT NULL DEFAULT '',
+	actor           TEXT    NOT NULL,
+	created_at      TEXT    NOT NULL
+);
+
+CREATE INDEX idx_price_schedules_product ON price_schedules (product_id, starts_at);
+CREATE INDEX idx_price_schedules_open ON price_schedules (status) WHERE status IN ('scheduled', 'active');
+
+-- O preço atual passa a ser o preço inicial do histórico
+INSERT INTO price_changes (product_id, amount, currency, reason, actor, created_at)
+SELECT id, price_amount, currency, 'preço inicial', 'system', strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+FROM products;
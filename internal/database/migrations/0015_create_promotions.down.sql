@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_discounts;
+DROP INDEX IF EXISTS idx_promotion_redemptions_user;
+DROP INDEX IF EXISTS idx_promotion_redemptions_promotion;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,48 @@
+-- Promoções: code, quando não nulo, é o cupom que aplica a promoção; sem ele,
+-- a promoção é automática. category_ids e product_ids são listas JSON de IDs
+-- (ambas vazias: todos os produtos) e amount é o valor das promoções fixed.
+CREATE TABLE promotions (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	name              TEXT    NOT NULL,
+	code              TEXT    UNIQUE COLLATE NOCASE,
+	type              TEXT    NOT NULL CHECK (type IN ('percentage', 'fixed')),
+	percentage        INTEGER NOT NULL DEFAULT 0 CHECK (percentage BETWEEN 0 AND 100),
+	amount            INTEGER,
+	currency          TEXT,
+	category_ids      TEXT    NOT NULL DEFAULT '[]',
+	product_ids       TEXT    NOT NULL DEFAULT '[]',
+	min_quantity      INTEGER NOT NULL DEFAULT 0,
+	starts_at         TEXT,
+	ends_at           TEXT,
+	max_uses          INTEGER NOT NULL DEFAULT 0,
+	max_uses_per_user INTEGER NOT NULL DEFAULT 0,
+	stackable         INTEGER NOT NULL DEFAULT 0,
+	active            INTEGER NOT NULL DEFAULT 1,
+	created_at        TEXT    NOT NULL,
+	updated_at        TEXT    NOT NULL
+);
+
+-- Resgates vigentes das promoções; liberar um resgate remove a linha.
+-- user_id não tem chave estrangeira, como nos pedidos.
+CREATE TABLE promotion_redemptions (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	promotion_id INTEGER NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+	user_id      INTEGER NOT NULL,
+	created_at   TEXT    NOT NULL
+);
+
+CREATE INDEX idx_promotion_redemptions_promotion ON promotion_redemptions (promotion_id, user_id);
+CREATE INDEX idx_promotion_redemptions_user ON promotion_redemptions (user_id);
+
+-- Descontos aplicados aos pedidos, com cópias do nome e do código da
+-- promoção; total_amount dos pedidos passa a ser o valor já descontado
+CREATE TABLE order_discounts (
+	order_id      INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	position      INTEGER NOT NULL,
+	promotion_id  INTEGER NOT NULL,
+	name          TEXT    NOT NULL,
+	code          TEXT    NOT NULL DEFAULT '',
+	amount        INTEGER NOT NULL CHECK (amount > 0),
+	redemption_id INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (order_id, position)
+);
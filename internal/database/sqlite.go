@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Driver SQLite em Go puro, compatível com CGO_ENABLED=0
+	_ "modernc.org/sqlite"
+)
+
+// uriEscaper escapa os caracteres com significado especial em URIs "file:" do SQLite
+var uriEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// OpenSQLite abre (ou cria) o banco SQLite no caminho informado.
+// O schema é gerenciado pelas migrações (ver Migrator).
+func OpenSQLite(path string) (*sql.DB, error) {
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("criar diretório do banco: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)", uriEscaper.Replace(path))
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("abrir banco sqlite: %w", err)
+	}
+
+	// SQLite aceita um único escritor por vez; uma conexão evita erros SQLITE_BUSY
+	// e mantém bancos ":memory:" consistentes entre chamadas
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("conectar ao banco sqlite: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// CartHandler gerencia as requisições HTTP relacionadas aos carrinhos de compras
+type CartHandler struct {
+	service *services.CartService
+}
+
+// NewCartHandler cria uma nova instância do handler de carrinhos
+func NewCartHandler(service *services.CartService) *CartHandler {
+	return &CartHandler{service: service}
+}
+
+// Get retorna o carrinho do usuário com as pendências de cada item
+func (h *CartHandler) Get(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	cart, err := h.service.Get(r.Context(), userID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    cart,
+	})
+}
+
+// AddItem adiciona um produto ao carrinho do usuário
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.CartItemRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	cart, err := h.service.AddItem(r.Context(), userID, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "cart_item_added", cart))
+}
+
+// UpdateItem altera a quantidade de um item do carrinho
+func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	userID, productID, variantID, ok := cartItemParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CartItemUpdateRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	cart, err := h.service.UpdateItem(r.Context(), userID, productID, variantID, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "cart_item_updated", cart))
+}
+
+// RemoveItem retira um produto do carrinho
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	userID, productID, variantID, ok := cartItemParams(w, r)
+	if !ok {
+		return
+	}
+
+	cart, err := h.service.RemoveItem(r.Context(), userID, productID, variantID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "cart_item_removed", cart))
+}
+
+// Clear esvazia o carrinho do usuário
+func (h *CartHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	cart, err := h.service.Clear(r.Context(), userID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "cart_cleared", cart))
+}
+
+// cartItemParams lê os IDs do usuário e do produto do caminho e o da
+// variante de ?variant_id= (zero se omitido), respondendo 400 se algum for inválido
+func cartItemParams(w http.ResponseWriter, r *http.Request) (int, int, int, bool) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return 0, 0, 0, false
+	}
+	productID, err := strconv.Atoi(chi.URLParam(r, "productID"))
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return 0, 0, 0, false
+	}
+	variantID := 0
+	if raw := r.URL.Query().Get("variant_id"); raw != "" {
+		if variantID, err = strconv.Atoi(raw); err != nil {
+			renderError(w, r, errInvalidID)
+			return 0, 0, 0, false
+		}
+	}
+	return userID, productID, variantID, true
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// CategoryHandler gerencia as requisições HTTP relacionadas às categorias
+type CategoryHandler struct {
+	service *services.CategoryService
+}
+
+// NewCategoryHandler cria uma nova instância do handler de categorias
+func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: service}
+}
+
+// GetTree retorna a árvore de categorias com a quantidade de produtos de cada uma
+func (h *CategoryHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.service.GetTree(r.Context())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    categories,
+	})
+}
+
+// GetByID retorna uma categoria com suas subcategorias
+func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	category, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    category,
+	})
+}
+
+// Create cria uma nova categoria
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CategoryRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	category, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, success(r, "category_created", category))
+}
+
+// Update atualiza uma categoria existente
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.CategoryRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	category, err := h.service.Update(r.Context(), id, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "category_updated", category))
+}
+
+// Delete remove uma categoria vazia
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "category_deleted", nil))
+}
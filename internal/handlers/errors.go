@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/patch"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+var (
+	errInvalidID       = errors.New("ID inválido")
+	errInvalidBody     = errors.New("Dados inválidos")
+	errInvalidCategory = errors.New("Categoria inválida")
+)
+
+// problemType identifica uma categoria de erro da API; o título é traduzido
+// a partir de "title.<slug>" no catálogo
+type problemType struct {
+	slug   string
+	status int
+}
+
+var (
+	problemInternal          = problemType{"internal_error", http.StatusInternalServerError}
+	problemValidation        = problemType{"validation_error", http.StatusUnprocessableEntity}
+	problemNotFound          = problemType{"not_found", http.StatusNotFound}
+	problemBadRequest        = problemType{"bad_request", http.StatusBadRequest}
+	problemInvalidQuery      = problemType{"invalid_query", http.StatusBadRequest}
+	problemInvalidPatch      = problemType{"invalid_patch", http.StatusBadRequest}
+	problemUnsupportedType   = problemType{"unsupported_media_type", http.StatusUnsupportedMediaType}
+	problemPatchConflict     = problemType{"patch_conflict", http.StatusConflict}
+	problemEmailExists       = problemType{"email_exists", http.StatusConflict}
+	problemVersionConflict   = problemType{"version_conflict", http.StatusPreconditionFailed}
+	problemInsufficientStock = problemType{"insufficient_stock", http.StatusConflict}
+	problemStockLimit        = problemType{"stock_limit", http.StatusConflict}
+	problemProductInactive   = problemType{"product_inactive", http.StatusConflict}
+	problemInvalidTransition = problemType{"invalid_transition", http.StatusConflict}
+	problemSlugExists        = problemType{"slug_exists", http.StatusConflict}
+	problemCategoryNotEmpty  = problemType{"category_not_empty", http.StatusConflict}
+	problemSKUExists         = problemType{"sku_exists", http.StatusConflict}
+	problemVariantRequired   = problemType{"variant_required", http.StatusConflict}
+	problemVariantReserved   = problemType{"variant_reserved", http.StatusConflict}
+	problemScheduleOverlap   = problemType{"schedule_overlap", http.StatusConflict}
+	problemScheduleClosed    = problemType{"schedule_closed", http.StatusConflict}
+	problemCouponCodeExists  = problemType{"coupon_code_exists", http.StatusConflict}
+	problemPromotionLimit    = problemType{"promotion_limit", http.StatusConflict}
+)
+
+// errorCodes associa os erros sentinela dos repositórios, serviços e handlers
+// ao código estável da mensagem e ao problema HTTP correspondente; a primeira
+// correspondência vence
+var errorCodes = []struct {
+	err     error
+	code    string
+	problem problemType
+}{
+	{repositories.ErrUserNotFound, "user_not_found", problemNotFound},
+	{repositories.ErrProductNotFound, "product_not_found", problemNotFound},
+	{repositories.ErrReservationNotFound, "reservation_not_found", problemNotFound},
+	{repositories.ErrOrderNotFound, "order_not_found", problemNotFound},
+	{services.ErrCartItemNotFound, "cart_item_not_found", problemNotFound},
+	{repositories.ErrCategoryNotFound, "category_not_found", problemNotFound},
+	{repositories.ErrVariantNotFound, "variant_not_found", problemNotFound},
+	{repositories.ErrScheduleNotFound, "schedule_not_found", problemNotFound},
+	{repositories.ErrPromotionNotFound, "promotion_not_found", problemNotFound},
+	{repositories.ErrInsufficientStock, "insufficient_stock", problemInsufficientStock},
+	{repositories.ErrStockOverflow, "stock_overflow", problemStockLimit},
+	{services.ErrProductInactive, "product_inactive", problemProductInactive},
+	{services.ErrInvalidTransition, "invalid_order_transition", problemInvalidTransition},
+	{repositories.ErrEmailExists, "email_exists", problemEmailExists},
+	{repositories.ErrSlugExists, "slug_exists", problemSlugExists},
+	{services.ErrCategoryNotEmpty, "category_not_empty", problemCategoryNotEmpty},
+	{repositories.ErrSKUExists, "sku_exists", problemSKUExists},
+	{repositories.ErrVariantRequired, "variant_required", problemVariantRequired},
+	{repositories.ErrVariantReserved, "variant_reserved", problemVariantReserved},
+	{repositories.ErrScheduleOverlap, "schedule_overlap", problemScheduleOverlap},
+	{repositories.ErrScheduleClosed, "schedule_closed", problemScheduleClosed},
+	{repositories.ErrCouponCodeExists, "coupon_code_exists", problemCouponCodeExists},
+	{repositories.ErrPromotionExhausted, "promotion_exhausted", problemPromotionLimit},
+	{repositories.ErrPromotionUserLimit, "promotion_user_limit", problemPromotionLimit},
+	{repositories.ErrVersionConflict, "version_conflict", problemVersionConflict},
+	{patch.ErrUnsupportedMediaType, "unsupported_media_type", problemUnsupportedType},
+	{patch.ErrInvalidPatch, "invalid_patch", problemInvalidPatch},
+	{patch.ErrConflict, "patch_conflict", problemPatchConflict},
+	{services.ErrInvalidCursor, "invalid_cursor", problemInvalidQuery},
+	{services.ErrInvalidQuery, "invalid_query", problemInvalidQuery},
+	{errInvalidPagination, "invalid_pagination", problemInvalidQuery},
+	{services.ErrInvalidUserData, "invalid_user_data", problemBadRequest},
+	{services.ErrInvalidProductData, "invalid_product_data", problemBadRequest},
+	{services.ErrInvalidOrderData, "invalid_order_data", problemBadRequest},
+	{services.ErrInvalidCartData, "invalid_cart_data", problemBadRequest},
+	{services.ErrInvalidCategoryData, "invalid_category_data", problemBadRequest},
+	{services.ErrInvalidVariantData, "invalid_variant_data", problemBadRequest},
+	{services.ErrInvalidScheduleData, "invalid_schedule_data", problemBadRequest},
+	{services.ErrInvalidPromotionData, "invalid_promotion_data", problemBadRequest},
+	{services.ErrInvalidBasketData, "invalid_basket_data", problemBadRequest},
+	{errInvalidID, "invalid_id", problemBadRequest},
+	{errInvalidBody, "invalid_body", problemBadRequest},
+	{errInvalidCategory, "invalid_category", problemBadRequest},
+}
+
+// classifyError retorna o código e o problema HTTP correspondentes ao erro.
+// Erros de validação respondem 422, embora também envolvam Err*Data.
+func classifyError(err error) (string, problemType) {
+	for _, entry := range errorCodes {
+		if !errors.Is(err, entry.err) {
+			continue
+		}
+		var validation *services.ValidationError
+		if errors.As(err, &validation) {
+			return entry.code, problemValidation
+		}
+		return entry.code, entry.problem
+	}
+	return "internal_error", problemInternal
+}
+
+// renderError responde com o status e o código correspondentes ao erro, com
+// as mensagens no idioma da requisição. O corpo segue o formato
+// application/problem+json quando o cliente o aceita explicitamente e
+// models.Response nos demais casos. Erros não mapeados são registrados no log
+// e não têm a mensagem exposta ao cliente; erros combinados com falhas de
+// compensação (errors.Join) também são registrados.
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	locale := i18n.FromContext(r.Context())
+	code, problem := classifyError(err)
+	if problem == problemInternal {
+		log.Printf("[%s] erro interno em %s %s: %v", middleware.GetReqID(r.Context()), r.Method, r.URL.Path, err)
+		err = nil
+	} else if joined, ok := err.(interface{ Unwrap() []error }); ok && len(joined.Unwrap()) > 1 {
+		// a resposta reflete só o erro principal; falhas ao desfazer os
+		// passos anteriores ficam registradas no log
+		log.Printf("[%s] erro com falhas de compensação em %s %s: %v", middleware.GetReqID(r.Context()), r.Method, r.URL.Path, err)
+	}
+
+	detail := i18n.Message(locale, code)
+	if details := i18n.Details(locale, err); len(details) > 0 {
+		detail += ": " + strings.Join(details, ": ")
+	}
+
+	var fields []models.FieldError
+	var validation *services.ValidationError
+	if errors.As(err, &validation) {
+		fields = make([]models.FieldError, len(validation.Fields))
+		for i, f := range validation.Fields {
+			f.Message = i18n.Message(locale, f.MessageKey, f.Args...)
+			fields[i] = f
+		}
+	}
+
+	if !acceptsProblem(r) {
+		render.Status(r, problem.status)
+		render.JSON(w, r, models.Response{
+			Success: false,
+			Code:    code,
+			Error:   detail,
+			Errors:  fields,
+		})
+		return
+	}
+
+	body := models.Problem{
+		Type:      problemTypeURI(problem),
+		Title:     i18n.Message(locale, "title."+problem.slug),
+		Status:    problem.status,
+		Code:      code,
+		Detail:    detail,
+		Instance:  r.URL.RequestURI(),
+		RequestID: middleware.GetReqID(r.Context()),
+		Errors:    fields,
+	}
+	w.Header().Set("Content-Type", models.ContentTypeProblem)
+	w.WriteHeader(problem.status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("erro ao escrever problem+json: %v", err)
+	}
+}
+
+// success monta uma resposta de sucesso com a mensagem de code no idioma da requisição
+func success(r *http.Request, code string, data interface{}) models.Response {
+	return models.Response{
+		Success: true,
+		Code:    code,
+		Message: i18n.Message(i18n.FromContext(r.Context()), code),
+		Data:    data,
+	}
+}
+
+// problemTypeURI retorna a URI que identifica o tipo do problema; erros sem
+// tipo próprio usam about:blank, como define a RFC 7807
+func problemTypeURI(problem problemType) string {
+	if problem == problemInternal {
+		return "about:blank"
+	}
+	return "/problems/" + strings.ReplaceAll(problem.slug, "_", "-")
+}
+
+// acceptsProblem indica se o header Accept inclui application/problem+json
+// com qualidade diferente de zero
+func acceptsProblem(r *http.Request) bool {
+	for _, header := range r.Header.Values("Accept") {
+		for _, value := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+			if err != nil || mediaType != models.ContentTypeProblem {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/patch"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+)
+
+func TestClassifyError(t *testing.T) {
+	validation := &services.ValidationError{
+		Err:    services.ErrInvalidUserData,
+		Fields: []models.FieldError{{Field: "email", Code: "invalid_format"}},
+	}
+	tests := []struct {
+		name    string
+		err     error
+		code    string
+		problem problemType
+	}{
+		{"validação responde 422", validation, "invalid_user_data", problemValidation},
+		{"validação envolvida responde 422", fmt.Errorf("criar usuário: %w", validation), "invalid_user_data", problemValidation},
+		{"dados inválidos sem campos respondem 400", services.ErrInvalidUserData, "invalid_user_data", problemBadRequest},
+		{"e-mail em uso", repositories.ErrEmailExists, "email_exists", problemEmailExists},
+		{"e-mail em uso envolvido", fmt.Errorf("atualizar usuário: %w", repositories.ErrEmailExists), "email_exists", problemEmailExists},
+		{"versão divergente", repositories.ErrVersionConflict, "version_conflict", problemVersionConflict},
+		{"não encontrado", repositories.ErrProductNotFound, "product_not_found", problemNotFound},
+		{"estoque insuficiente com detalhe", i18n.Errorf(repositories.ErrInsufficientStock, "cart_stock", 3), "insufficient_stock", problemInsufficientStock},
+		{"falha de compensação mantém o erro principal", errors.Join(repositories.ErrInsufficientStock, errors.New("falha ao liberar")), "insufficient_stock", problemInsufficientStock},
+		{"patch inválido", patch.ErrInvalidPatch, "invalid_patch", problemInvalidPatch},
+		{"paginação inválida", errInvalidPagination, "invalid_pagination", problemInvalidQuery},
+		{"ID inválido", errInvalidID, "invalid_id", problemBadRequest},
+		{"erro desconhecido", errors.New("disco cheio"), "internal_error", problemInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, problem := classifyError(tt.err)
+			if code != tt.code || problem != tt.problem {
+				t.Errorf("classifyError: esperado %s %+v, obtido %s %+v", tt.code, tt.problem, code, problem)
+			}
+		})
+	}
+}
+
+func TestErrorCodesAreUnique(t *testing.T) {
+	seen := make(map[error]string)
+	for _, entry := range errorCodes {
+		if code, ok := seen[entry.err]; ok {
+			t.Errorf("errorCodes: erro %v mapeado para %s e %s", entry.err, code, entry.code)
+		}
+		seen[entry.err] = entry.code
+		if got, _ := classifyError(entry.err); got != entry.code {
+			t.Errorf("classifyError(%v): esperado %s, obtido %s; a entrada é encoberta por outra anterior", entry.err, entry.code, got)
+		}
+	}
+}
+
+func TestAcceptsProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept []string
+		want   bool
+	}{
+		{"sem Accept", nil, false},
+		{"JSON", []string{"application/json"}, false},
+		{"qualquer tipo", []string{"*/*"}, false},
+		{"problem+json", []string{"application/problem+json"}, true},
+		{"problem+json entre outros", []string{"application/json, application/problem+json;q=0.5"}, true},
+		{"problem+json em outro header", []string{"application/json", "application/problem+json"}, true},
+		{"q=0 recusa problem+json", []string{"application/problem+json;q=0"}, false},
+		{"q=0.0 recusa problem+json", []string{"application/json, application/problem+json; q=0.0"}, false},
+		{"q inválido não recusa", []string{"application/problem+json;q=x"}, true},
+		{"valor malformado é ignorado", []string{"application/problem+json;;, text/plain"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/products", nil)
+			for _, value := range tt.accept {
+				r.Header.Add("Accept", value)
+			}
+			if got := acceptsProblem(r); got != tt.want {
+				t.Errorf("acceptsProblem(%q): esperado %v, obtido %v", tt.accept, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderErrorNegotiatesProblem(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"application/problem+json", models.ContentTypeProblem},
+		{"application/problem+json;q=0", "application/json"},
+		{"", "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/users/1", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			renderError(w, r, repositories.ErrVersionConflict)
+			if w.Code != http.StatusPreconditionFailed {
+				t.Errorf("renderError: esperado status %d, obtido %d", http.StatusPreconditionFailed, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType && got != tt.contentType+"; charset=utf-8" {
+				t.Errorf("renderError: esperado Content-Type %s, obtido %s", tt.contentType, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag formata a versão de um registro como entity tag forte
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag expõe a versão do registro no header ETag
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// ifMatchVersions lê o header If-Match (RFC 7232). Retorna nil quando ele está
+// ausente ou é "*"; caso contrário, as versões das tags fortes informadas.
+// Tags fracas ou que não são versões nunca casam, e a lista pode ficar vazia.
+func ifMatchVersions(r *http.Request) []int {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if v, ok := parseETag(tag); ok {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// notModified indica se o header If-None-Match casa com a versão atual,
+// usando a comparação fraca exigida para GET
+func notModified(r *http.Request, version int) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETag extrai a versão de uma tag no formato "N"
+func parseETag(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	v, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
 	"github.com/go-chi/render"
 )
 
@@ -17,12 +16,8 @@ func NewHealthHandler() *HealthHandler {
 
 // Check retorna o status da API
 func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "API está funcionando corretamente",
-		Data: map[string]string{
-			"status":  "healthy",
-			"service": "go-api-actions-ci-cd",
-		},
-	})
+	render.JSON(w, r, success(r, "api_healthy", map[string]string{
+		"status":  "healthy",
+		"service": "go-api-actions-ci-cd",
+	}))
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// OrderHandler gerencia as requisições HTTP relacionadas a pedidos
+type OrderHandler struct {
+	service *services.OrderService
+}
+
+// NewOrderHandler cria uma nova instância do handler de pedidos
+func NewOrderHandler(service *services.OrderService) *OrderHandler {
+	return &OrderHandler{service: service}
+}
+
+// GetAll retorna uma página de pedidos filtrada e ordenada pelos parâmetros da URL
+func (h *OrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	orders, info, err := h.service.GetAll(r.Context(), r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, orders, page, info)
+}
+
+// GetByID retorna um pedido pelo ID
+func (h *OrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	order, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, order.Version)
+	if notModified(r, order.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    order,
+	})
+}
+
+// Create cria um novo pedido pendente, reservando o estoque dos itens
+func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.OrderRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	order, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, order.Version)
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, success(r, "order_created", order))
+}
+
+// Pay marca um pedido pendente como pago
+func (h *OrderHandler) Pay(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Pay, "order_paid")
+}
+
+// Ship marca um pedido pago como enviado
+func (h *OrderHandler) Ship(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Ship, "order_shipped")
+}
+
+// Cancel cancela um pedido pendente ou pago, devolvendo o estoque
+func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Cancel, "order_cancelled")
+}
+
+// Refund marca um pedido enviado como reembolsado
+func (h *OrderHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Refund, "order_refunded")
+}
+
+// transition aplica a mudança de status solicitada; transições não
+// permitidas pela máquina de status respondem 409
+func (h *OrderHandler) transition(w http.ResponseWriter, r *http.Request, apply func(context.Context, int) (*models.Order, error), code string) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	order, err := apply(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, order.Version)
+	render.JSON(w, r, success(r, code, order))
+}
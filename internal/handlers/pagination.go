@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/go-chi/render"
+)
+
+var errInvalidPagination = errors.New("paginação inválida")
+
+// parsePagination lê os parâmetros ?page=, ?limit= e ?cursor= aplicando os valores padrão
+func parsePagination(r *http.Request) (models.Pagination, error) {
+	page := models.Pagination{Page: 1, Limit: models.DefaultPageLimit}
+	query := r.URL.Query()
+
+	if v := query.Get("cursor"); v != "" {
+		page.Page = 0
+		page.Cursor = v
+	}
+
+	if v := query.Get("page"); v != "" && page.Cursor == "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return page, i18n.Errorf(errInvalidPagination, "pagination_range", models.MaxPageLimit)
+		}
+		page.Page = n
+	}
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > models.MaxPageLimit {
+			return page, i18n.Errorf(errInvalidPagination, "pagination_range", models.MaxPageLimit)
+		}
+		page.Limit = n
+	}
+
+	// o deslocamento (page-1)*limit precisa caber em um int
+	if maxPage := math.MaxInt/page.Limit + 1; page.Page > maxPage {
+		return page, i18n.Errorf(errInvalidPagination, "pagination_page_too_large", maxPage, page.Limit)
+	}
+
+	return page, nil
+}
+
+// renderPage escreve a resposta paginada e o header Link correspondente
+func renderPage(w http.ResponseWriter, r *http.Request, data interface{}, page models.Pagination, info models.PageInfo) {
+	setLinkHeader(w, r, page, info)
+	render.JSON(w, r, models.PaginatedResponse{
+		Success:    true,
+		Data:       data,
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Total:      info.Total,
+		NextCursor: info.NextCursor,
+	})
+}
+
+// setLinkHeader define o header Link (RFC 5988). Na paginação por página são
+// emitidas as relações first, prev, next e last; na paginação por cursor,
+// apenas first e next, pois não há como voltar ou saltar para o fim.
+func setLinkHeader(w http.ResponseWriter, r *http.Request, page models.Pagination, info models.PageInfo) {
+	if page.Cursor != "" {
+		links := []string{pageLink(r.URL, page.Limit, 1, "first")}
+		if info.NextCursor != "" {
+			links = append(links, cursorLink(r.URL, page.Limit, info.NextCursor, "next"))
+		}
+		w.Header().Set("Link", strings.Join(links, ", "))
+		return
+	}
+
+	total := info.Total
+	lastPage := 1
+	if total > 0 {
+		lastPage = (total + page.Limit - 1) / page.Limit
+	}
+
+	links := []string{pageLink(r.URL, page.Limit, 1, "first")}
+	if page.Page > 1 {
+		prev := page.Page - 1
+		if prev > lastPage {
+			prev = lastPage
+		}
+		links = append(links, pageLink(r.URL, page.Limit, prev, "prev"))
+	}
+	if page.Page < lastPage {
+		links = append(links, pageLink(r.URL, page.Limit, page.Page+1, "next"))
+	}
+	links = append(links, pageLink(r.URL, page.Limit, lastPage, "last"))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// pageLink monta uma entrada do header Link preservando os demais parâmetros da URL
+func pageLink(u *url.URL, limit, page int, rel string) string {
+	query := u.Query()
+	query.Del("cursor")
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
+
+	target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=\"%s\"", target.String(), rel)
+}
+
+// cursorLink monta uma entrada do header Link apontando para um cursor
+func cursorLink(u *url.URL, limit int, cursor, rel string) string {
+	query := u.Query()
+	query.Del("page")
+	query.Set("cursor", cursor)
+	query.Set("limit", strconv.Itoa(limit))
+
+	target := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=\"%s\"", target.String(), rel)
+}
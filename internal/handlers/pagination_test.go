@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+func TestParsePagination(t *testing.T) {
+	maxPage := strconv.Itoa(math.MaxInt/100 + 1)
+	tests := []struct {
+		query   string
+		want    models.Pagination
+		invalid bool
+	}{
+		{"", models.Pagination{Page: 1, Limit: models.DefaultPageLimit}, false},
+		{"page=3&limit=10", models.Pagination{Page: 3, Limit: 10}, false},
+		{"cursor=abc&page=3", models.Pagination{Limit: models.DefaultPageLimit, Cursor: "abc"}, false},
+		{"page=" + maxPage + "&limit=100", models.Pagination{Page: math.MaxInt/100 + 1, Limit: 100}, false},
+		{"page=0", models.Pagination{}, true},
+		{"page=-1", models.Pagination{}, true},
+		{"page=x", models.Pagination{}, true},
+		{"limit=0", models.Pagination{}, true},
+		{"limit=101", models.Pagination{}, true},
+		{"page=184467440737095517&limit=100", models.Pagination{}, true},
+		{"page=" + strconv.Itoa(math.MaxInt/100+2) + "&limit=100", models.Pagination{}, true},
+		{"page=99999999999999999999", models.Pagination{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			page, err := parsePagination(httptest.NewRequest("GET", "/api/products?"+tt.query, nil))
+			if tt.invalid {
+				if !errors.Is(err, errInvalidPagination) {
+					t.Errorf("parsePagination: esperado errInvalidPagination, obtido %v (%+v)", err, page)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePagination: %v", err)
+			}
+			if page != tt.want {
+				t.Errorf("parsePagination: esperado %+v, obtido %+v", tt.want, page)
+			}
+			if page.Offset() < 0 {
+				t.Errorf("Offset: deslocamento negativo %d", page.Offset())
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetPriceHistory retorna uma página do histórico de preços de um produto
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	changes, info, err := h.service.GetPriceHistory(r.Context(), id, page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, changes, page, info)
+}
+
+// GetPriceSchedules retorna os agendamentos de preço de um produto
+func (h *ProductHandler) GetPriceSchedules(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	schedules, err := h.service.GetPriceSchedules(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    schedules,
+	})
+}
+
+// SchedulePrice agenda uma alteração de preço; responde 409 se o período se
+// sobrepuser ao de outro agendamento em aberto
+func (h *ProductHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.PriceScheduleRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	schedule, err := h.service.SchedulePrice(r.Context(), id, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, success(r, "price_scheduled", schedule))
+}
+
+// CancelPriceSchedule cancela um agendamento de preço em aberto
+func (h *ProductHandler) CancelPriceSchedule(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+	scheduleID, err := strconv.Atoi(chi.URLParam(r, "scheduleID"))
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	schedule, err := h.service.CancelPriceSchedule(r.Context(), productID, scheduleID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "price_schedule_cancelled", schedule))
+}
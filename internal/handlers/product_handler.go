@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
@@ -20,13 +22,21 @@ func NewProductHandler(service *services.ProductService) *ProductHandler {
 	return &ProductHandler{service: service}
 }
 
-// GetAll retorna todos os produtos
+// GetAll retorna uma página de produtos filtrada e ordenada pelos parâmetros da URL
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	products := h.service.GetAll()
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Data:    products,
-	})
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	products, info, err := h.service.GetAll(r.Context(), r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, products, page, info)
 }
 
 // GetByID retorna um produto pelo ID
@@ -34,21 +44,19 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "ID inválido",
-		})
+		renderError(w, r, errInvalidID)
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	product, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	if notModified(r, product.Version) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -58,53 +66,64 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetByCategory retorna produtos por categoria
+// GetByCategory retorna produtos da categoria identificada pelo slug e, com
+// ?recursive=true, de suas subcategorias
 func (h *ProductHandler) GetByCategory(w http.ResponseWriter, r *http.Request) {
-	category := chi.URLParam(r, "category")
-	if category == "" {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "Categoria inválida",
-		})
+	slug := chi.URLParam(r, "slug")
+	if slug == "" {
+		renderError(w, r, errInvalidCategory)
 		return
 	}
 
-	products := h.service.GetByCategory(category)
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Data:    products,
-	})
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	products, info, err := h.service.GetByCategory(r.Context(), slug, r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, products, page, info)
+}
+
+// Search busca produtos por texto (?q=) ordenados por relevância
+func (h *ProductHandler) Search(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	results, info, err := h.service.Search(r.Context(), r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, results, page, info)
 }
 
 // Create cria um novo produto
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req models.ProductRequest
 	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "Dados inválidos",
-		})
+		renderError(w, r, errInvalidBody)
 		return
 	}
 
-	product, err := h.service.Create(req)
+	product, err := h.service.Create(r.Context(), req)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		renderError(w, r, err)
 		return
 	}
 
+	setETag(w, product.Version)
 	render.Status(r, http.StatusCreated)
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "Produto criado com sucesso",
-		Data:    product,
-	})
+	render.JSON(w, r, success(r, "product_created", product))
 }
 
 // Update atualiza um produto existente
@@ -112,65 +131,136 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "ID inválido",
-		})
+		renderError(w, r, errInvalidID)
 		return
 	}
 
 	var req models.ProductRequest
 	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "Dados inválidos",
-		})
+		renderError(w, r, errInvalidBody)
 		return
 	}
 
-	product, err := h.service.Update(id, req)
+	product, err := h.service.Update(r.Context(), id, req, ifMatchVersions(r))
 	if err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		renderError(w, r, err)
 		return
 	}
 
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "Produto atualizado com sucesso",
-		Data:    product,
-	})
+	setETag(w, product.Version)
+	render.JSON(w, r, success(r, "product_updated", product))
+}
+
+// Patch altera parcialmente um produto com JSON Merge Patch ou JSON Patch,
+// conforme o Content-Type da requisição
+func (h *ProductHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	product, err := h.service.Patch(r.Context(), id, mediaType, body, ifMatchVersions(r))
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	render.JSON(w, r, success(r, "product_updated", product))
+}
+
+// Activate reativa um produto
+func (h *ProductHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+// Deactivate desativa um produto
+func (h *ProductHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+// setActive executa a transição de estado ativo/inativo do produto
+func (h *ProductHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	transition, code := h.service.Deactivate, "product_deactivated"
+	if active {
+		transition, code = h.service.Activate, "product_activated"
+	}
+
+	product, err := transition(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	render.JSON(w, r, success(r, code, product))
 }
 
-// Delete remove um produto
+// Delete move um produto para a lixeira
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "ID inválido",
-		})
+		renderError(w, r, errInvalidID)
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+	if err := h.service.Delete(r.Context(), id, ifMatchVersions(r)); err != nil {
+		renderError(w, r, err)
 		return
 	}
 
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "Produto removido com sucesso",
-	})
+	render.JSON(w, r, success(r, "product_trashed", nil))
+}
+
+// GetTrash retorna uma página dos produtos na lixeira
+func (h *ProductHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	products, info, err := h.service.GetTrash(r.Context(), r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, products, page, info)
+}
+
+// Restore retira um produto da lixeira
+func (h *ProductHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	product, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	render.JSON(w, r, success(r, "product_restored", product))
 }
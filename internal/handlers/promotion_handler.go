@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// PromotionHandler gerencia as requisições HTTP relacionadas às promoções e cupons
+type PromotionHandler struct {
+	service *services.PromotionService
+}
+
+// NewPromotionHandler cria uma nova instância do handler de promoções
+func NewPromotionHandler(service *services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// GetAll retorna todas as promoções
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.service.GetAll(r.Context())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    promotions,
+	})
+}
+
+// GetByID retorna uma promoção pelo ID
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	promotion, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    promotion,
+	})
+}
+
+// Create cria uma nova promoção
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.PromotionRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	promotion, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, success(r, "promotion_created", promotion))
+}
+
+// Update atualiza uma promoção existente
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.PromotionRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	promotion, err := h.service.Update(r.Context(), id, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "promotion_updated", promotion))
+}
+
+// Delete remove uma promoção
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "promotion_deleted", nil))
+}
+
+// Evaluate calcula os descontos de uma cesta de produtos, sem registrar resgates
+func (h *PromotionHandler) Evaluate(w http.ResponseWriter, r *http.Request) {
+	var req models.BasketRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	evaluation, err := h.service.Evaluate(r.Context(), req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    evaluation,
+	})
+}
+
+// EvaluateCart calcula os descontos do carrinho do usuário, sem registrar resgates
+func (h *PromotionHandler) EvaluateCart(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	userID, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	// o corpo é opcional: sem cupons, só as promoções automáticas são avaliadas
+	var req models.CouponsRequest
+	if r.ContentLength != 0 {
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			renderError(w, r, errInvalidBody)
+			return
+		}
+	}
+
+	evaluation, err := h.service.EvaluateCart(r.Context(), userID, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    evaluation,
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// IncrementStock adiciona unidades ao estoque de um produto
+func (h *ProductHandler) IncrementStock(w http.ResponseWriter, r *http.Request) {
+	h.adjustStock(w, r, true)
+}
+
+// DecrementStock retira unidades do estoque de um produto; responde 409 se
+// não houver estoque disponível suficiente
+func (h *ProductHandler) DecrementStock(w http.ResponseWriter, r *http.Request) {
+	h.adjustStock(w, r, false)
+}
+
+// adjustStock aplica a operação de estoque solicitada
+func (h *ProductHandler) adjustStock(w http.ResponseWriter, r *http.Request, increment bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.StockRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	adjust, code := h.service.DecrementStock, "stock_decremented"
+	if increment {
+		adjust, code = h.service.IncrementStock, "stock_incremented"
+	}
+
+	product, err := adjust(r.Context(), id, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	render.JSON(w, r, success(r, code, product))
+}
+
+// GetMovements retorna uma página do histórico de estoque de um produto
+func (h *ProductHandler) GetMovements(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	movements, info, err := h.service.GetMovements(r.Context(), id, page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, movements, page, info)
+}
+
+// Reserve cria uma reserva de estoque para um produto
+func (h *ProductHandler) Reserve(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.StockRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	reservation, err := h.service.Reserve(r.Context(), id, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, success(r, "stock_reserved", reservation))
+}
+
+// GetReservation retorna uma reserva vigente
+func (h *ProductHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	reservation, err := h.service.GetReservation(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    reservation,
+	})
+}
+
+// CommitReservation confirma uma reserva, retirando as unidades do estoque
+func (h *ProductHandler) CommitReservation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	product, err := h.service.CommitReservation(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, product.Version)
+	render.JSON(w, r, success(r, "reservation_committed", product))
+}
+
+// ReleaseReservation libera uma reserva, devolvendo as unidades ao estoque disponível
+func (h *ProductHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	if err := h.service.ReleaseReservation(r.Context(), id); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "reservation_released", nil))
+}
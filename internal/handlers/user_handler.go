@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
 
@@ -20,13 +22,21 @@ func NewUserHandler(service *services.UserService) *UserHandler {
 	return &UserHandler{service: service}
 }
 
-// GetAll retorna todos os usuários
+// GetAll retorna uma página de usuários filtrada e ordenada pelos parâmetros da URL
 func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	users := h.service.GetAll()
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Data:    users,
-	})
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	users, info, err := h.service.GetAll(r.Context(), r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, users, page, info)
 }
 
 // GetByID retorna um usuário pelo ID
@@ -34,21 +44,19 @@ func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "ID inválido",
-		})
+		renderError(w, r, errInvalidID)
 		return
 	}
 
-	user, err := h.service.GetByID(id)
+	user, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	if notModified(r, user.Version) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -62,30 +70,19 @@ func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req models.UserRequest
 	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "Dados inválidos",
-		})
+		renderError(w, r, errInvalidBody)
 		return
 	}
 
-	user, err := h.service.Create(req)
+	user, err := h.service.Create(r.Context(), req)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		renderError(w, r, err)
 		return
 	}
 
+	setETag(w, user.Version)
 	render.Status(r, http.StatusCreated)
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "Usuário criado com sucesso",
-		Data:    user,
-	})
+	render.JSON(w, r, success(r, "user_created", user))
 }
 
 // Update atualiza um usuário existente
@@ -93,65 +90,136 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "ID inválido",
-		})
+		renderError(w, r, errInvalidID)
 		return
 	}
 
 	var req models.UserRequest
 	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "Dados inválidos",
-		})
+		renderError(w, r, errInvalidBody)
 		return
 	}
 
-	user, err := h.service.Update(id, req)
+	user, err := h.service.Update(r.Context(), id, req, ifMatchVersions(r))
 	if err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		renderError(w, r, err)
 		return
 	}
 
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "Usuário atualizado com sucesso",
-		Data:    user,
-	})
+	setETag(w, user.Version)
+	render.JSON(w, r, success(r, "user_updated", user))
+}
+
+// Patch altera parcialmente um usuário com JSON Merge Patch ou JSON Patch,
+// conforme o Content-Type da requisição
+func (h *UserHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	user, err := h.service.Patch(r.Context(), id, mediaType, body, ifMatchVersions(r))
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	render.JSON(w, r, success(r, "user_updated", user))
 }
 
-// Delete remove um usuário
+// Activate reativa um usuário
+func (h *UserHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+// Deactivate desativa um usuário
+func (h *UserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+// setActive executa a transição de estado ativo/inativo do usuário
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	transition, code := h.service.Deactivate, "user_deactivated"
+	if active {
+		transition, code = h.service.Activate, "user_activated"
+	}
+
+	user, err := transition(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	render.JSON(w, r, success(r, code, user))
+}
+
+// Delete move um usuário para a lixeira
 func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   "ID inválido",
-		})
+		renderError(w, r, errInvalidID)
 		return
 	}
 
-	if err := h.service.Delete(id); err != nil {
-		render.Status(r, http.StatusNotFound)
-		render.JSON(w, r, models.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+	if err := h.service.Delete(r.Context(), id, ifMatchVersions(r)); err != nil {
+		renderError(w, r, err)
 		return
 	}
 
-	render.JSON(w, r, models.Response{
-		Success: true,
-		Message: "Usuário removido com sucesso",
-	})
+	render.JSON(w, r, success(r, "user_trashed", nil))
+}
+
+// GetTrash retorna uma página dos usuários na lixeira
+func (h *UserHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePagination(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	users, info, err := h.service.GetTrash(r.Context(), r.URL.Query(), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	renderPage(w, r, users, page, info)
+}
+
+// Restore retira um usuário da lixeira
+func (h *UserHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	user, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	setETag(w, user.Version)
+	render.JSON(w, r, success(r, "user_restored", user))
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetVariants retorna as variantes de um produto
+func (h *ProductHandler) GetVariants(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	variants, err := h.service.GetVariants(r.Context(), id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    variants,
+	})
+}
+
+// GetVariant retorna uma variante de um produto
+func (h *ProductHandler) GetVariant(w http.ResponseWriter, r *http.Request) {
+	productID, variantID, ok := variantParams(w, r)
+	if !ok {
+		return
+	}
+
+	variant, err := h.service.GetVariant(r.Context(), productID, variantID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, models.Response{
+		Success: true,
+		Data:    variant,
+	})
+}
+
+// CreateVariant cria uma variante de um produto; responde 409 se o SKU já
+// estiver em uso
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return
+	}
+
+	var req models.VariantRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	variant, err := h.service.CreateVariant(r.Context(), id, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, success(r, "variant_created", variant))
+}
+
+// UpdateVariant substitui os campos de uma variante
+func (h *ProductHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	productID, variantID, ok := variantParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.VariantRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		renderError(w, r, errInvalidBody)
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(r.Context(), productID, variantID, req)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "variant_updated", variant))
+}
+
+// DeleteVariant remove uma variante; responde 409 se ela tiver reservas vigentes
+func (h *ProductHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	productID, variantID, ok := variantParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteVariant(r.Context(), productID, variantID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.JSON(w, r, success(r, "variant_deleted", nil))
+}
+
+// variantParams lê os IDs do produto e da variante do caminho, respondendo
+// 400 se algum for inválido
+func variantParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	productID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return 0, 0, false
+	}
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variantID"))
+	if err != nil {
+		renderError(w, r, errInvalidID)
+		return 0, 0, false
+	}
+	return productID, variantID, true
+}
@@ -0,0 +1,141 @@
+// Package i18n traduz as mensagens da API a partir de códigos estáveis e
+// negocia o idioma da resposta pelo header Accept-Language.
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Locale identifica um idioma suportado pela API
+type Locale string
+
+// Idiomas suportados
+const (
+	PortugueseBR Locale = "pt-BR"
+	English      Locale = "en"
+	Spanish      Locale = "es"
+
+	// Default é usado quando o cliente não aceita nenhum idioma suportado
+	Default = PortugueseBR
+)
+
+// locales segue a ordem das tags do matcher; a primeira é o idioma padrão
+var (
+	locales = []Locale{PortugueseBR, English, Spanish}
+	matcher = language.NewMatcher([]language.Tag{language.BrazilianPortuguese, language.English, language.Spanish})
+)
+
+// catalog guarda os modelos de mensagem de cada idioma, indexados por código
+var catalog = map[Locale]map[string]string{
+	PortugueseBR: portugueseBR,
+	English:      english,
+	Spanish:      spanish,
+}
+
+// Negotiate escolhe o idioma da resposta a partir do valor do header
+// Accept-Language, respeitando os pesos q; sem correspondência, retorna Default
+func Negotiate(acceptLanguage string) Locale {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return locales[index]
+}
+
+// Text é um argumento de mensagem que também é um código do catálogo e deve
+// ser traduzido antes de compor a mensagem
+type Text string
+
+// Message retorna a mensagem de code no idioma informado, formatada com args
+// como em fmt.Sprintf. Códigos sem tradução caem no idioma padrão e, em
+// último caso, no próprio código.
+func Message(locale Locale, code string, args ...interface{}) string {
+	template, ok := catalog[locale][code]
+	if !ok {
+		template, ok = catalog[Default][code]
+	}
+	if !ok {
+		return code
+	}
+	if len(args) == 0 {
+		return template
+	}
+
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		if text, ok := arg.(Text); ok {
+			arg = Message(locale, string(text))
+		}
+		localized[i] = arg
+	}
+	return fmt.Sprintf(template, localized...)
+}
+
+type contextKey struct{}
+
+// WithLocale retorna uma cópia do contexto com o idioma da requisição
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext retorna o idioma da requisição, ou Default se não houver
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return locale
+	}
+	return Default
+}
+
+// Error detalha um erro com uma mensagem traduzível. Err é o erro envolvido:
+// um erro sentinela ou outro *Error com um detalhe mais específico.
+type Error struct {
+	Err  error
+	Code string
+	Args []interface{}
+}
+
+// Errorf envolve err com o detalhe identificado por code
+func Errorf(err error, code string, args ...interface{}) error {
+	return &Error{Err: err, Code: code, Args: args}
+}
+
+// Error retorna a mensagem do erro envolvido seguida dos detalhes no idioma padrão
+func (e *Error) Error() string {
+	var root error = e
+	for {
+		var detail *Error
+		if !errors.As(root, &detail) {
+			break
+		}
+		root = detail.Err
+	}
+	return strings.Join(append([]string{root.Error()}, Details(Default, e)...), ": ")
+}
+
+// Unwrap expõe o erro envolvido
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Details retorna as mensagens de detalhe de err no idioma informado, da mais
+// externa para a mais interna
+func Details(locale Locale, err error) []string {
+	var details []string
+	for {
+		var detail *Error
+		if !errors.As(err, &detail) {
+			return details
+		}
+		details = append(details, Message(locale, detail.Code, detail.Args...))
+		err = detail.Err
+	}
+}
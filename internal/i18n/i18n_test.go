@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Locale
+	}{
+		{"", PortugueseBR},
+		{"pt-BR", PortugueseBR},
+		{"pt", PortugueseBR},
+		{"pt-PT", PortugueseBR},
+		{"en", English},
+		{"en-US", English},
+		{"en-GB,en;q=0.9", English},
+		{"es-AR", Spanish},
+		{"fr", PortugueseBR},
+		{"fr-CA,de;q=0.8", PortugueseBR},
+		{"fr,es;q=0.5", Spanish},
+		{"en;q=0.5,es;q=0.8", Spanish},
+		{"es;q=0.1,pt-BR;q=0.9,en;q=0.5", PortugueseBR},
+		{"en;q=0,es", Spanish},
+		{"*", PortugueseBR},
+		{"en;q=x", PortugueseBR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Negotiate(tt.header); got != tt.want {
+				t.Errorf("Negotiate(%q): esperado %s, obtido %s", tt.header, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	catalog[Default]["test.only_default"] = "só em português: %d"
+	t.Cleanup(func() { delete(catalog[Default], "test.only_default") })
+
+	tests := []struct {
+		name   string
+		locale Locale
+		code   string
+		args   []interface{}
+		want   string
+	}{
+		{"português", PortugueseBR, "product_not_found", nil, "produto não encontrado"},
+		{"inglês", English, "product_not_found", nil, "product not found"},
+		{"espanhol", Spanish, "product_not_found", nil, "producto no encontrado"},
+		{"com argumentos", English, "cart_stock", []interface{}{3}, "3 unit(s) available"},
+		{"idioma desconhecido cai no padrão", Locale("fr"), "product_not_found", nil, "produto não encontrado"},
+		{"código sem tradução cai no padrão", English, "test.only_default", []interface{}{1}, "só em português: 1"},
+		{"código desconhecido retorna o código", Spanish, "no.such.code", nil, "no.such.code"},
+		{"Text é traduzido", English, "field.invalid_type", []interface{}{Text("type.number")}, "must be of type number"},
+		{"Text no idioma da mensagem", Spanish, "field.coupon_rejected", []interface{}{Text("rejection.expired")}, "cupón no aplicable: la promoción terminó"},
+		{"string comum não é traduzida", English, "field.invalid_type", []interface{}{"type.number"}, "must be of type type.number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Message(tt.locale, tt.code, tt.args...); got != tt.want {
+				t.Errorf("Message(%s, %s): esperado %q, obtido %q", tt.locale, tt.code, tt.want, got)
+			}
+		})
+	}
+}
+
+// TestCatalogsMatch garante que todos os idiomas traduzem os mesmos códigos,
+// com os mesmos verbos de formatação
+func TestCatalogsMatch(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for _, locale := range locales[1:] {
+		for code, template := range catalog[Default] {
+			translated, ok := catalog[locale][code]
+			if !ok {
+				t.Errorf("%s: falta o código %s", locale, code)
+				continue
+			}
+			want := verbs.FindAllString(template, -1)
+			if got := verbs.FindAllString(translated, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s usa %v, esperado %v", locale, code, got, want)
+			}
+		}
+		for code := range catalog[locale] {
+			if _, ok := catalog[Default][code]; !ok {
+				t.Errorf("%s: código %s ausente do idioma padrão", locale, code)
+			}
+		}
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	base := errors.New("estoque insuficiente")
+	err := Errorf(Errorf(base, "cart_stock", 3), "field.invalid_type", Text("type.number"))
+
+	if !errors.Is(err, base) {
+		t.Errorf("errors.Is: esperado o erro envolvido em %v", err)
+	}
+	want := []string{"must be of type number", "3 unit(s) available"}
+	if got := Details(English, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("Details: esperado %q, obtido %q", want, got)
+	}
+	if got := err.Error(); got != "estoque insuficiente: deve ser do tipo número: há 3 unidade(s) disponível(is)" {
+		t.Errorf("Error: obtido %q", got)
+	}
+	if got := Details(English, base); got != nil {
+		t.Errorf("Details sem detalhe: esperado nil, obtido %q", got)
+	}
+}
+
+func TestLocaleContext(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != Default {
+		t.Errorf("FromContext sem idioma: esperado %s, obtido %s", Default, got)
+	}
+	if got := FromContext(WithLocale(ctx, Spanish)); got != Spanish {
+		t.Errorf("FromContext: esperado %s, obtido %s", Spanish, got)
+	}
+}
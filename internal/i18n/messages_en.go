@@ -0,0 +1,210 @@
+package i18n
+
+// english é o catálogo em inglês
+var english = map[string]string{
+	// Erros
+	"internal_error":           "Internal server error",
+	"invalid_id":               "Invalid ID",
+	"invalid_body":             "Invalid request body",
+	"invalid_category":         "Invalid category",
+	"invalid_pagination":       "invalid pagination",
+	"invalid_query":            "invalid query",
+	"invalid_cursor":           "invalid cursor",
+	"invalid_user_data":        "invalid user data",
+	"invalid_product_data":     "invalid product data",
+	"user_not_found":           "user not found",
+	"product_not_found":        "product not found",
+	"email_exists":             "email already registered",
+	"version_conflict":         "the record was changed by another request",
+	"unsupported_media_type":   "unsupported patch type",
+	"invalid_patch":            "invalid patch",
+	"patch_conflict":           "patch cannot be applied to the record",
+	"insufficient_stock":       "insufficient stock",
+	"stock_overflow":           "the resulting stock exceeds the limit",
+	"reservation_not_found":    "reservation not found",
+	"product_inactive":         "product is inactive",
+	"order_not_found":          "order not found",
+	"invalid_order_data":       "invalid order data",
+	"invalid_order_transition": "invalid status transition",
+	"invalid_cart_data":        "invalid cart data",
+	"cart_item_not_found":      "item not found in cart",
+	"category_not_found":       "category not found",
+	"invalid_category_data":    "invalid category data",
+	"slug_exists":              "slug already used by another category",
+	"category_not_empty":       "category is not empty",
+	"variant_not_found":        "variant not found",
+	"invalid_variant_data":     "invalid variant data",
+	"sku_exists":               "SKU already used by another variant",
+	"variant_required":         "specify the variant: this product's stock is managed by its variants",
+	"variant_reserved":         "the variant has active reservations",
+	"schedule_not_found":       "price schedule not found",
+	"invalid_schedule_data":    "invalid price schedule data",
+	"schedule_overlap":         "the period overlaps another schedule",
+	"schedule_closed":          "the schedule is already completed or cancelled",
+	"promotion_not_found":      "promotion not found",
+	"invalid_promotion_data":   "invalid promotion data",
+	"invalid_basket_data":      "invalid basket data",
+	"coupon_code_exists":       "coupon code already used by another promotion",
+	"promotion_exhausted":      "the promotion has reached its usage limit",
+	"promotion_user_limit":     "the user has reached the promotion's usage limit",
+
+	// Títulos dos problemas (RFC 7807)
+	"title.bad_request":            "Bad request",
+	"title.invalid_query":          "Invalid query",
+	"title.invalid_patch":          "Invalid patch",
+	"title.not_found":              "Resource not found",
+	"title.email_exists":           "Email already registered",
+	"title.patch_conflict":         "Patch not applicable",
+	"title.version_conflict":       "Outdated version",
+	"title.insufficient_stock":     "Insufficient stock",
+	"title.stock_limit":            "Stock limit exceeded",
+	"title.product_inactive":       "Product inactive",
+	"title.invalid_transition":     "Invalid status transition",
+	"title.slug_exists":            "Slug already in use",
+	"title.category_not_empty":     "Category not empty",
+	"title.sku_exists":             "SKU already used",
+	"title.variant_required":       "Variant required",
+	"title.variant_reserved":       "Variant reserved",
+	"title.schedule_overlap":       "Overlapping schedules",
+	"title.schedule_closed":        "Schedule closed",
+	"title.coupon_code_exists":     "Coupon code already in use",
+	"title.promotion_limit":        "Promotion limit reached",
+	"title.unsupported_media_type": "Unsupported media type",
+	"title.validation_error":       "Invalid data",
+	"title.internal_error":         "Internal server error",
+
+	// Detalhes
+	"pagination_range":           "page must be >= 1 and limit between 1 and %d",
+	"pagination_page_too_large":  "page must be at most %d with limit %d",
+	"query_repeated_param":       "parameter %q given more than once",
+	"query_unknown_params":       "unknown parameter(s) %s; accepted: %s",
+	"query_invalid_bool":         "%s must be true or false, got %q",
+	"query_invalid_price":        "%s must be a non-negative amount with the currency's decimal places, got %q",
+	"query_unknown_currency":     "%s: unsupported currency %q",
+	"query_invalid_sort":         "invalid sort %q; use field or -field",
+	"query_unknown_sort_field":   "unknown sort field %q; accepted: %s",
+	"query_repeated_sort_field":  "sort field %q repeated",
+	"query_invalid_date":         "%s must be in RFC 3339 or YYYY-MM-DD format, got %q",
+	"query_price_range":          "min_price cannot be greater than max_price",
+	"query_date_range":           "created_after must be before created_before",
+	"query_search_term_required": "provide the search term in q",
+	"query_search_cursor":        "search does not support cursors; use page and limit",
+	"query_invalid_id":           "%s must be a positive ID, got %q",
+	"query_not_allowed":          "%s must be one of: %s; got %q",
+	"query_unknown_category":     "unknown category %q",
+	"order_transition":           "the order is %s and cannot become %s",
+	"order_item":                 "item %d (product %d)",
+	"order_cancel_incomplete":    "order %d was cancelled, but not everything it held was returned",
+	"cart_stock":                 "%d unit(s) available",
+	"category_has_children":      "it has %d subcategory(ies)",
+	"category_has_products":      "it has %d product(s), including those in the trash",
+	"variant_inactive":           "variant %s is inactive",
+	"malformed_json":             "malformed JSON: %s",
+	"patch_media_types":          "%q; use %s or %s",
+	"patch_not_a_list":           "the document must be a list of operations",
+	"patch_operation":            "operation %d (%s)",
+	"patch_member_required":      "%s is required",
+	"patch_test_failed":          "the value at %q does not match the expected one",
+	"patch_move_into_self":       "cannot move %q into itself",
+	"patch_unknown_operation":    "unknown operation %q",
+	"patch_invalid_pointer":      "pointer %q must start with /",
+	"patch_path_missing":         "%q does not exist",
+	"patch_not_container":        "%q is neither an object nor a list",
+	"patch_target_not_container": "the target of %q is neither an object nor a list",
+	"patch_remove_root":          "cannot remove the whole document",
+	"patch_invalid_index":        "invalid list index %q",
+	"patch_index_out_of_range":   "index %d out of the list",
+
+	// Violações de validação por campo
+	"field.required":                 "is required",
+	"field.too_short":                "must have at least %d characters",
+	"field.too_long":                 "must have at most %d characters",
+	"field.invalid_email":            "is not a valid email",
+	"field.invalid_amount":           "must be a decimal amount, such as \"599.90\"",
+	"field.unknown_currency":         "unsupported currency %q",
+	"field.not_allowed":              "must be one of: %s",
+	"field.must_be_positive":         "must be greater than zero",
+	"field.too_large":                "must be at most %v",
+	"field.too_many_decimals":        "must have at most %d decimal places",
+	"field.must_not_be_negative":     "must not be negative",
+	"field.invalid_type":             "must be of type %s",
+	"field.unknown_field":            "is not an editable field",
+	"field.not_found":                "not found",
+	"field.inactive":                 "is inactive",
+	"field.duplicate":                "given more than once",
+	"field.currency_mismatch":        "currency %s differs from the order currency %s",
+	"field.too_many_items":           "must have at most %d items",
+	"field.invalid_slug":             "must contain only lowercase unaccented letters, digits and hyphens",
+	"field.cycle":                    "cannot be the category itself or one of its subcategories",
+	"field.invalid_sku":              "must contain only unaccented letters, digits and the separators - _ .",
+	"field.no_product_options":       "the product has no variation options",
+	"field.unknown_option":           "is not a variation option of the product",
+	"field.duplicate_combination":    "variant %s already has this combination",
+	"field.product_currency":         "currency %s differs from the product currency %s",
+	"field.managed_by_variants":      "must be the sum of the variants' stock (%d)",
+	"field.managed_by_stock":         "must be changed through the stock endpoints (current: %d)",
+	"field.managed_by_variant_stock": "must be changed through /stock/increment or /stock/decrement with variant_id %d (current: %d)",
+	"field.in_use_by_variants":       "incompatible with %d existing variant(s)",
+	"field.in_use_by_schedules":      "incompatible with %d open price schedule(s)",
+	"field.invalid_timestamp":        "must be an RFC 3339 date and time, such as 2024-11-29T00:00:00-03:00",
+	"field.must_be_future":           "must be in the future",
+	"field.after_starts_at":          "must be after starts_at",
+	"field.invalid_coupon_code":      "must contain only unaccented letters, digits and the separators - _",
+	"field.max_value":                "must be at most %d",
+	"field.not_for_type":             "does not apply to %s promotions",
+	"field.coupon_rejected":          "coupon not applicable: %s",
+	"type.string":                    "string",
+	"type.boolean":                   "boolean",
+	"type.number":                    "number",
+	"rejection.not_found":            "unknown coupon",
+	"rejection.inactive":             "inactive promotion",
+	"rejection.not_started":          "promotion has not started yet",
+	"rejection.expired":              "promotion has ended",
+	"rejection.exhausted":            "usage limit reached",
+	"rejection.user_limit":           "user usage limit reached",
+	"rejection.user_required":        "requires an identified user",
+	"rejection.no_eligible_items":    "no eligible items",
+	"rejection.min_quantity":         "minimum item quantity not reached",
+	"rejection.currency_mismatch":    "discount currency differs from the basket currency",
+	"rejection.not_stackable":        "not stackable with the other promotions",
+
+	// Mensagens de sucesso
+	"api_healthy":              "API is working correctly",
+	"user_created":             "User created successfully",
+	"user_updated":             "User updated successfully",
+	"user_activated":           "User activated successfully",
+	"user_deactivated":         "User deactivated successfully",
+	"user_trashed":             "User moved to the trash",
+	"user_restored":            "User restored successfully",
+	"product_created":          "Product created successfully",
+	"product_updated":          "Product updated successfully",
+	"product_activated":        "Product activated successfully",
+	"product_deactivated":      "Product deactivated successfully",
+	"product_trashed":          "Product moved to the trash",
+	"product_restored":         "Product restored successfully",
+	"stock_incremented":        "Stock incremented successfully",
+	"stock_decremented":        "Stock decremented successfully",
+	"stock_reserved":           "Stock reserved successfully",
+	"reservation_committed":    "Reservation committed successfully",
+	"reservation_released":     "Reservation released successfully",
+	"order_created":            "Order created successfully",
+	"order_paid":               "Order paid successfully",
+	"order_shipped":            "Order shipped successfully",
+	"order_cancelled":          "Order cancelled successfully",
+	"order_refunded":           "Order refunded successfully",
+	"cart_item_added":          "Item added to cart",
+	"cart_item_updated":        "Cart item updated",
+	"cart_item_removed":        "Item removed from cart",
+	"cart_cleared":             "Cart emptied",
+	"category_created":         "Category created successfully",
+	"category_updated":         "Category updated successfully",
+	"category_deleted":         "Category deleted successfully",
+	"variant_created":          "Variant created successfully",
+	"variant_updated":          "Variant updated successfully",
+	"variant_deleted":          "Variant deleted successfully",
+	"price_scheduled":          "Price change scheduled successfully",
+	"price_schedule_cancelled": "Price schedule cancelled successfully",
+	"promotion_created":        "Promotion created successfully",
+	"promotion_updated":        "Promotion updated successfully",
+	"promotion_deleted":        "Promotion deleted successfully",
+}
@@ -0,0 +1,210 @@
+package i18n
+
+// spanish é o catálogo em espanhol
+var spanish = map[string]string{
+	// Erros
+	"internal_error":           "Error interno del servidor",
+	"invalid_id":               "ID inválido",
+	"invalid_body":             "Datos inválidos",
+	"invalid_category":         "Categoría inválida",
+	"invalid_pagination":       "paginación inválida",
+	"invalid_query":            "consulta inválida",
+	"invalid_cursor":           "cursor inválido",
+	"invalid_user_data":        "datos del usuario inválidos",
+	"invalid_product_data":     "datos del producto inválidos",
+	"user_not_found":           "usuario no encontrado",
+	"product_not_found":        "producto no encontrado",
+	"email_exists":             "email ya registrado",
+	"version_conflict":         "el registro fue modificado por otra solicitud",
+	"unsupported_media_type":   "tipo de patch no soportado",
+	"invalid_patch":            "patch inválido",
+	"patch_conflict":           "patch no aplicable al registro",
+	"insufficient_stock":       "stock insuficiente",
+	"stock_overflow":           "el stock resultante supera el límite",
+	"reservation_not_found":    "reserva no encontrada",
+	"product_inactive":         "producto inactivo",
+	"order_not_found":          "pedido no encontrado",
+	"invalid_order_data":       "datos del pedido inválidos",
+	"invalid_order_transition": "transición de estado inválida",
+	"invalid_cart_data":        "datos del carrito inválidos",
+	"cart_item_not_found":      "ítem no encontrado en el carrito",
+	"category_not_found":       "categoría no encontrada",
+	"invalid_category_data":    "datos de la categoría inválidos",
+	"slug_exists":              "slug ya utilizado por otra categoría",
+	"category_not_empty":       "la categoría no está vacía",
+	"variant_not_found":        "variante no encontrada",
+	"invalid_variant_data":     "datos de la variante inválidos",
+	"sku_exists":               "SKU ya utilizado por otra variante",
+	"variant_required":         "indique la variante: el stock de este producto se controla por sus variantes",
+	"variant_reserved":         "la variante tiene reservas vigentes",
+	"schedule_not_found":       "programación de precio no encontrada",
+	"invalid_schedule_data":    "datos de la programación de precio inválidos",
+	"schedule_overlap":         "el período se superpone a otra programación",
+	"schedule_closed":          "la programación ya fue concluida o cancelada",
+	"promotion_not_found":      "promoción no encontrada",
+	"invalid_promotion_data":   "datos de la promoción inválidos",
+	"invalid_basket_data":      "datos de la cesta inválidos",
+	"coupon_code_exists":       "código de cupón ya utilizado por otra promoción",
+	"promotion_exhausted":      "la promoción alcanzó su límite de usos",
+	"promotion_user_limit":     "el usuario alcanzó el límite de usos de la promoción",
+
+	// Títulos dos problemas (RFC 7807)
+	"title.bad_request":            "Solicitud inválida",
+	"title.invalid_query":          "Consulta inválida",
+	"title.invalid_patch":          "Patch inválido",
+	"title.not_found":              "Recurso no encontrado",
+	"title.email_exists":           "Email ya registrado",
+	"title.patch_conflict":         "Patch no aplicable",
+	"title.version_conflict":       "Versión desactualizada",
+	"title.insufficient_stock":     "Stock insuficiente",
+	"title.stock_limit":            "Límite de stock superado",
+	"title.product_inactive":       "Producto inactivo",
+	"title.invalid_transition":     "Transición de estado inválida",
+	"title.slug_exists":            "Slug ya utilizado",
+	"title.category_not_empty":     "Categoría no vacía",
+	"title.sku_exists":             "SKU ya utilizado",
+	"title.variant_required":       "Variante obligatoria",
+	"title.variant_reserved":       "Variante reservada",
+	"title.schedule_overlap":       "Programaciones superpuestas",
+	"title.schedule_closed":        "Programación cerrada",
+	"title.coupon_code_exists":     "Código de cupón ya utilizado",
+	"title.promotion_limit":        "Límite de la promoción alcanzado",
+	"title.unsupported_media_type": "Tipo de medio no soportado",
+	"title.validation_error":       "Datos inválidos",
+	"title.internal_error":         "Error interno del servidor",
+
+	// Detalhes
+	"pagination_range":           "page debe ser >= 1 y limit entre 1 y %d",
+	"pagination_page_too_large":  "page debe ser como máximo %d con limit %d",
+	"query_repeated_param":       "parámetro %q informado más de una vez",
+	"query_unknown_params":       "parámetro(s) desconocido(s) %s; aceptados: %s",
+	"query_invalid_bool":         "%s debe ser true o false, recibido %q",
+	"query_invalid_price":        "%s debe ser un valor no negativo con los decimales de la moneda, recibido %q",
+	"query_unknown_currency":     "%s: moneda %q no soportada",
+	"query_invalid_sort":         "ordenación %q inválida; use campo o -campo",
+	"query_unknown_sort_field":   "campo de ordenación desconocido %q; aceptados: %s",
+	"query_repeated_sort_field":  "campo de ordenación %q repetido",
+	"query_invalid_date":         "%s debe estar en formato RFC 3339 o AAAA-MM-DD, recibido %q",
+	"query_price_range":          "min_price no puede ser mayor que max_price",
+	"query_date_range":           "created_after debe ser anterior a created_before",
+	"query_search_term_required": "informe el término de búsqueda en q",
+	"query_search_cursor":        "la búsqueda no admite cursor; use page y limit",
+	"query_invalid_id":           "%s debe ser un ID positivo, recibido %q",
+	"query_not_allowed":          "%s debe ser uno de: %s; recibido %q",
+	"query_unknown_category":     "categoría desconocida %q",
+	"order_transition":           "el pedido está %s y no puede pasar a %s",
+	"order_item":                 "ítem %d (producto %d)",
+	"order_cancel_incomplete":    "el pedido %d fue cancelado, pero no se devolvió todo lo que retenía",
+	"cart_stock":                 "hay %d unidad(es) disponible(s)",
+	"category_has_children":      "tiene %d subcategoría(s)",
+	"category_has_products":      "tiene %d producto(s), incluidos los de la papelera",
+	"variant_inactive":           "la variante %s está inactiva",
+	"malformed_json":             "JSON mal formado: %s",
+	"patch_media_types":          "%q; use %s o %s",
+	"patch_not_a_list":           "el documento debe ser una lista de operaciones",
+	"patch_operation":            "operación %d (%s)",
+	"patch_member_required":      "%s es obligatorio",
+	"patch_test_failed":          "el valor en %q no corresponde al esperado",
+	"patch_move_into_self":       "no es posible mover %q dentro de sí mismo",
+	"patch_unknown_operation":    "operación desconocida %q",
+	"patch_invalid_pointer":      "el puntero %q debe comenzar con /",
+	"patch_path_missing":         "%q no existe",
+	"patch_not_container":        "%q no es un objeto ni una lista",
+	"patch_target_not_container": "el destino de %q no es un objeto ni una lista",
+	"patch_remove_root":          "no es posible eliminar el documento entero",
+	"patch_invalid_index":        "índice de lista inválido %q",
+	"patch_index_out_of_range":   "índice %d fuera de la lista",
+
+	// Violações de validação por campo
+	"field.required":                 "es obligatorio",
+	"field.too_short":                "debe tener al menos %d caracteres",
+	"field.too_long":                 "debe tener como máximo %d caracteres",
+	"field.invalid_email":            "no es un email válido",
+	"field.invalid_amount":           "debe ser un valor decimal, como \"599.90\"",
+	"field.unknown_currency":         "moneda %q no soportada",
+	"field.not_allowed":              "debe ser uno de: %s",
+	"field.must_be_positive":         "debe ser mayor que cero",
+	"field.too_large":                "debe ser como máximo %v",
+	"field.too_many_decimals":        "debe tener como máximo %d decimales",
+	"field.must_not_be_negative":     "no puede ser negativo",
+	"field.invalid_type":             "debe ser del tipo %s",
+	"field.unknown_field":            "no es un campo editable",
+	"field.not_found":                "no encontrado",
+	"field.inactive":                 "está inactivo",
+	"field.duplicate":                "informado más de una vez",
+	"field.currency_mismatch":        "la moneda %s difiere de la moneda %s del pedido",
+	"field.too_many_items":           "debe tener como máximo %d ítems",
+	"field.invalid_slug":             "debe contener solo letras minúsculas sin acento, números y guiones",
+	"field.cycle":                    "no puede ser la propia categoría ni una de sus subcategorías",
+	"field.invalid_sku":              "debe contener solo letras sin acento, números y los separadores - _ .",
+	"field.no_product_options":       "el producto no tiene ejes de variación",
+	"field.unknown_option":           "no es un eje de variación del producto",
+	"field.duplicate_combination":    "la variante %s ya tiene esta combinación",
+	"field.product_currency":         "la moneda %s difiere de la moneda %s del producto",
+	"field.managed_by_variants":      "debe ser la suma del stock de las variantes (%d)",
+	"field.managed_by_stock":         "debe modificarse con los endpoints de stock (actual: %d)",
+	"field.managed_by_variant_stock": "debe modificarse con /stock/increment o /stock/decrement con variant_id %d (actual: %d)",
+	"field.in_use_by_variants":       "incompatible con %d variante(s) existente(s)",
+	"field.in_use_by_schedules":      "incompatible con %d programación(es) de precio abierta(s)",
+	"field.invalid_timestamp":        "debe ser una fecha y hora RFC 3339, como 2024-11-29T00:00:00-03:00",
+	"field.must_be_future":           "debe estar en el futuro",
+	"field.after_starts_at":          "debe ser posterior a starts_at",
+	"field.invalid_coupon_code":      "debe contener solo letras sin tilde, números y los separadores - _",
+	"field.max_value":                "debe ser como máximo %d",
+	"field.not_for_type":             "no se aplica a promociones del tipo %s",
+	"field.coupon_rejected":          "cupón no aplicable: %s",
+	"type.string":                    "texto",
+	"type.boolean":                   "booleano",
+	"type.number":                    "número",
+	"rejection.not_found":            "cupón inexistente",
+	"rejection.inactive":             "promoción inactiva",
+	"rejection.not_started":          "la promoción aún no comenzó",
+	"rejection.expired":              "la promoción terminó",
+	"rejection.exhausted":            "límite de usos alcanzado",
+	"rejection.user_limit":           "límite de usos del usuario alcanzado",
+	"rejection.user_required":        "requiere un usuario identificado",
+	"rejection.no_eligible_items":    "ningún ítem elegible",
+	"rejection.min_quantity":         "cantidad mínima de ítems no alcanzada",
+	"rejection.currency_mismatch":    "la moneda del descuento difiere de la moneda de la cesta",
+	"rejection.not_stackable":        "no acumulable con las demás promociones",
+
+	// Mensagens de sucesso
+	"api_healthy":              "La API funciona correctamente",
+	"user_created":             "Usuario creado con éxito",
+	"user_updated":             "Usuario actualizado con éxito",
+	"user_activated":           "Usuario activado con éxito",
+	"user_deactivated":         "Usuario desactivado con éxito",
+	"user_trashed":             "Usuario movido a la papelera",
+	"user_restored":            "Usuario restaurado con éxito",
+	"product_created":          "Producto creado con éxito",
+	"product_updated":          "Producto actualizado con éxito",
+	"product_activated":        "Producto activado con éxito",
+	"product_deactivated":      "Producto desactivado con éxito",
+	"product_trashed":          "Producto movido a la papelera",
+	"product_restored":         "Producto restaurado con éxito",
+	"stock_incremented":        "Stock incrementado con éxito",
+	"stock_decremented":        "Stock decrementado con éxito",
+	"stock_reserved":           "Stock reservado con éxito",
+	"reservation_committed":    "Reserva confirmada con éxito",
+	"reservation_released":     "Reserva liberada con éxito",
+	"order_created":            "Pedido creado con éxito",
+	"order_paid":               "Pedido pagado con éxito",
+	"order_shipped":            "Pedido enviado con éxito",
+	"order_cancelled":          "Pedido cancelado con éxito",
+	"order_refunded":           "Pedido reembolsado con éxito",
+	"cart_item_added":          "Ítem agregado al carrito",
+	"cart_item_updated":        "Ítem del carrito actualizado",
+	"cart_item_removed":        "Ítem eliminado del carrito",
+	"cart_cleared":             "Carrito vaciado",
+	"category_created":         "Categoría creada con éxito",
+	"category_updated":         "Categoría actualizada con éxito",
+	"category_deleted":         "Categoría eliminada con éxito",
+	"variant_created":          "Variante creada con éxito",
+	"variant_updated":          "Variante actualizada con éxito",
+	"variant_deleted":          "Variante eliminada con éxito",
+	"price_scheduled":          "Cambio de precio programado con éxito",
+	"price_schedule_cancelled": "Programación de precio cancelada con éxito",
+	"promotion_created":        "Promoción creada con éxito",
+	"promotion_updated":        "Promoción actualizada con éxito",
+	"promotion_deleted":        "Promoción eliminada con éxito",
+}
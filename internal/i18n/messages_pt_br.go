@@ -0,0 +1,210 @@
+package i18n
+
+// portugueseBR é o catálogo do idioma padrão; todo código deve existir aqui
+var portugueseBR = map[string]string{
+	// Erros
+	"internal_error":           "Erro interno do servidor",
+	"invalid_id":               "ID inválido",
+	"invalid_body":             "Dados inválidos",
+	"invalid_category":         "Categoria inválida",
+	"invalid_pagination":       "paginação inválida",
+	"invalid_query":            "consulta inválida",
+	"invalid_cursor":           "cursor inválido",
+	"invalid_user_data":        "dados do usuário inválidos",
+	"invalid_product_data":     "dados do produto inválidos",
+	"user_not_found":           "usuário não encontrado",
+	"product_not_found":        "produto não encontrado",
+	"email_exists":             "email já cadastrado",
+	"version_conflict":         "o registro foi alterado por outra requisição",
+	"unsupported_media_type":   "tipo de patch não suportado",
+	"invalid_patch":            "patch inválido",
+	"patch_conflict":           "patch não aplicável ao registro",
+	"insufficient_stock":       "estoque insuficiente",
+	"stock_overflow":           "o estoque resultante passa do limite",
+	"reservation_not_found":    "reserva não encontrada",
+	"product_inactive":         "produto inativo",
+	"order_not_found":          "pedido não encontrado",
+	"invalid_order_data":       "dados do pedido inválidos",
+	"invalid_order_transition": "transição de status inválida",
+	"invalid_cart_data":        "dados do carrinho inválidos",
+	"cart_item_not_found":      "item não encontrado no carrinho",
+	"category_not_found":       "categoria não encontrada",
+	"invalid_category_data":    "dados da categoria inválidos",
+	"slug_exists":              "slug já utilizado por outra categoria",
+	"category_not_empty":       "a categoria não está vazia",
+	"variant_not_found":        "variante não encontrada",
+	"invalid_variant_data":     "dados da variante inválidos",
+	"sku_exists":               "SKU já utilizado por outra variante",
+	"variant_required":         "informe a variante: o estoque deste produto é controlado pelas variantes",
+	"variant_reserved":         "a variante tem reservas vigentes",
+	"schedule_not_found":       "agendamento de preço não encontrado",
+	"invalid_schedule_data":    "dados do agendamento de preço inválidos",
+	"schedule_overlap":         "o período se sobrepõe a outro agendamento",
+	"schedule_closed":          "o agendamento já foi concluído ou cancelado",
+	"promotion_not_found":      "promoção não encontrada",
+	"invalid_promotion_data":   "dados da promoção inválidos",
+	"invalid_basket_data":      "dados da cesta inválidos",
+	"coupon_code_exists":       "código de cupom já utilizado por outra promoção",
+	"promotion_exhausted":      "a promoção atingiu o limite de usos",
+	"promotion_user_limit":     "o usuário atingiu o limite de usos da promoção",
+
+	// Títulos dos problemas (RFC 7807)
+	"title.bad_request":            "Requisição inválida",
+	"title.invalid_query":          "Consulta inválida",
+	"title.invalid_patch":          "Patch inválido",
+	"title.not_found":              "Recurso não encontrado",
+	"title.email_exists":           "Email já cadastrado",
+	"title.patch_conflict":         "Patch não aplicável",
+	"title.version_conflict":       "Versão desatualizada",
+	"title.insufficient_stock":     "Estoque insuficiente",
+	"title.stock_limit":            "Limite de estoque excedido",
+	"title.product_inactive":       "Produto inativo",
+	"title.invalid_transition":     "Transição de status inválida",
+	"title.slug_exists":            "Slug já utilizado",
+	"title.category_not_empty":     "Categoria não vazia",
+	"title.sku_exists":             "SKU já utilizado",
+	"title.variant_required":       "Variante obrigatória",
+	"title.variant_reserved":       "Variante reservada",
+	"title.schedule_overlap":       "Agendamentos sobrepostos",
+	"title.schedule_closed":        "Agendamento encerrado",
+	"title.coupon_code_exists":     "Código de cupom já utilizado",
+	"title.promotion_limit":        "Limite da promoção atingido",
+	"title.unsupported_media_type": "Tipo de mídia não suportado",
+	"title.validation_error":       "Dados inválidos",
+	"title.internal_error":         "Erro interno do servidor",
+
+	// Detalhes
+	"pagination_range":           "page deve ser >= 1 e limit entre 1 e %d",
+	"pagination_page_too_large":  "page deve ser no máximo %d com limit %d",
+	"query_repeated_param":       "parâmetro %q informado mais de uma vez",
+	"query_unknown_params":       "parâmetro(s) desconhecido(s) %s; aceitos: %s",
+	"query_invalid_bool":         "%s deve ser true ou false, recebido %q",
+	"query_invalid_price":        "%s deve ser um valor não negativo com as casas decimais da moeda, recebido %q",
+	"query_unknown_currency":     "%s: moeda %q não suportada",
+	"query_invalid_sort":         "ordenação %q inválida; use campo ou -campo",
+	"query_unknown_sort_field":   "campo de ordenação desconhecido %q; aceitos: %s",
+	"query_repeated_sort_field":  "campo de ordenação %q repetido",
+	"query_invalid_date":         "%s deve estar no formato RFC 3339 ou AAAA-MM-DD, recebido %q",
+	"query_price_range":          "min_price não pode ser maior que max_price",
+	"query_date_range":           "created_after deve ser anterior a created_before",
+	"query_search_term_required": "informe o termo de busca em q",
+	"query_search_cursor":        "a busca não suporta cursor; use page e limit",
+	"query_invalid_id":           "%s deve ser um ID positivo, recebido %q",
+	"query_not_allowed":          "%s deve ser um de: %s; recebido %q",
+	"query_unknown_category":     "categoria desconhecida %q",
+	"order_transition":           "o pedido está %s e não pode passar para %s",
+	"order_item":                 "item %d (produto %d)",
+	"order_cancel_incomplete":    "o pedido %d foi cancelado, mas nem tudo o que ele retinha foi devolvido",
+	"cart_stock":                 "há %d unidade(s) disponível(is)",
+	"category_has_children":      "há %d subcategoria(s)",
+	"category_has_products":      "há %d produto(s), incluindo os da lixeira",
+	"variant_inactive":           "a variante %s está inativa",
+	"malformed_json":             "JSON malformado: %s",
+	"patch_media_types":          "%q; use %s ou %s",
+	"patch_not_a_list":           "o documento deve ser uma lista de operações",
+	"patch_operation":            "operação %d (%s)",
+	"patch_member_required":      "%s é obrigatório",
+	"patch_test_failed":          "o valor em %q não corresponde ao esperado",
+	"patch_move_into_self":       "não é possível mover %q para dentro de si mesmo",
+	"patch_unknown_operation":    "operação desconhecida %q",
+	"patch_invalid_pointer":      "ponteiro %q deve começar com /",
+	"patch_path_missing":         "%q não existe",
+	"patch_not_container":        "%q não é um objeto nem uma lista",
+	"patch_target_not_container": "o destino de %q não é um objeto nem uma lista",
+	"patch_remove_root":          "não é possível remover o documento inteiro",
+	"patch_invalid_index":        "índice de lista inválido %q",
+	"patch_index_out_of_range":   "índice %d fora da lista",
+
+	// Violações de validação por campo
+	"field.required":                 "é obrigatório",
+	"field.too_short":                "deve ter pelo menos %d caracteres",
+	"field.too_long":                 "deve ter no máximo %d caracteres",
+	"field.invalid_email":            "não é um email válido",
+	"field.invalid_amount":           "deve ser um valor decimal, como \"599.90\"",
+	"field.unknown_currency":         "moeda %q não suportada",
+	"field.not_allowed":              "deve ser um de: %s",
+	"field.must_be_positive":         "deve ser maior que zero",
+	"field.too_large":                "deve ser no máximo %v",
+	"field.too_many_decimals":        "deve ter no máximo %d casas decimais",
+	"field.must_not_be_negative":     "não pode ser negativo",
+	"field.invalid_type":             "deve ser do tipo %s",
+	"field.unknown_field":            "não é um campo editável",
+	"field.not_found":                "não encontrado",
+	"field.inactive":                 "está inativo",
+	"field.duplicate":                "informado mais de uma vez",
+	"field.currency_mismatch":        "moeda %s difere da moeda %s do pedido",
+	"field.too_many_items":           "deve ter no máximo %d itens",
+	"field.invalid_slug":             "deve conter apenas letras minúsculas sem acento, números e hífens",
+	"field.cycle":                    "não pode ser a própria categoria nem uma de suas subcategorias",
+	"field.invalid_sku":              "deve conter apenas letras sem acento, números e os separadores - _ .",
+	"field.no_product_options":       "o produto não tem eixos de variação",
+	"field.unknown_option":           "não é um eixo de variação do produto",
+	"field.duplicate_combination":    "a variante %s já tem essa combinação",
+	"field.product_currency":         "moeda %s difere da moeda %s do produto",
+	"field.managed_by_variants":      "deve ser a soma do estoque das variantes (%d)",
+	"field.managed_by_stock":         "deve ser alterado pelos endpoints de estoque (atual: %d)",
+	"field.managed_by_variant_stock": "deve ser alterado por /stock/increment ou /stock/decrement com variant_id %d (atual: %d)",
+	"field.in_use_by_variants":       "incompatível com %d variante(s) existente(s)",
+	"field.in_use_by_schedules":      "incompatível com %d agendamento(s) de preço em aberto",
+	"field.invalid_timestamp":        "deve ser uma data e hora RFC 3339, como 2024-11-29T00:00:00-03:00",
+	"field.must_be_future":           "deve estar no futuro",
+	"field.after_starts_at":          "deve ser posterior a starts_at",
+	"field.invalid_coupon_code":      "deve conter apenas letras sem acento, números e os separadores - _",
+	"field.max_value":                "deve ser no máximo %d",
+	"field.not_for_type":             "não se aplica a promoções do tipo %s",
+	"field.coupon_rejected":          "cupom não aplicável: %s",
+	"type.string":                    "texto",
+	"type.boolean":                   "booleano",
+	"type.number":                    "número",
+	"rejection.not_found":            "cupom inexistente",
+	"rejection.inactive":             "promoção inativa",
+	"rejection.not_started":          "promoção ainda não começou",
+	"rejection.expired":              "promoção encerrada",
+	"rejection.exhausted":            "limite de usos atingido",
+	"rejection.user_limit":           "limite de usos do usuário atingido",
+	"rejection.user_required":        "exige um usuário identificado",
+	"rejection.no_eligible_items":    "nenhum item elegível",
+	"rejection.min_quantity":         "quantidade mínima de itens não atingida",
+	"rejection.currency_mismatch":    "moeda do desconto difere da moeda da cesta",
+	"rejection.not_stackable":        "não cumulativa com as demais promoções",
+
+	// Mensagens de sucesso
+	"api_healthy":              "API está funcionando corretamente",
+	"user_created":             "Usuário criado com sucesso",
+	"user_updated":             "Usuário atualizado com sucesso",
+	"user_activated":           "Usuário ativado com sucesso",
+	"user_deactivated":         "Usuário desativado com sucesso",
+	"user_trashed":             "Usuário movido para a lixeira",
+	"user_restored":            "Usuário restaurado com sucesso",
+	"product_created":          "Produto criado com sucesso",
+	"product_updated":          "Produto atualizado com sucesso",
+	"product_activated":        "Produto ativado com sucesso",
+	"product_deactivated":      "Produto desativado com sucesso",
+	"product_trashed":          "Produto movido para a lixeira",
+	"product_restored":         "Produto restaurado com sucesso",
+	"stock_incremented":        "Estoque incrementado com sucesso",
+	"stock_decremented":        "Estoque decrementado com sucesso",
+	"stock_reserved":           "Estoque reservado com sucesso",
+	"reservation_committed":    "Reserva confirmada com sucesso",
+	"reservation_released":     "Reserva liberada com sucesso",
+	"order_created":            "Pedido criado com sucesso",
+	"order_paid":               "Pedido pago com sucesso",
+	"order_shipped":            "Pedido enviado com sucesso",
+	"order_cancelled":          "Pedido cancelado com sucesso",
+	"order_refunded":           "Pedido reembolsado com sucesso",
+	"cart_item_added":          "Item adicionado ao carrinho",
+	"cart_item_updated":        "Item do carrinho atualizado",
+	"cart_item_removed":        "Item removido do carrinho",
+	"cart_cleared":             "Carrinho esvaziado",
+	"category_created":         "Categoria criada com sucesso",
+	"category_updated":         "Categoria atualizada com sucesso",
+	"category_deleted":         "Categoria excluída com sucesso",
+	"variant_created":          "Variante criada com sucesso",
+	"variant_updated":          "Variante atualizada com sucesso",
+	"variant_deleted":          "Variante excluída com sucesso",
+	"price_scheduled":          "Alteração de preço agendada com sucesso",
+	"price_schedule_cancelled": "Agendamento de preço cancelado com sucesso",
+	"promotion_created":        "Promoção criada com sucesso",
+	"promotion_updated":        "Promoção atualizada com sucesso",
+	"promotion_deleted":        "Promoção excluída com sucesso",
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
+)
+
+// Actor lê o responsável pela requisição do header X-Actor e o disponibiliza
+// no contexto, para registro nos históricos
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(actor.WithName(r.Context(), r.Header.Get(actor.Header))))
+	})
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Pendências de um item do carrinho em relação ao catálogo atual
+const (
+	// CartIssuePriceChanged indica que o preço do produto mudou desde que o
+	// item foi adicionado
+	CartIssuePriceChanged = "price_changed"
+	// CartIssueInactive indica que o produto foi desativado
+	CartIssueInactive = "inactive"
+	// CartIssueOutOfStock indica que o produto está sem estoque disponível
+	CartIssueOutOfStock = "out_of_stock"
+	// CartIssueInsufficientStock indica que o estoque disponível é menor que a quantidade do item
+	CartIssueInsufficientStock = "insufficient_stock"
+	// CartIssueUnavailable indica que o produto foi excluído do catálogo
+	CartIssueUnavailable = "unavailable"
+)
+
+// Cart representa o carrinho de compras de um usuário. Um carrinho sem
+// alterações por mais que a janela de inatividade expira e é descartado.
+type Cart struct {
+	UserID int        `json:"user_id"`
+	Items  []CartItem `json:"items"`
+	// Total é a soma dos subtotais pelos preços registrados nos itens
+	Total Money `json:"total"`
+	// HasIssues indica se algum item tem pendências em relação ao catálogo
+	HasIssues bool      `json:"has_issues"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired indica se o carrinho já expirou no instante now
+func (c Cart) Expired(now time.Time) bool {
+	return !c.ExpiresAt.After(now)
+}
+
+// CartItem representa um item do carrinho. Nome e preço unitário são cópias
+// do produto no momento em que o item foi adicionado; os demais campos são
+// calculados a cada leitura a partir do catálogo atual.
+type CartItem struct {
+	ProductID int `json:"product_id"`
+	// VariantID e SKU identificam a variante escolhida, em produtos com variações
+	VariantID int       `json:"variant_id,omitempty"`
+	SKU       string    `json:"sku,omitempty"`
+	Name      string    `json:"name"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice Money     `json:"unit_price"`
+	Subtotal  Money     `json:"subtotal"`
+	AddedAt   time.Time `json:"added_at"`
+	// CurrentPrice é o preço atual do produto, ausente se ele foi excluído
+	CurrentPrice *Money `json:"current_price,omitempty"`
+	// Issues lista as pendências do item (CartIssue*)
+	Issues []string `json:"issues,omitempty"`
+}
+
+// CartItemRequest representa a requisição para adicionar um produto ao
+// carrinho; VariantID é obrigatório em produtos com variações
+type CartItemRequest struct {
+	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id,omitempty"`
+	Quantity  int `json:"quantity"`
+}
+
+// CartItemUpdateRequest representa a requisição para alterar a quantidade de um item
+type CartItemUpdateRequest struct {
+	Quantity int `json:"quantity"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Category representa uma categoria de produtos. As categorias formam uma
+// árvore: ParentID nulo indica uma categoria raiz.
+type Category struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Slug identifica a categoria nas URLs e é único entre todas as categorias
+	Slug     string `json:"slug"`
+	ParentID *int   `json:"parent_id"`
+	// ProductCount é a quantidade de produtos fora da lixeira na categoria;
+	// TotalProductCount inclui os produtos das subcategorias
+	ProductCount      int        `json:"product_count"`
+	TotalProductCount int        `json:"total_product_count"`
+	Children          []Category `json:"children"`
+}
+
+// CategoryRequest representa a requisição para criar/atualizar uma categoria;
+// sem slug, ele é gerado a partir do nome
+type CategoryRequest struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID *int   `json:"parent_id"`
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency é a moeda assumida quando nenhuma é informada
+const DefaultCurrency = "BRL"
+
+// currencyExponents guarda a quantidade de casas decimais (unidades menores)
+// de cada moeda ISO 4217 aceita
+var currencyExponents = map[string]int{
+	"ARS": 2,
+	"BRL": 2,
+	"CLP": 0,
+	"COP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"MXN": 2,
+	"PYG": 0,
+	"USD": 2,
+	"UYU": 2,
+}
+
+var (
+	ErrUnknownCurrency = errors.New("moeda não suportada")
+	ErrInvalidAmount   = errors.New("valor monetário inválido")
+	ErrTooManyDecimals = errors.New("valor com mais casas decimais que a moeda permite")
+	ErrAmountOverflow  = errors.New("valor monetário acima do limite")
+)
+
+// CurrencyExponent retorna as casas decimais da moeda e se ela é suportada
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// Money representa um valor monetário exato: Amount em unidades menores da
+// moeda (centavos, para BRL) e Currency no código ISO 4217. Em JSON, o valor
+// é serializado como texto decimal: {"amount": "599.90", "currency": "BRL"}.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// ParseMoney converte um valor decimal em texto ("599.90") na moeda informada,
+// rejeitando mais casas decimais do que a moeda possui
+func ParseMoney(amount, currency string) (Money, error) {
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	negative := strings.HasPrefix(amount, "-")
+	whole, fraction, hasPoint := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+	if whole == "" || (hasPoint && fraction == "") || !digitsOnly(whole) || !digitsOnly(fraction) {
+		return Money{}, ErrInvalidAmount
+	}
+	if trimmed := strings.TrimRight(fraction, "0"); len(trimmed) > exponent {
+		return Money{}, ErrTooManyDecimals
+	}
+	fraction += strings.Repeat("0", exponent)
+
+	minor, err := strconv.ParseInt(whole+fraction[:exponent], 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// digitsOnly indica se o texto contém apenas dígitos decimais
+func digitsOnly(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal retorna o valor como texto decimal com as casas da moeda ("599.90")
+func (m Money) Decimal() string {
+	exponent := currencyExponents[m.Currency]
+	sign, minor := "", uint64(m.Amount)
+	if m.Amount < 0 {
+		sign, minor = "-", uint64(-m.Amount)
+		if m.Amount == math.MinInt64 {
+			minor = uint64(math.MaxInt64) + 1
+		}
+	}
+
+	digits := strconv.FormatUint(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Times retorna o valor multiplicado por quantity, ou ErrAmountOverflow se o
+// resultado não couber em int64
+func (m Money) Times(quantity int) (Money, error) {
+	n := int64(quantity)
+	product := m.Amount * n
+	if n != 0 && (product/n != m.Amount || (n == -1 && m.Amount == math.MinInt64)) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: product, Currency: m.Currency}, nil
+}
+
+// Plus retorna a soma dos valores, na moeda de m, ou ErrAmountOverflow se o
+// resultado não couber em int64
+func (m Money) Plus(other Money) (Money, error) {
+	sum := m.Amount + other.Amount
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// String retorna o valor seguido da moeda ("599.90 BRL")
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// moneyJSON é a representação de Money em JSON
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON serializa o valor como texto decimal, evitando a perda de
+// precisão de números de ponto flutuante nos clientes
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON lê o formato produzido por MarshalJSON
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	parsed, err := ParseMoney(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MoneyRequest é um valor monetário recebido em uma requisição, ainda não
+// validado: Amount como texto decimal e Currency opcional (padrão
+// DefaultCurrency). Por compatibilidade, também aceita um número JSON (o
+// formato antigo de price, obsoleto), interpretado na moeda padrão.
+type MoneyRequest struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// UnmarshalJSON aceita o objeto {"amount", "currency"} ou um número JSON
+func (m *MoneyRequest) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err == nil && len(data) > 0 && data[0] != '"' {
+		*m = MoneyRequest{Amount: number.String()}
+		return nil
+	}
+
+	type plain MoneyRequest
+	var v plain
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+	*m = MoneyRequest(v)
+	return nil
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     int64
+		err      error
+	}{
+		{"599.90", "BRL", 59990, nil},
+		{"599.9", "BRL", 59990, nil},
+		{"599", "BRL", 59900, nil},
+		{"0.05", "BRL", 5, nil},
+		{"0", "BRL", 0, nil},
+		{"-10.50", "BRL", -1050, nil},
+		{"-0.01", "USD", -1, nil},
+		{"1.500", "BRL", 150, nil},
+		{"1500", "JPY", 1500, nil},
+		{"1500.0", "JPY", 1500, nil},
+		{"92233720368547758.07", "BRL", math.MaxInt64, nil},
+		{"599.901", "BRL", 0, ErrTooManyDecimals},
+		{"1500.5", "JPY", 0, ErrTooManyDecimals},
+		{"599.90", "XYZ", 0, ErrUnknownCurrency},
+		{"599.90", "brl", 0, ErrUnknownCurrency},
+		{"599.90", "", 0, ErrUnknownCurrency},
+		{"", "BRL", 0, ErrInvalidAmount},
+		{"-", "BRL", 0, ErrInvalidAmount},
+		{".50", "BRL", 0, ErrInvalidAmount},
+		{"10.", "BRL", 0, ErrInvalidAmount},
+		{"+10", "BRL", 0, ErrInvalidAmount},
+		{"1,50", "BRL", 0, ErrInvalidAmount},
+		{"1e3", "BRL", 0, ErrInvalidAmount},
+		{" 10", "BRL", 0, ErrInvalidAmount},
+		{"92233720368547758.08", "BRL", 0, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			got, err := ParseMoney(tt.amount, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseMoney: esperado erro %v, obtido %v", tt.err, err)
+			}
+			if err == nil && (got.Amount != tt.want || got.Currency != tt.currency) {
+				t.Errorf("ParseMoney: esperado %d %s, obtido %+v", tt.want, tt.currency, got)
+			}
+		})
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{Amount: 59990, Currency: "BRL"}, "599.90"},
+		{Money{Amount: 5, Currency: "BRL"}, "0.05"},
+		{Money{Amount: 0, Currency: "BRL"}, "0.00"},
+		{Money{Amount: -1050, Currency: "BRL"}, "-10.50"},
+		{Money{Amount: -5, Currency: "USD"}, "-0.05"},
+		{Money{Amount: 1500, Currency: "JPY"}, "1500"},
+		{Money{Amount: -1500, Currency: "CLP"}, "-1500"},
+		{Money{Amount: math.MinInt64, Currency: "BRL"}, "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.want {
+				t.Errorf("Decimal: esperado %q, obtido %q", tt.want, got)
+			}
+		})
+	}
+
+	if got := (Money{Amount: 59990, Currency: "BRL"}).String(); got != "599.90 BRL" {
+		t.Errorf("String: esperado %q, obtido %q", "599.90 BRL", got)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  func() (Money, error)
+		want int64
+		err  error
+	}{
+		{"Times", func() (Money, error) { return Money{Amount: 59990, Currency: "BRL"}.Times(3) }, 179970, nil},
+		{"Times por zero", func() (Money, error) { return Money{Amount: math.MaxInt64, Currency: "BRL"}.Times(0) }, 0, nil},
+		{"Times no limite", func() (Money, error) { return Money{Amount: math.MaxInt64 / 2, Currency: "BRL"}.Times(2) }, math.MaxInt64 - 1, nil},
+		{"Times acima do limite", func() (Money, error) { return Money{Amount: 5000000000000000000, Currency: "BRL"}.Times(2) }, 0, ErrAmountOverflow},
+		{"Times com quantidade enorme", func() (Money, error) { return Money{Amount: 100001, Currency: "BRL"}.Times(9223372036854775) }, 0, ErrAmountOverflow},
+		{"Times negativo", func() (Money, error) { return Money{Amount: math.MinInt64, Currency: "BRL"}.Times(-1) }, 0, ErrAmountOverflow},
+		{"Plus", func() (Money, error) {
+			return Money{Amount: 100, Currency: "BRL"}.Plus(Money{Amount: -30, Currency: "BRL"})
+		}, 70, nil},
+		{"Plus no limite", func() (Money, error) {
+			return Money{Amount: math.MaxInt64 - 1, Currency: "BRL"}.Plus(Money{Amount: 1, Currency: "BRL"})
+		}, math.MaxInt64, nil},
+		{"Plus acima do limite", func() (Money, error) {
+			return Money{Amount: math.MaxInt64, Currency: "BRL"}.Plus(Money{Amount: 1, Currency: "BRL"})
+		}, 0, ErrAmountOverflow},
+		{"Plus abaixo do limite", func() (Money, error) {
+			return Money{Amount: math.MinInt64, Currency: "BRL"}.Plus(Money{Amount: -1, Currency: "BRL"})
+		}, 0, ErrAmountOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("esperado erro %v, obtido %v", tt.err, err)
+			}
+			if err == nil && (got.Amount != tt.want || got.Currency != "BRL") {
+				t.Errorf("esperado %d BRL, obtido %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	for _, m := range []Money{
+		{Amount: 59990, Currency: "BRL"},
+		{Amount: -1050, Currency: "EUR"},
+		{Amount: 1500, Currency: "JPY"},
+	} {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		var got Money
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if got != m {
+			t.Errorf("Unmarshal(%s): esperado %+v, obtido %+v", data, m, got)
+		}
+	}
+
+	data, _ := json.Marshal(Money{Amount: 59990, Currency: "BRL"})
+	if string(data) != `{"amount":"599.90","currency":"BRL"}` {
+		t.Errorf("Marshal: obtido %s", data)
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount":"1.00","currency":"XYZ"}`), &m); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Unmarshal com moeda desconhecida: esperado ErrUnknownCurrency, obtido %v", err)
+	}
+}
+
+func TestMoneyRequestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    MoneyRequest
+		invalid bool
+	}{
+		{"objeto", `{"amount":"599.90","currency":"USD"}`, MoneyRequest{Amount: "599.90", Currency: "USD"}, false},
+		{"objeto sem moeda", `{"amount":"599.90"}`, MoneyRequest{Amount: "599.90"}, false},
+		{"número obsoleto", `599.90`, MoneyRequest{Amount: "599.90"}, false},
+		{"número negativo", `-10.5`, MoneyRequest{Amount: "-10.5"}, false},
+		{"null", `null`, MoneyRequest{}, false},
+		{"texto com o valor", `"599.90"`, MoneyRequest{}, true},
+		{"membro desconhecido", `{"amount":"599.90","value":1}`, MoneyRequest{}, true},
+		{"valor numérico no objeto", `{"amount":599.90}`, MoneyRequest{}, true},
+		{"lista", `["599.90"]`, MoneyRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got MoneyRequest
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.invalid {
+				if err == nil {
+					t.Errorf("Unmarshal(%s): esperado erro, obtido %+v", tt.data, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.data, err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s): esperado %+v, obtido %+v", tt.data, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Status de um pedido
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderCancelled = "cancelled"
+	OrderRefunded  = "refunded"
+)
+
+// OrderTransitions define os status que podem suceder cada status de pedido;
+// cancelled e refunded são finais
+var OrderTransitions = map[string][]string{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderShipped, OrderCancelled},
+	OrderShipped: {OrderRefunded},
+}
+
+// CanTransition indica se um pedido pode passar do status from para to
+func CanTransition(from, to string) bool {
+	for _, next := range OrderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Order representa um pedido de um usuário
+type Order struct {
+	ID     int         `json:"id"`
+	UserID int         `json:"user_id"`
+	Status string      `json:"status"`
+	Items  []OrderItem `json:"items"`
+	// Subtotal é a soma dos subtotais dos itens, calculada com os preços do
+	// catálogo na criação do pedido
+	Subtotal Money `json:"subtotal"`
+	// Discount é a soma dos descontos das promoções aplicadas
+	Discount  Money           `json:"discount"`
+	Discounts []OrderDiscount `json:"discounts"`
+	// Total é o subtotal menos os descontos
+	Total     Money     `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Version é incrementada a cada mudança de status e exposta como ETag
+	Version int `json:"version"`
+}
+
+// OrderItem representa um item de pedido. Nome e preço unitário são cópias do
+// produto na criação do pedido, preservadas se o catálogo mudar depois.
+type OrderItem struct {
+	ProductID int `json:"product_id"`
+	// VariantID e SKU identificam a variante pedida, em produtos com variações
+	VariantID int    `json:"variant_id,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice Money  `json:"unit_price"`
+	Subtotal  Money  `json:"subtotal"`
+	// ReservationID é a reserva de estoque feita para o item na criação do pedido
+	ReservationID int `json:"reservation_id,omitempty"`
+}
+
+// OrderDiscount representa uma promoção aplicada ao pedido. Nome, código e
+// valor são cópias da avaliação na criação do pedido.
+type OrderDiscount struct {
+	PromotionID int    `json:"promotion_id"`
+	Name        string `json:"name"`
+	Code        string `json:"code,omitempty"`
+	Amount      Money  `json:"amount"`
+	// RedemptionID é o resgate registrado para a promoção na criação do pedido
+	RedemptionID int `json:"redemption_id"`
+}
+
+// OrderRequest representa a requisição para criar um pedido; os preços vêm
+// sempre do catálogo, nunca do cliente, e os descontos das promoções
+// automáticas e dos cupons informados
+type OrderRequest struct {
+	UserID  int                `json:"user_id"`
+	Items   []OrderItemRequest `json:"items"`
+	Coupons []string           `json:"coupons,omitempty"`
+}
+
+// OrderItemRequest representa um item da requisição de pedido; VariantID é
+// obrigatório em produtos com variações
+type OrderItemRequest struct {
+	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id,omitempty"`
+	Quantity  int `json:"quantity"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "math"
+
+// Limites de paginação aplicados às listagens
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Pagination representa a página solicitada em uma listagem.
+// Quando Cursor está presente, a paginação é por keyset e Page é ignorado.
+type Pagination struct {
+	Page   int
+	Limit  int
+	Cursor string
+}
+
+// Offset retorna quantos registros devem ser ignorados antes da página;
+// deslocamentos que não cabem em um int são limitados a math.MaxInt
+func (p Pagination) Offset() int {
+	if p.Cursor != "" || p.Page <= 1 || p.Limit <= 0 {
+		return 0
+	}
+	if p.Page-1 > math.MaxInt/p.Limit {
+		return math.MaxInt
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// Keyset identifica a posição logo após o último item de uma página.
+// Sort descreve a ordenação para a qual a posição foi emitida, Values
+// guarda os valores das chaves de ordenação e ID desempata registros iguais.
+type Keyset struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v,omitempty"`
+	ID     int           `json:"id"`
+}
+
+// PageInfo descreve a página retornada por uma listagem
+type PageInfo struct {
+	Total      int
+	NextCursor string
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPaginationOffset(t *testing.T) {
+	tests := []struct {
+		page Pagination
+		want int
+	}{
+		{Pagination{Page: 1, Limit: 20}, 0},
+		{Pagination{Page: 3, Limit: 20}, 40},
+		{Pagination{Page: 0, Limit: 20}, 0},
+		{Pagination{Page: 3, Limit: 20, Cursor: "abc"}, 0},
+		{Pagination{Page: math.MaxInt/100 + 1, Limit: 100}, math.MaxInt / 100 * 100},
+		{Pagination{Page: 184467440737095517, Limit: 100}, math.MaxInt},
+		{Pagination{Page: math.MaxInt, Limit: 2}, math.MaxInt},
+	}
+	for _, tt := range tests {
+		if got := tt.page.Offset(); got != tt.want {
+			t.Errorf("Offset(%+v): esperado %d, obtido %d", tt.page, tt.want, got)
+		}
+	}
+}
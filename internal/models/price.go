@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Status dos agendamentos de preço
+const (
+	ScheduleScheduled = "scheduled"
+	ScheduleActive    = "active"
+	ScheduleCompleted = "completed"
+	ScheduleCancelled = "cancelled"
+)
+
+// PriceChange representa uma entrada do histórico de preços de um produto.
+// O histórico só recebe inclusões: Previous é o preço anterior (nulo no
+// cadastro) e ScheduleID identifica o agendamento que aplicou a alteração,
+// quando houver.
+type PriceChange struct {
+	ID         int       `json:"id"`
+	ProductID  int       `json:"product_id"`
+	Price      Money     `json:"price"`
+	Previous   *Money    `json:"previous_price"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	ScheduleID int       `json:"schedule_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PriceSchedule representa uma alteração de preço agendada. Em StartsAt o
+// preço do produto passa a ser Price; com EndsAt, o preço anterior, guardado
+// em PreviousPrice enquanto o agendamento está ativo, volta a valer nesse
+// instante. Sem EndsAt, a alteração é definitiva.
+type PriceSchedule struct {
+	ID            int        `json:"id"`
+	ProductID     int        `json:"product_id"`
+	Price         Money      `json:"price"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	Status        string     `json:"status"`
+	PreviousPrice *Money     `json:"previous_price,omitempty"`
+	Reason        string     `json:"reason"`
+	Actor         string     `json:"actor"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Open indica se o agendamento ainda vai alterar o preço: agendado ou ativo
+func (s PriceSchedule) Open() bool {
+	return s.Status == ScheduleScheduled || s.Status == ScheduleActive
+}
+
+// PriceScheduleRequest representa o agendamento de uma alteração de preço;
+// os instantes seguem a RFC 3339 e ends_at é opcional
+type PriceScheduleRequest struct {
+	Price    MoneyRequest `json:"price"`
+	StartsAt string       `json:"starts_at"`
+	EndsAt   string       `json:"ends_at,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+}
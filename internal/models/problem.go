@@ -0,0 +1,17 @@
+package models
+
+// ContentTypeProblem é o tipo de mídia das respostas de erro no formato RFC 7807
+const ContentTypeProblem = "application/problem+json"
+
+// Problem representa um erro no formato application/problem+json (RFC 7807)
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Errors detalha as violações por campo quando a validação falha
+	Errors []FieldError `json:"errors,omitempty"`
+}
@@ -1,21 +1,42 @@
 package models
 
+import "time"
+
 // Product representa um produto no sistema
 type Product struct {
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
-	Category    string  `json:"category"`
-	Active      bool    `json:"active"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       Money  `json:"price"`
+	Stock       int    `json:"stock"`
+	CategoryID  int    `json:"category_id"`
+	// Category é uma cópia do nome da categoria, atualizada quando ela é renomeada
+	Category string `json:"category"`
+	// Options são os eixos de variação; com eles, o estoque é controlado
+	// pelas variantes e Stock é a soma do estoque delas
+	Options []ProductOption `json:"options"`
+	Active  bool            `json:"active"`
+	// Version é incrementada a cada alteração e exposta como ETag
+	Version int `json:"version"`
+	// DeletedAt é preenchido quando o produto está na lixeira
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
-// ProductRequest representa a requisição para criar/atualizar um produto
+// ProductRequest representa a requisição para criar/atualizar um produto. A
+// categoria é indicada por CategoryID ou, por compatibilidade, pelo slug ou
+// nome em Category; CategoryID prevalece quando ambos são informados.
 type ProductRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
-	Category    string  `json:"category"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       MoneyRequest    `json:"price"`
+	Stock       int             `json:"stock"`
+	CategoryID  int             `json:"category_id"`
+	Category    string          `json:"category"`
+	Options     []ProductOption `json:"options"`
+}
+
+// ProductSearchResult representa um produto encontrado na busca textual e sua relevância
+type ProductSearchResult struct {
+	Product Product `json:"product"`
+	Score   float64 `json:"score"`
 }
@@ -0,0 +1,151 @@
+package models
+
+import "time"
+
+// Tipos de desconto de uma promoção
+const (
+	// DiscountPercentage desconta um percentual do valor dos itens elegíveis
+	DiscountPercentage = "percentage"
+	// DiscountFixed desconta um valor fixo, repartido entre os itens elegíveis
+	DiscountFixed = "fixed"
+)
+
+// Motivos pelos quais uma promoção não é aplicada a uma cesta
+const (
+	RejectionNotFound     = "not_found"
+	RejectionInactive     = "inactive"
+	RejectionNotStarted   = "not_started"
+	RejectionExpired      = "expired"
+	RejectionExhausted    = "exhausted"
+	RejectionUserLimit    = "user_limit"
+	RejectionUserRequired = "user_required"
+	RejectionNoEligible   = "no_eligible_items"
+	RejectionMinQuantity  = "min_quantity"
+	RejectionCurrency     = "currency_mismatch"
+	RejectionNotStackable = "not_stackable"
+)
+
+// Promotion representa uma regra de desconto. Sem Code, a promoção é aplicada
+// automaticamente a toda cesta elegível; com Code, apenas quando o cupom é
+// informado. Sem CategoryIDs nem ProductIDs, todos os produtos são elegíveis.
+type Promotion struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Code é o código do cupom, único e em maiúsculas
+	Code string `json:"code,omitempty"`
+	Type string `json:"type"`
+	// Percentage é o percentual descontado, de 1 a 100, nas promoções percentage
+	Percentage int `json:"percentage,omitempty"`
+	// Amount é o valor descontado da cesta, nas promoções fixed
+	Amount *Money `json:"amount,omitempty"`
+	// CategoryIDs inclui as subcategorias de cada categoria informada
+	CategoryIDs []int `json:"category_ids"`
+	ProductIDs  []int `json:"product_ids"`
+	// MinQuantity é a quantidade mínima de unidades elegíveis na cesta
+	MinQuantity int        `json:"min_quantity"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	// MaxUses e MaxUsesPerUser limitam os resgates; zero indica sem limite
+	MaxUses        int `json:"max_uses"`
+	MaxUsesPerUser int `json:"max_uses_per_user"`
+	// Uses é a quantidade de resgates vigentes, calculada a cada leitura
+	Uses int `json:"uses"`
+	// Stackable indica se a promoção pode ser combinada com outras cumulativas;
+	// uma promoção não cumulativa só é aplicada sozinha
+	Stackable bool      `json:"stackable"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PromotionRequest representa a requisição para criar/atualizar uma promoção;
+// os instantes seguem a RFC 3339 e, sem active, a promoção fica ativa
+type PromotionRequest struct {
+	Name           string        `json:"name"`
+	Code           string        `json:"code"`
+	Type           string        `json:"type"`
+	Percentage     int           `json:"percentage"`
+	Amount         *MoneyRequest `json:"amount"`
+	CategoryIDs    []int         `json:"category_ids"`
+	ProductIDs     []int         `json:"product_ids"`
+	MinQuantity    int           `json:"min_quantity"`
+	StartsAt       string        `json:"starts_at"`
+	EndsAt         string        `json:"ends_at"`
+	MaxUses        int           `json:"max_uses"`
+	MaxUsesPerUser int           `json:"max_uses_per_user"`
+	Stackable      bool          `json:"stackable"`
+	Active         *bool         `json:"active"`
+}
+
+// Redemption representa o uso de uma promoção por um usuário em um pedido;
+// os resgates vigentes contam para os limites da promoção
+type Redemption struct {
+	ID          int       `json:"id"`
+	PromotionID int       `json:"promotion_id"`
+	UserID      int       `json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BasketRequest representa uma cesta a avaliar contra as promoções vigentes;
+// sem user_id, promoções com limite por usuário não são aplicadas
+type BasketRequest struct {
+	UserID  int                `json:"user_id"`
+	Items   []OrderItemRequest `json:"items"`
+	Coupons []string           `json:"coupons"`
+}
+
+// CouponsRequest representa os cupons informados na avaliação do carrinho
+type CouponsRequest struct {
+	Coupons []string `json:"coupons"`
+}
+
+// Evaluation é o resultado da avaliação de uma cesta: os itens com os
+// descontos de cada um, as promoções aplicadas e as que ficaram de fora
+type Evaluation struct {
+	UserID   int              `json:"user_id,omitempty"`
+	Items    []EvaluationItem `json:"items"`
+	Subtotal Money            `json:"subtotal"`
+	Discount Money            `json:"discount"`
+	Total    Money            `json:"total"`
+	// Promotions são as promoções aplicadas, na ordem em que foram calculadas
+	Promotions []AppliedPromotion `json:"promotions"`
+	// Rejected lista os cupons informados que não foram aplicados e as
+	// promoções automáticas preteridas pelas regras de acumulação
+	Rejected []RejectedPromotion `json:"rejected"`
+}
+
+// EvaluationItem representa um item da cesta avaliada, com o preço atual do catálogo
+type EvaluationItem struct {
+	ProductID int            `json:"product_id"`
+	VariantID int            `json:"variant_id,omitempty"`
+	SKU       string         `json:"sku,omitempty"`
+	Name      string         `json:"name"`
+	Quantity  int            `json:"quantity"`
+	UnitPrice Money          `json:"unit_price"`
+	Subtotal  Money          `json:"subtotal"`
+	Discount  Money          `json:"discount"`
+	Total     Money          `json:"total"`
+	Discounts []ItemDiscount `json:"discounts"`
+}
+
+// ItemDiscount é a parte do desconto de uma promoção atribuída a um item
+type ItemDiscount struct {
+	PromotionID int   `json:"promotion_id"`
+	Amount      Money `json:"amount"`
+}
+
+// AppliedPromotion é uma promoção aplicada à cesta e o desconto total que ela concedeu
+type AppliedPromotion struct {
+	PromotionID int    `json:"promotion_id"`
+	Name        string `json:"name"`
+	Code        string `json:"code,omitempty"`
+	Type        string `json:"type"`
+	Amount      Money  `json:"amount"`
+}
+
+// RejectedPromotion é um cupom ou promoção não aplicado e o motivo (Rejection*)
+type RejectedPromotion struct {
+	PromotionID int    `json:"promotion_id,omitempty"`
+	Code        string `json:"code,omitempty"`
+	Reason      string `json:"reason"`
+}
@@ -2,17 +2,23 @@ package models
 
 // Response representa uma resposta padrão da API
 type Response struct {
-	Success bool        `json:"success"`
+	Success bool `json:"success"`
+	// Code identifica a mensagem ou o erro e não muda com o idioma
+	Code    string      `json:"code,omitempty"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	// Errors detalha as violações por campo quando a validação falha
+	Errors []FieldError `json:"errors,omitempty"`
 }
 
-// PaginatedResponse representa uma resposta paginada
+// PaginatedResponse representa uma resposta paginada.
+// Page é omitido na paginação por cursor; NextCursor é omitido na última página.
 type PaginatedResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data"`
-	Page    int         `json:"page"`
-	Limit   int         `json:"limit"`
-	Total   int         `json:"total"`
+	Success    bool        `json:"success"`
+	Data       interface{} `json:"data"`
+	Page       int         `json:"page,omitempty"`
+	Limit      int         `json:"limit"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
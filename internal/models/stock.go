@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Tipos de movimentação de estoque
+const (
+	MovementPurchase   = "purchase"
+	MovementSale       = "sale"
+	MovementAdjustment = "adjustment"
+	MovementReturn     = "return"
+)
+
+// StockRequest representa uma operação de estoque: a quantidade e, opcionalmente,
+// o tipo e o motivo registrados na movimentação. Em produtos com variações,
+// VariantID indica a variante movimentada e é obrigatório.
+type StockRequest struct {
+	Quantity  int    `json:"quantity"`
+	Type      string `json:"type,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	VariantID int    `json:"variant_id,omitempty"`
+}
+
+// StockMovement representa uma entrada do histórico de estoque de um produto.
+// O histórico só recebe inclusões: Quantity é a variação com sinal (negativa
+// nas saídas) e Balance é o estoque do produto logo após a movimentação, de
+// modo que a soma das quantidades sempre reproduz o estoque atual. VariantID
+// identifica a variante movimentada, quando houver; Balance continua sendo o
+// estoque total do produto.
+type StockMovement struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	VariantID int       `json:"variant_id,omitempty"`
+	Type      string    `json:"type"`
+	Quantity  int       `json:"quantity"`
+	Balance   int       `json:"balance"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockReservation representa unidades de um produto, ou de uma de suas
+// variantes, retidas até serem confirmadas (commit), liberadas (release) ou
+// até expirarem. Enquanto vigente, a reserva reduz o estoque disponível, mas
+// não o estoque do produto.
+type StockReservation struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	VariantID int       `json:"variant_id,omitempty"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Active indica se a reserva ainda está vigente no instante informado
+func (r StockReservation) Active(now time.Time) bool {
+	return now.Before(r.ExpiresAt)
+}
@@ -1,13 +1,19 @@
 package models
 
+import "time"
+
 // User representa um usuário no sistema
 type User struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
-	Active   bool   `json:"active"`
-	CreateAt string `json:"created_at"`
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	Active   bool      `json:"active"`
+	CreateAt time.Time `json:"created_at"`
+	// Version é incrementada a cada alteração e exposta como ETag
+	Version int `json:"version"`
+	// DeletedAt é preenchido quando o usuário está na lixeira
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // UserRequest representa a requisição para criar/atualizar um usuário
@@ -0,0 +1,11 @@
+package models
+
+// FieldError descreve uma regra de validação violada por um campo da requisição
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// MessageKey e Args permitem traduzir Message para o idioma da requisição
+	MessageKey string        `json:"-"`
+	Args       []interface{} `json:"-"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// ProductOption é um eixo de variação de um produto, como "switch" ou
+// "layout", com os valores que as variantes podem assumir
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// Variant representa uma variante vendável de um produto, identificada pelo
+// SKU e pela combinação de um valor de cada eixo do produto. Em um produto com
+// eixos de variação, o estoque é controlado pelas variantes e o do produto é
+// a soma delas.
+type Variant struct {
+	ID        int               `json:"id"`
+	ProductID int               `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	// PriceOverride substitui o preço do produto, na mesma moeda; nulo indica
+	// que a variante usa o preço do produto
+	PriceOverride *Money `json:"price_override"`
+	// Price é o preço efetivo da variante, calculado a cada leitura
+	Price  Money `json:"price"`
+	Stock  int   `json:"stock"`
+	Active bool  `json:"active"`
+}
+
+// VariantRequest representa a requisição para criar/atualizar uma variante;
+// sem active, a variante fica ativa
+type VariantRequest struct {
+	SKU           string            `json:"sku"`
+	Options       map[string]string `json:"options"`
+	PriceOverride *MoneyRequest     `json:"price_override"`
+	Stock         int               `json:"stock"`
+	Active        *bool             `json:"active"`
+}
@@ -0,0 +1,357 @@
+// Package patch aplica documentos de alteração parcial a documentos JSON:
+// JSON Merge Patch (RFC 7396) e JSON Patch (RFC 6902).
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+)
+
+// Tipos de mídia suportados
+const (
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("tipo de patch não suportado")
+	ErrInvalidPatch         = errors.New("patch inválido")
+	ErrConflict             = errors.New("patch não aplicável ao registro")
+)
+
+// Apply aplica o patch ao documento conforme o tipo de mídia informado
+func Apply(mediaType string, doc, patch []byte) ([]byte, error) {
+	switch mediaType {
+	case MediaTypeMergePatch:
+		return MergePatch(doc, patch)
+	case MediaTypeJSONPatch:
+		return JSONPatch(doc, patch)
+	default:
+		return nil, i18n.Errorf(ErrUnsupportedMediaType, "patch_media_types", mediaType, MediaTypeMergePatch, MediaTypeJSONPatch)
+	}
+}
+
+// MergePatch aplica um JSON Merge Patch (RFC 7396): membros nulos são removidos,
+// objetos são mesclados recursivamente e qualquer outro valor substitui o atual
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, i18n.Errorf(ErrInvalidPatch, "malformed_json", err.Error())
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+// merge implementa o algoritmo MergePatch da RFC 7396
+func merge(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		object[key] = merge(object[key], value)
+	}
+	return object
+}
+
+// operation representa uma operação de JSON Patch; Value fica nulo quando o
+// membro "value" está ausente, distinguindo-o de um null explícito
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch aplica um JSON Patch (RFC 6902). As operações são aplicadas em
+// ordem e, se alguma falhar, nenhuma alteração é retornada.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	var ops []operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, i18n.Errorf(i18n.Errorf(ErrInvalidPatch, "malformed_json", err.Error()), "patch_not_a_list")
+	}
+
+	for i, op := range ops {
+		var err error
+		if target, err = op.apply(target); err != nil {
+			return nil, i18n.Errorf(err, "patch_operation", i, op.Op)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// apply executa a operação sobre o documento e retorna o documento resultante
+func (op operation) apply(doc interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, i18n.Errorf(ErrInvalidPatch, "patch_member_required", "path")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, i18n.Errorf(ErrConflict, "patch_test_failed", *op.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "move", "copy":
+		if op.From == nil {
+			return nil, i18n.Errorf(ErrInvalidPatch, "patch_member_required", "from")
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value, err := get(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return add(doc, path, deepCopy(value))
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, i18n.Errorf(ErrInvalidPatch, "patch_move_into_self", *op.From)
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, i18n.Errorf(ErrInvalidPatch, "patch_unknown_operation", op.Op)
+	}
+}
+
+// value decodifica o membro "value", obrigatório em add, replace e test
+func (op operation) value() (interface{}, error) {
+	if op.Value == nil {
+		return nil, i18n.Errorf(ErrInvalidPatch, "patch_member_required", "value")
+	}
+	var value interface{}
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, i18n.Errorf(ErrInvalidPatch, "malformed_json", err.Error())
+	}
+	return value, nil
+}
+
+// parsePointer converte um JSON Pointer (RFC 6901) em seus tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, i18n.Errorf(ErrInvalidPatch, "patch_invalid_pointer", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// get retorna o valor apontado pelo caminho
+func get(doc interface{}, path []string) (interface{}, error) {
+	node := doc
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			child, ok := container[token]
+			if !ok {
+				return nil, i18n.Errorf(ErrConflict, "patch_path_missing", token)
+			}
+			node = child
+		case []interface{}:
+			i, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[i]
+		default:
+			return nil, i18n.Errorf(ErrConflict, "patch_not_container", token)
+		}
+	}
+	return node, nil
+}
+
+// add insere o valor no caminho, substituindo membros de objeto já existentes
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+			i, err := arrayIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, i18n.Errorf(ErrConflict, "patch_target_not_container", token)
+		}
+	})
+}
+
+// replace substitui um valor que já existe no caminho
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if _, err := get(doc, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		default:
+			list := c.([]interface{})
+			i, _ := arrayIndex(token, len(list)-1)
+			list[i] = value
+			return list, nil
+		}
+	})
+}
+
+// remove retira o valor do caminho e o retorna junto com o documento resultante
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, i18n.Errorf(ErrInvalidPatch, "patch_remove_root")
+	}
+	removed, err := get(doc, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err = modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			delete(c, token)
+			return c, nil
+		default:
+			list := c.([]interface{})
+			i, _ := arrayIndex(token, len(list)-1)
+			return append(list[:i], list[i+1:]...), nil
+		}
+	})
+	return doc, removed, err
+}
+
+// modify percorre o caminho até o contêiner do último token, aplica fn a ele e
+// reconstrói os contêineres intermediários (listas podem ser realocadas)
+func modify(node interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, i18n.Errorf(ErrConflict, "patch_path_missing", token)
+		}
+		updated, err := modify(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = updated
+		return container, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := modify(container[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = updated
+		return container, nil
+	default:
+		return nil, i18n.Errorf(ErrConflict, "patch_not_container", token)
+	}
+}
+
+// arrayIndex converte o token em um índice entre 0 e max
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, i18n.Errorf(ErrInvalidPatch, "patch_invalid_index", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, i18n.Errorf(ErrInvalidPatch, "patch_invalid_index", token)
+	}
+	if i > max {
+		return 0, i18n.Errorf(ErrConflict, "patch_index_out_of_range", i)
+	}
+	return i, nil
+}
+
+// deepCopy duplica um valor decodificado de JSON
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c[key] = deepCopy(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
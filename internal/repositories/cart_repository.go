@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// ErrCartNotFound indica que o usuário não tem carrinho
+var ErrCartNotFound = errors.New("carrinho não encontrado")
+
+// CartRepository é a implementação em memória de CartStore
+type CartRepository struct {
+	mu    sync.RWMutex
+	carts map[int]models.Cart
+}
+
+// NewCartRepository cria uma nova instância do repositório de carrinhos, inicialmente vazio
+func NewCartRepository() *CartRepository {
+	return &CartRepository{carts: make(map[int]models.Cart)}
+}
+
+// Get retorna o carrinho do usuário
+func (r *CartRepository) Get(ctx context.Context, userID int) (*models.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cart, ok := r.carts[userID]
+	if !ok {
+		return nil, ErrCartNotFound
+	}
+	cart = cloneCart(cart)
+	return &cart, nil
+}
+
+// Save cria ou substitui o carrinho do usuário
+func (r *CartRepository) Save(ctx context.Context, cart models.Cart) (*models.Cart, error) {
+	cart, err := storedCart(cart)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.carts[cart.UserID] = cloneCart(cart)
+	return &cart, nil
+}
+
+// Delete remove o carrinho do usuário
+func (r *CartRepository) Delete(ctx context.Context, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.carts[userID]; !ok {
+		return ErrCartNotFound
+	}
+	delete(r.carts, userID)
+	return nil
+}
+
+// PurgeExpired remove os carrinhos que expiraram até before
+func (r *CartRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for userID, cart := range r.carts {
+		if cart.Expired(before) {
+			delete(r.carts, userID)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// storedCart retorna o carrinho apenas com os campos persistidos, com
+// subtotais e total recalculados a partir dos preços registrados, ou
+// models.ErrAmountOverflow se algum valor não couber em int64
+func storedCart(cart models.Cart) (models.Cart, error) {
+	cart.HasIssues = false
+	cart.Total.Amount = 0
+	items := make([]models.CartItem, len(cart.Items))
+	for i, item := range cart.Items {
+		subtotal, err := item.UnitPrice.Times(item.Quantity)
+		if err != nil {
+			return models.Cart{}, err
+		}
+		items[i] = models.CartItem{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			SKU:       item.SKU,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Subtotal:  subtotal,
+			AddedAt:   item.AddedAt,
+		}
+		if cart.Total, err = cart.Total.Plus(subtotal); err != nil {
+			return models.Cart{}, err
+		}
+	}
+	cart.Items = items
+	return cart, nil
+}
+
+// cloneCart copia o carrinho e seus itens, para que alterações externas não
+// alcancem o repositório
+func cloneCart(c models.Cart) models.Cart {
+	c.Items = append([]models.CartItem{}, c.Items...)
+	return c
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("categoria não encontrada")
+	ErrSlugExists       = errors.New("slug já utilizado por outra categoria")
+)
+
+// CategoryRepository é a implementação em memória de CategoryStore
+type CategoryRepository struct {
+	mu         sync.RWMutex
+	categories []models.Category
+	nextID     int
+}
+
+// NewCategoryRepository cria uma nova instância do repositório com as
+// categorias dos produtos pré-prontos de NewProductRepository
+func NewCategoryRepository() *CategoryRepository {
+	electronics := 2
+	return &CategoryRepository{
+		categories: []models.Category{
+			{ID: 1, Name: "Geral", Slug: "geral"},
+			{ID: 2, Name: "Eletrônicos", Slug: "eletronicos"},
+			{ID: 3, Name: "Periféricos", Slug: "perifericos", ParentID: &electronics},
+			{ID: 4, Name: "Monitores", Slug: "monitores", ParentID: &electronics},
+		},
+		nextID: 5,
+	}
+}
+
+// List retorna todas as categorias, ordenadas por ID
+func (r *CategoryRepository) List(ctx context.Context) ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]models.Category, len(r.categories))
+	for i, c := range r.categories {
+		categories[i] = cloneCategory(c)
+	}
+	return categories, nil
+}
+
+// GetByID retorna uma categoria pelo ID
+func (r *CategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return nil, ErrCategoryNotFound
+	}
+	category := cloneCategory(r.categories[i])
+	return &category, nil
+}
+
+// Create cria uma nova categoria com slug único
+func (r *CategoryRepository) Create(ctx context.Context, category models.Category) (*models.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.slugTaken(category.Slug, 0) {
+		return nil, ErrSlugExists
+	}
+	category = storedCategory(category)
+	category.ID = r.nextID
+	r.nextID++
+	r.categories = append(r.categories, cloneCategory(category))
+	return &category, nil
+}
+
+// Update substitui nome, slug e pai de uma categoria
+func (r *CategoryRepository) Update(ctx context.Context, id int, category models.Category) (*models.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return nil, ErrCategoryNotFound
+	}
+	if r.slugTaken(category.Slug, id) {
+		return nil, ErrSlugExists
+	}
+	category = storedCategory(category)
+	category.ID = id
+	r.categories[i] = cloneCategory(category)
+	return &category, nil
+}
+
+// Delete remove uma categoria
+func (r *CategoryRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return ErrCategoryNotFound
+	}
+	r.categories = append(r.categories[:i], r.categories[i+1:]...)
+	return nil
+}
+
+// find retorna o índice da categoria ou -1. Deve ser chamado com o lock adquirido.
+func (r *CategoryRepository) find(id int) int {
+	for i := range r.categories {
+		if r.categories[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// slugTaken indica se o slug pertence a uma categoria diferente de exceptID.
+// Deve ser chamado com o lock adquirido.
+func (r *CategoryRepository) slugTaken(slug string, exceptID int) bool {
+	for _, c := range r.categories {
+		if c.Slug == slug && c.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// storedCategory retorna a categoria apenas com os campos persistidos
+func storedCategory(c models.Category) models.Category {
+	return models.Category{ID: c.ID, Name: c.Name, Slug: c.Slug, ParentID: c.ParentID}
+}
+
+// cloneCategory copia a categoria, para que alterações no pai retornado não
+// alcancem o repositório
+func cloneCategory(c models.Category) models.Category {
+	if c.ParentID != nil {
+		parentID := *c.ParentID
+		c.ParentID = &parentID
+	}
+	return c
+}
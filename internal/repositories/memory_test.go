@@ -0,0 +1,44 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories/storetest"
+)
+
+func TestMemoryUserStore(t *testing.T) {
+	storetest.TestUserStore(t, func(t *testing.T) repositories.UserStore {
+		return repositories.NewUserRepository()
+	})
+}
+
+func TestMemoryProductStore(t *testing.T) {
+	storetest.TestProductStore(t, func(t *testing.T) repositories.ProductStore {
+		return repositories.NewProductRepository()
+	})
+}
+
+func TestMemoryCategoryStore(t *testing.T) {
+	storetest.TestCategoryStore(t, func(t *testing.T) repositories.CategoryStore {
+		return repositories.NewCategoryRepository()
+	})
+}
+
+func TestMemoryOrderStore(t *testing.T) {
+	storetest.TestOrderStore(t, func(t *testing.T) repositories.OrderStore {
+		return repositories.NewOrderRepository()
+	})
+}
+
+func TestMemoryCartStore(t *testing.T) {
+	storetest.TestCartStore(t, func(t *testing.T) repositories.CartStore {
+		return repositories.NewCartRepository()
+	})
+}
+
+func TestMemoryPromotionStore(t *testing.T) {
+	storetest.TestPromotionStore(t, func(t *testing.T) repositories.PromotionStore {
+		return repositories.NewPromotionRepository()
+	})
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// ErrOrderNotFound indica que o pedido não existe
+var ErrOrderNotFound = errors.New("pedido não encontrado")
+
+// OrderRepository é a implementação em memória de OrderStore
+type OrderRepository struct {
+	mu     sync.RWMutex
+	orders []models.Order
+	nextID int
+}
+
+// NewOrderRepository cria uma nova instância do repositório de pedidos, inicialmente vazio
+func NewOrderRepository() *OrderRepository {
+	return &OrderRepository{nextID: 1}
+}
+
+// List retorna uma janela de pedidos que atendem ao filtro, na ordenação solicitada
+func (r *OrderRepository) List(ctx context.Context, query OrderQuery) ([]models.Order, int, error) {
+	r.mu.RLock()
+	filtered := make([]models.Order, 0, len(r.orders))
+	for i := range r.orders {
+		if query.Filter.Matches(r.orders[i]) {
+			filtered = append(filtered, cloneOrder(r.orders[i]))
+		}
+	}
+	r.mu.RUnlock()
+
+	keys := func(o models.Order) []interface{} { return OrderSortValues(o, query.Sort) }
+	sortByKeys(filtered, query.Sort, keys, orderID)
+	orders := applyWindow(filtered, query.Window, query.Sort, keys, orderID)
+	return orders, len(filtered), nil
+}
+
+// GetByID retorna um pedido pelo ID
+func (r *OrderRepository) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return nil, ErrOrderNotFound
+	}
+	order := cloneOrder(r.orders[i])
+	return &order, nil
+}
+
+// Create cria um novo pedido
+func (r *OrderRepository) Create(ctx context.Context, order models.Order) (*models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order = withOrderTotals(cloneOrder(order))
+	order.ID = r.nextID
+	order.Version = 1
+	r.nextID++
+	r.orders = append(r.orders, cloneOrder(order))
+	return &order, nil
+}
+
+// UpdateStatus altera o status de um pedido
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id int, version int, status string, updatedAt time.Time) (*models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return nil, ErrOrderNotFound
+	}
+	if r.orders[i].Version != version {
+		return nil, ErrVersionConflict
+	}
+	r.orders[i].Status = status
+	r.orders[i].UpdatedAt = updatedAt
+	r.orders[i].Version++
+	order := cloneOrder(r.orders[i])
+	return &order, nil
+}
+
+// find retorna o índice do pedido ou -1. Deve ser chamado com o lock adquirido.
+func (r *OrderRepository) find(id int) int {
+	for i := range r.orders {
+		if r.orders[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// cloneOrder copia o pedido e seus itens, para que alterações externas não
+// alcancem o repositório
+func cloneOrder(o models.Order) models.Order {
+	o.Items = append([]models.OrderItem(nil), o.Items...)
+	o.Discounts = append([]models.OrderDiscount{}, o.Discounts...)
+	return o
+}
+
+// withOrderTotals preenche o subtotal e o desconto do pedido a partir dos
+// itens e dos descontos, na moeda do total
+func withOrderTotals(o models.Order) models.Order {
+	o.Subtotal = models.Money{Currency: o.Total.Currency}
+	for _, item := range o.Items {
+		o.Subtotal.Amount += item.Subtotal.Amount
+	}
+	o.Discount = models.Money{Currency: o.Total.Currency}
+	for _, discount := range o.Discounts {
+		o.Discount.Amount += discount.Amount.Amount
+	}
+	return o
+}
+
+// orderID retorna o ID do pedido, usado como desempate nas ordenações
+func orderID(o models.Order) int {
+	return o.ID
+}
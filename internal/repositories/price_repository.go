@@ -0,0 +1,302 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("agendamento de preço não encontrado")
+	// ErrScheduleOverlap indica um agendamento cujo período se sobrepõe ao de
+	// outro agendamento em aberto do mesmo produto
+	ErrScheduleOverlap = errors.New("o período se sobrepõe a outro agendamento")
+	// ErrScheduleClosed indica um agendamento já concluído ou cancelado
+	ErrScheduleClosed = errors.New("o agendamento já foi concluído ou cancelado")
+)
+
+// ListPriceChanges retorna uma janela do histórico de preços do produto
+func (r *ProductRepository) ListPriceChanges(ctx context.Context, productID int, window Window) ([]models.PriceChange, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.find(productID, false) < 0 {
+		return nil, 0, ErrProductNotFound
+	}
+	var changes []models.PriceChange
+	for _, change := range r.priceChanges {
+		if change.ProductID == productID {
+			changes = append(changes, clonePriceChange(change))
+		}
+	}
+
+	page := applyWindow(changes, window, nil, func(models.PriceChange) []interface{} { return nil }, priceChangeID)
+	return page, len(changes), nil
+}
+
+// ListPriceSchedules retorna os agendamentos do produto, ordenados por início
+func (r *ProductRepository) ListPriceSchedules(ctx context.Context, productID int) ([]models.PriceSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.find(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	schedules := []models.PriceSchedule{}
+	for _, s := range r.schedules {
+		if s.ProductID == productID {
+			schedules = append(schedules, cloneSchedule(s))
+		}
+	}
+	sortSchedules(schedules)
+	return schedules, nil
+}
+
+// CreatePriceSchedule grava um agendamento que não se sobrepõe aos demais em aberto
+func (r *ProductRepository) CreatePriceSchedule(ctx context.Context, schedule models.PriceSchedule) (*models.PriceSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.find(schedule.ProductID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	for _, s := range r.schedules {
+		if s.ProductID == schedule.ProductID && s.Open() && schedulesOverlap(s, schedule) {
+			return nil, ErrScheduleOverlap
+		}
+	}
+
+	schedule.ID = r.nextScheduleID
+	r.nextScheduleID++
+	schedule.Status = models.ScheduleScheduled
+	schedule.PreviousPrice = nil
+	r.schedules = append(r.schedules, cloneSchedule(schedule))
+	schedule = cloneSchedule(schedule)
+	return &schedule, nil
+}
+
+// CancelPriceSchedule cancela um agendamento em aberto; se ele estiver ativo,
+// o preço anterior é restaurado
+func (r *ProductRepository) CancelPriceSchedule(ctx context.Context, productID, scheduleID int, by string, at time.Time) (*models.PriceSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(productID, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	j := r.findSchedule(productID, scheduleID)
+	if j < 0 {
+		return nil, ErrScheduleNotFound
+	}
+	if !r.schedules[j].Open() {
+		return nil, ErrScheduleClosed
+	}
+	if r.schedules[j].Status == models.ScheduleActive {
+		r.restorePrice(i, r.schedules[j], "cancelamento do agendamento", by, at)
+	}
+	r.schedules[j].Status = models.ScheduleCancelled
+	schedule := cloneSchedule(r.schedules[j])
+	return &schedule, nil
+}
+
+// ApplyPriceSchedules aplica, em ordem cronológica, os inícios e términos de
+// agendamento devidos até now
+func (r *ProductRepository) ApplyPriceSchedules(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var open []models.PriceSchedule
+	for _, s := range r.schedules {
+		if s.Open() && r.find(s.ProductID, false) >= 0 {
+			open = append(open, s)
+		}
+	}
+
+	transitions := dueTransitions(open, now)
+	for _, t := range transitions {
+		i := r.find(t.schedule.ProductID, false)
+		j := r.findSchedule(t.schedule.ProductID, t.schedule.ID)
+		schedule := &r.schedules[j]
+		switch {
+		case !t.start:
+			r.restorePrice(i, *schedule, "fim do agendamento", actor.System, t.at)
+			schedule.Status = models.ScheduleCompleted
+			schedule.PreviousPrice = nil
+		case schedule.EndsAt != nil && !schedule.EndsAt.After(now):
+			schedule.Status = models.ScheduleCompleted
+		default:
+			previous := r.products[i].Price
+			r.setPrice(i, schedule.Price, schedule.ID, "início do agendamento", actor.System, t.at)
+			schedule.Status = models.ScheduleCompleted
+			if schedule.EndsAt != nil {
+				schedule.Status = models.ScheduleActive
+				schedule.PreviousPrice = &previous
+			}
+		}
+	}
+	return len(transitions), nil
+}
+
+// NextPriceScheduleAt retorna o próximo início ou término de agendamento
+func (r *ProductRepository) NextPriceScheduleAt(ctx context.Context) (*time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var next *time.Time
+	for _, s := range r.schedules {
+		if !s.Open() || r.find(s.ProductID, false) < 0 {
+			continue
+		}
+		at := transitionAt(s)
+		if next == nil || at.Before(*next) {
+			next = &at
+		}
+	}
+	return next, nil
+}
+
+// setPrice altera o preço do produto na posição i, incrementa a versão e
+// registra a alteração no histórico. Deve ser chamado com o lock de escrita
+// adquirido.
+func (r *ProductRepository) setPrice(i int, price models.Money, scheduleID int, reason, by string, at time.Time) {
+	previous := r.products[i].Price
+	r.products[i].Price = price
+	r.products[i].Version++
+	r.recordPrice(r.products[i].ID, price, &previous, scheduleID, reason, by, at)
+}
+
+// restorePrice devolve ao produto o preço anterior a um agendamento ativo,
+// desde que o preço não tenha sido alterado depois do início. Deve ser
+// chamado com o lock de escrita adquirido.
+func (r *ProductRepository) restorePrice(i int, schedule models.PriceSchedule, reason, by string, at time.Time) {
+	if schedule.PreviousPrice == nil || r.products[i].Price != schedule.Price {
+		return
+	}
+	r.setPrice(i, *schedule.PreviousPrice, schedule.ID, reason, by, at)
+}
+
+// recordPrice acrescenta uma alteração ao histórico de preços; alterações
+// que mantêm o preço são ignoradas. Deve ser chamado com o lock de escrita
+// adquirido.
+func (r *ProductRepository) recordPrice(productID int, price models.Money, previous *models.Money, scheduleID int, reason, by string, at time.Time) {
+	if previous != nil && *previous == price {
+		return
+	}
+	r.priceChanges = append(r.priceChanges, clonePriceChange(models.PriceChange{
+		ID:         r.nextPriceChangeID,
+		ProductID:  productID,
+		Price:      price,
+		Previous:   previous,
+		Reason:     reason,
+		Actor:      by,
+		ScheduleID: scheduleID,
+		CreatedAt:  at,
+	}))
+	r.nextPriceChangeID++
+}
+
+// findSchedule retorna a posição do agendamento do produto, ou -1. Deve ser
+// chamado com o lock adquirido.
+func (r *ProductRepository) findSchedule(productID, scheduleID int) int {
+	for j := range r.schedules {
+		if r.schedules[j].ID == scheduleID && r.schedules[j].ProductID == productID {
+			return j
+		}
+	}
+	return -1
+}
+
+// priceTransition é um início (start) ou término de agendamento devido
+type priceTransition struct {
+	at       time.Time
+	start    bool
+	schedule models.PriceSchedule
+}
+
+// dueTransitions retorna os inícios e términos dos agendamentos em aberto
+// devidos até now, em ordem cronológica. Um agendamento cujo início está
+// devido gera apenas o início, mesmo que o término também esteja: nesse caso
+// o período já passou e o preço não é alterado.
+func dueTransitions(open []models.PriceSchedule, now time.Time) []priceTransition {
+	var transitions []priceTransition
+	for _, s := range open {
+		at := transitionAt(s)
+		if !at.After(now) {
+			transitions = append(transitions, priceTransition{at: at, start: s.Status == models.ScheduleScheduled, schedule: s})
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if !transitions[i].at.Equal(transitions[j].at) {
+			return transitions[i].at.Before(transitions[j].at)
+		}
+		return transitions[i].schedule.ID < transitions[j].schedule.ID
+	})
+	return transitions
+}
+
+// transitionAt retorna o instante da próxima mudança de um agendamento em
+// aberto: o início, se ainda agendado, ou o término, se ativo
+func transitionAt(s models.PriceSchedule) time.Time {
+	if s.Status == models.ScheduleActive && s.EndsAt != nil {
+		return *s.EndsAt
+	}
+	return s.StartsAt
+}
+
+// schedulesOverlap indica se os períodos de dois agendamentos se sobrepõem.
+// Um agendamento sem término ocupa apenas o instante de início, e nenhum
+// início pode cair dentro do período de outro agendamento.
+func schedulesOverlap(a, b models.PriceSchedule) bool {
+	return within(a.StartsAt, b) || within(b.StartsAt, a)
+}
+
+// within indica se at está no período [início, término) do agendamento, ou é
+// o seu início quando ele não tem término
+func within(at time.Time, s models.PriceSchedule) bool {
+	if s.EndsAt == nil {
+		return at.Equal(s.StartsAt)
+	}
+	return !at.Before(s.StartsAt) && at.Before(*s.EndsAt)
+}
+
+// sortSchedules ordena os agendamentos por início, desempatando por ID
+func sortSchedules(schedules []models.PriceSchedule) {
+	sort.SliceStable(schedules, func(i, j int) bool {
+		if !schedules[i].StartsAt.Equal(schedules[j].StartsAt) {
+			return schedules[i].StartsAt.Before(schedules[j].StartsAt)
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+}
+
+// priceChangeID retorna o ID da alteração de preço, usado como chave da paginação
+func priceChangeID(c models.PriceChange) int {
+	return c.ID
+}
+
+// clonePriceChange copia a alteração sem compartilhar o preço anterior
+func clonePriceChange(c models.PriceChange) models.PriceChange {
+	if c.Previous != nil {
+		previous := *c.Previous
+		c.Previous = &previous
+	}
+	return c
+}
+
+// cloneSchedule copia o agendamento sem compartilhar o término nem o preço anterior
+func cloneSchedule(s models.PriceSchedule) models.PriceSchedule {
+	if s.EndsAt != nil {
+		endsAt := *s.EndsAt
+		s.EndsAt = &endsAt
+	}
+	if s.PreviousPrice != nil {
+		previous := *s.PreviousPrice
+		s.PreviousPrice = &previous
+	}
+	return s
+}
@@ -1,24 +1,48 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"sync"
+	"time"
 
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
 	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/search"
 )
 
 var (
-	ErrProductNotFound = errors.New("produto não encontrado")
+	ErrProductNotFound     = errors.New("produto não encontrado")
+	ErrInsufficientStock   = errors.New("estoque insuficiente")
+	ErrReservationNotFound = errors.New("reserva não encontrada")
+	ErrStockOverflow       = errors.New("estoque acima do limite")
 )
 
-// ProductRepository gerencia os dados de produtos em memória
+// ProductRepository é a implementação em memória de ProductStore
 type ProductRepository struct {
 	mu       sync.RWMutex
 	products []models.Product
 	nextID   int
+	index    *search.Index
+	// reservations guarda as reservas de estoque por ID
+	reservations      map[int]models.StockReservation
+	nextReservationID int
+	// movements é o histórico de estoque, em ordem de inclusão
+	movements      []models.StockMovement
+	nextMovementID int
+	// variants guarda as variantes de todos os produtos, em ordem de ID
+	variants      []models.Variant
+	nextVariantID int
+	// priceChanges é o histórico de preços, em ordem de inclusão
+	priceChanges      []models.PriceChange
+	nextPriceChangeID int
+	// schedules guarda os agendamentos de preço de todos os produtos
+	schedules      []models.PriceSchedule
+	nextScheduleID int
 }
 
-// NewProductRepository cria uma nova instância do repositório com dados pré-prontos
+// NewProductRepository cria uma nova instância do repositório com dados
+// pré-prontos, cujas categorias são as de NewCategoryRepository
 func NewProductRepository() *ProductRepository {
 	repo := &ProductRepository{
 		products: []models.Product{
@@ -26,123 +50,330 @@ func NewProductRepository() *ProductRepository {
 				ID:          1,
 				Name:        "Notebook Dell XPS 15",
 				Description: "Notebook de alta performance com processador Intel i7",
-				Price:       8999.99,
+				Price:       models.Money{Amount: 899999, Currency: "BRL"},
 				Stock:       15,
+				CategoryID:  2,
 				Category:    "Eletrônicos",
 				Active:      true,
+				Version:     1,
 			},
 			{
 				ID:          2,
 				Name:        "Mouse Logitech MX Master 3",
 				Description: "Mouse sem fio ergonômico para produtividade",
-				Price:       599.90,
+				Price:       models.Money{Amount: 59990, Currency: "BRL"},
 				Stock:       50,
+				CategoryID:  3,
 				Category:    "Periféricos",
 				Active:      true,
+				Version:     1,
 			},
 			{
 				ID:          3,
 				Name:        "Teclado Mecânico Keychron K8",
 				Description: "Teclado mecânico sem fio com switches Gateron",
-				Price:       799.00,
+				Price:       models.Money{Amount: 79900, Currency: "BRL"},
 				Stock:       30,
+				CategoryID:  3,
 				Category:    "Periféricos",
 				Active:      true,
+				Version:     1,
 			},
 			{
 				ID:          4,
 				Name:        "Monitor LG UltraWide 34",
 				Description: "Monitor ultrawide 34 polegadas 4K",
-				Price:       3499.99,
+				Price:       models.Money{Amount: 349999, Currency: "BRL"},
 				Stock:       8,
+				CategoryID:  4,
 				Category:    "Monitores",
 				Active:      true,
+				Version:     1,
 			},
 			{
 				ID:          5,
 				Name:        "Webcam Logitech C920",
 				Description: "Webcam Full HD para videoconferências",
-				Price:       499.90,
+				Price:       models.Money{Amount: 49990, Currency: "BRL"},
 				Stock:       0,
+				CategoryID:  3,
 				Category:    "Periféricos",
 				Active:      false,
+				Version:     1,
 			},
 		},
-		nextID: 6,
+		nextID:            6,
+		index:             search.NewIndex(),
+		reservations:      make(map[int]models.StockReservation),
+		nextReservationID: 1,
+		nextMovementID:    1,
+		nextVariantID:     1,
+		nextPriceChangeID: 1,
+		nextScheduleID:    1,
+	}
+	opening := StockChange{Type: models.MovementAdjustment, Reason: "saldo inicial", Actor: actor.System, At: time.Now().UTC().Truncate(time.Second)}
+	for _, p := range repo.products {
+		repo.index.Put(p.ID, productDocument(p))
+		repo.record(p.ID, 0, p.Stock, p.Stock, opening)
+		repo.recordPrice(p.ID, p.Price, nil, 0, "preço inicial", opening.Actor, opening.At)
 	}
 	return repo
 }
 
-// GetAll retorna todos os produtos
-func (r *ProductRepository) GetAll() []models.Product {
+// GetAll retorna todos os produtos fora da lixeira
+func (r *ProductRepository) GetAll(ctx context.Context) ([]models.Product, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.products
+
+	products := make([]models.Product, 0, len(r.products))
+	for _, p := range r.products {
+		if p.DeletedAt == nil {
+			products = append(products, cloneProduct(p))
+		}
+	}
+	return products, nil
 }
 
 // GetByID retorna um produto pelo ID
-func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
+func (r *ProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	product := cloneProduct(r.products[i])
+	return &product, nil
+}
+
+// List retorna uma janela de produtos que atendem ao filtro, na ordenação solicitada
+func (r *ProductRepository) List(ctx context.Context, query ProductQuery) ([]models.Product, int, error) {
+	r.mu.RLock()
+	filtered := make([]models.Product, 0, len(r.products))
 	for i := range r.products {
-		if r.products[i].ID == id {
-			return &r.products[i], nil
+		if query.Filter.Matches(r.products[i]) {
+			filtered = append(filtered, cloneProduct(r.products[i]))
 		}
 	}
-	return nil, ErrProductNotFound
+	r.mu.RUnlock()
+
+	keys := func(p models.Product) []interface{} { return ProductSortValues(p, query.Sort) }
+	sortByKeys(filtered, query.Sort, keys, productID)
+	products := applyWindow(filtered, query.Window, query.Sort, keys, productID)
+	return products, len(filtered), nil
 }
 
-// GetByCategory retorna produtos por categoria
-func (r *ProductRepository) GetByCategory(category string) []models.Product {
+// Search retorna os produtos que casam com o texto, ordenados por relevância
+func (r *ProductRepository) Search(ctx context.Context, text string, filter ProductFilter, window Window) ([]models.ProductSearchResult, int, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var filtered []models.Product
-	for i := range r.products {
-		if r.products[i].Category == category {
-			filtered = append(filtered, r.products[i])
-		}
+	byID := make(map[int]models.Product, len(r.products))
+	for _, p := range r.products {
+		byID[p.ID] = cloneProduct(p)
 	}
-	return filtered
+
+	results, total := searchResults(r.index.Search(text), filter, window, func(id int) (models.Product, bool) {
+		p, ok := byID[id]
+		return p, ok
+	})
+	return results, total, nil
 }
 
-// Create cria um novo produto
-func (r *ProductRepository) Create(product models.Product) models.Product {
+// Create cria um novo produto, registrando o estoque inicial e o preço nos históricos
+func (r *ProductRepository) Create(ctx context.Context, product models.Product, change StockChange) (*models.Product, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	product.ID = r.nextID
+	product.Version = 1
+	product.DeletedAt = nil
 	r.nextID++
-	r.products = append(r.products, product)
-	return product
+	r.products = append(r.products, cloneProduct(product))
+	r.index.Put(product.ID, productDocument(product))
+	r.record(product.ID, 0, product.Stock, product.Stock, change)
+	r.recordPrice(product.ID, product.Price, nil, 0, change.Reason, change.Actor, change.At)
+	product = cloneProduct(product)
+	return &product, nil
 }
 
-// Update atualiza um produto existente
-func (r *ProductRepository) Update(id int, product models.Product) (*models.Product, error) {
+// Update atualiza um produto existente, registrando as variações de estoque e
+// de preço nos históricos
+func (r *ProductRepository) Update(ctx context.Context, id int, product models.Product, change StockChange) (*models.Product, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for i := range r.products {
-		if r.products[i].ID == id {
-			product.ID = id
-			r.products[i] = product
-			return &r.products[i], nil
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	if r.products[i].Version != product.Version {
+		return nil, ErrVersionConflict
+	}
+	if product.Stock < r.products[i].Stock && product.Stock < r.reserved(id, change.At) {
+		return nil, ErrInsufficientStock
+	}
+	product.ID = id
+	product.Version++
+	product.DeletedAt = nil
+	r.record(id, 0, product.Stock-r.products[i].Stock, product.Stock, change)
+	previous := r.products[i].Price
+	r.recordPrice(id, product.Price, &previous, 0, change.Reason, change.Actor, change.At)
+	r.products[i] = cloneProduct(product)
+	r.index.Put(id, productDocument(product))
+	product = cloneProduct(product)
+	return &product, nil
+}
+
+// SetActive altera o estado ativo de um produto
+func (r *ProductRepository) SetActive(ctx context.Context, id int, active bool) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	r.products[i].Active = active
+	r.products[i].Version++
+	product := cloneProduct(r.products[i])
+	return &product, nil
+}
+
+// Delete move um produto para a lixeira, retirando-o do índice de busca
+func (r *ProductRepository) Delete(ctx context.Context, id int, version int, deletedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id, false)
+	if i < 0 {
+		return ErrProductNotFound
+	}
+	if r.products[i].Version != version {
+		return ErrVersionConflict
+	}
+	r.products[i].DeletedAt = &deletedAt
+	r.index.Remove(id)
+	return nil
+}
+
+// Restore retira um produto da lixeira e o devolve ao índice de busca
+func (r *ProductRepository) Restore(ctx context.Context, id int) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id, true)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	r.products[i].DeletedAt = nil
+	product := cloneProduct(r.products[i])
+	r.index.Put(id, productDocument(product))
+	return &product, nil
+}
+
+// Purge remove definitivamente os produtos excluídos antes de before
+func (r *ProductRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.products[:0]
+	// surviving guarda os IDs dos produtos mantidos, para filtrar os
+	// registros dependentes sem percorrer os produtos a cada um
+	surviving := make(map[int]struct{}, len(r.products))
+	for _, p := range r.products {
+		if p.DeletedAt == nil || !p.DeletedAt.Before(before) {
+			kept = append(kept, p)
+			surviving[p.ID] = struct{}{}
 		}
 	}
-	return nil, ErrProductNotFound
+	purged := len(r.products) - len(kept)
+	r.products = kept
+	survives := func(productID int) bool {
+		_, ok := surviving[productID]
+		return ok
+	}
+
+	variants := r.variants[:0]
+	for _, v := range r.variants {
+		if survives(v.ProductID) {
+			variants = append(variants, v)
+		}
+	}
+	r.variants = variants
+	schedules := r.schedules[:0]
+	for _, s := range r.schedules {
+		if survives(s.ProductID) {
+			schedules = append(schedules, s)
+		}
+	}
+	r.schedules = schedules
+	priceChanges := r.priceChanges[:0]
+	for _, change := range r.priceChanges {
+		if survives(change.ProductID) {
+			priceChanges = append(priceChanges, change)
+		}
+	}
+	r.priceChanges = priceChanges
+	for id, reservation := range r.reservations {
+		if !survives(reservation.ProductID) {
+			delete(r.reservations, id)
+		}
+	}
+	movements := r.movements[:0]
+	for _, movement := range r.movements {
+		if survives(movement.ProductID) {
+			movements = append(movements, movement)
+		}
+	}
+	r.movements = movements
+	return purged, nil
+}
+
+// CountByCategory retorna a quantidade de produtos por ID de categoria
+func (r *ProductRepository) CountByCategory(ctx context.Context, includeDeleted bool) (map[int]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, p := range r.products {
+		if p.DeletedAt == nil || includeDeleted {
+			counts[p.CategoryID]++
+		}
+	}
+	return counts, nil
 }
 
-// Delete remove um produto
-func (r *ProductRepository) Delete(id int) error {
+// RenameCategory atualiza o nome da categoria nos produtos e no índice de busca
+func (r *ProductRepository) RenameCategory(ctx context.Context, categoryID int, name string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	for i := range r.products {
-		if r.products[i].ID == id {
-			r.products = append(r.products[:i], r.products[i+1:]...)
-			return nil
+		if r.products[i].CategoryID != categoryID {
+			continue
+		}
+		r.products[i].Category = name
+		if r.products[i].DeletedAt == nil {
+			r.index.Put(r.products[i].ID, productDocument(r.products[i]))
+		}
+	}
+	return nil
+}
+
+// find retorna a posição do produto com o ID, dentro ou fora da lixeira
+// conforme deleted, ou -1 se não existir. Deve ser chamado com o lock adquirido.
+func (r *ProductRepository) find(id int, deleted bool) int {
+	for i := range r.products {
+		if r.products[i].ID == id && (r.products[i].DeletedAt != nil) == deleted {
+			return i
 		}
 	}
-	return ErrProductNotFound
+	return -1
+}
+
+// productID retorna o ID do produto, usado como desempate nas ordenações
+func productID(p models.Product) int {
+	return p.ID
 }
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var (
+	ErrPromotionNotFound  = errors.New("promoção não encontrada")
+	ErrRedemptionNotFound = errors.New("resgate de promoção não encontrado")
+	ErrCouponCodeExists   = errors.New("código de cupom já utilizado por outra promoção")
+	// ErrPromotionExhausted indica que a promoção atingiu o limite de resgates
+	ErrPromotionExhausted = errors.New("a promoção atingiu o limite de usos")
+	// ErrPromotionUserLimit indica que o usuário atingiu o limite de resgates da promoção
+	ErrPromotionUserLimit = errors.New("o usuário atingiu o limite de usos da promoção")
+)
+
+// PromotionRepository é a implementação em memória de PromotionStore
+type PromotionRepository struct {
+	mu               sync.RWMutex
+	promotions       []models.Promotion
+	nextID           int
+	redemptions      []models.Redemption
+	nextRedemptionID int
+}
+
+// NewPromotionRepository cria uma nova instância do repositório de promoções, inicialmente vazio
+func NewPromotionRepository() *PromotionRepository {
+	return &PromotionRepository{nextID: 1, nextRedemptionID: 1}
+}
+
+// List retorna todas as promoções, ordenadas por ID
+func (r *PromotionRepository) List(ctx context.Context) ([]models.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	promotions := make([]models.Promotion, len(r.promotions))
+	for i, p := range r.promotions {
+		promotions[i] = r.withUses(p)
+	}
+	return promotions, nil
+}
+
+// GetByID retorna uma promoção pelo ID
+func (r *PromotionRepository) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return nil, ErrPromotionNotFound
+	}
+	promotion := r.withUses(r.promotions[i])
+	return &promotion, nil
+}
+
+// GetByCode retorna a promoção do cupom, sem diferenciar maiúsculas
+func (r *PromotionRepository) GetByCode(ctx context.Context, code string) (*models.Promotion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.promotions {
+		if p.Code != "" && strings.EqualFold(p.Code, code) {
+			promotion := r.withUses(p)
+			return &promotion, nil
+		}
+	}
+	return nil, ErrPromotionNotFound
+}
+
+// Create cria uma nova promoção com código de cupom único
+func (r *PromotionRepository) Create(ctx context.Context, promotion models.Promotion) (*models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.codeTaken(promotion.Code, 0) {
+		return nil, ErrCouponCodeExists
+	}
+	promotion = storedPromotion(promotion)
+	promotion.ID = r.nextID
+	r.nextID++
+	r.promotions = append(r.promotions, clonePromotion(promotion))
+	return &promotion, nil
+}
+
+// Update substitui os dados de uma promoção, preservando a data de criação
+func (r *PromotionRepository) Update(ctx context.Context, id int, promotion models.Promotion) (*models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return nil, ErrPromotionNotFound
+	}
+	if r.codeTaken(promotion.Code, id) {
+		return nil, ErrCouponCodeExists
+	}
+	promotion = storedPromotion(promotion)
+	promotion.ID = id
+	promotion.CreatedAt = r.promotions[i].CreatedAt
+	r.promotions[i] = clonePromotion(promotion)
+	promotion = r.withUses(promotion)
+	return &promotion, nil
+}
+
+// Delete remove uma promoção e seus resgates
+func (r *PromotionRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id)
+	if i < 0 {
+		return ErrPromotionNotFound
+	}
+	r.promotions = append(r.promotions[:i], r.promotions[i+1:]...)
+	kept := r.redemptions[:0]
+	for _, redemption := range r.redemptions {
+		if redemption.PromotionID != id {
+			kept = append(kept, redemption)
+		}
+	}
+	r.redemptions = kept
+	return nil
+}
+
+// UserRedemptions retorna a quantidade de resgates vigentes do usuário por promoção
+func (r *PromotionRepository) UserRedemptions(ctx context.Context, userID int) (map[int]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, redemption := range r.redemptions {
+		if redemption.UserID == userID {
+			counts[redemption.PromotionID]++
+		}
+	}
+	return counts, nil
+}
+
+// Redeem registra os resgates de uma só vez, desde que nenhum limite seja excedido
+func (r *PromotionRepository) Redeem(ctx context.Context, redemptions []models.Redemption) ([]models.Redemption, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, redemption := range redemptions {
+		i := r.find(redemption.PromotionID)
+		if i < 0 {
+			return nil, ErrPromotionNotFound
+		}
+		total, byUser := r.countRedemptions(redemption.PromotionID, redemption.UserID)
+		if err := checkRedemptionLimits(r.promotions[i], total, byUser, redemptions, redemption); err != nil {
+			return nil, err
+		}
+	}
+
+	redeemed := make([]models.Redemption, len(redemptions))
+	for i, redemption := range redemptions {
+		redemption.ID = r.nextRedemptionID
+		r.nextRedemptionID++
+		r.redemptions = append(r.redemptions, redemption)
+		redeemed[i] = redemption
+	}
+	return redeemed, nil
+}
+
+// ReleaseRedemption remove um resgate, liberando-o para os limites da promoção
+func (r *PromotionRepository) ReleaseRedemption(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.redemptions {
+		if r.redemptions[i].ID == id {
+			r.redemptions = append(r.redemptions[:i], r.redemptions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrRedemptionNotFound
+}
+
+// find retorna o índice da promoção ou -1. Deve ser chamado com o lock adquirido.
+func (r *PromotionRepository) find(id int) int {
+	for i := range r.promotions {
+		if r.promotions[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// codeTaken indica se o código pertence a uma promoção diferente de exceptID;
+// promoções automáticas, sem código, nunca conflitam. Deve ser chamado com o
+// lock adquirido.
+func (r *PromotionRepository) codeTaken(code string, exceptID int) bool {
+	if code == "" {
+		return false
+	}
+	for _, p := range r.promotions {
+		if strings.EqualFold(p.Code, code) && p.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// countRedemptions retorna os resgates vigentes da promoção, no total e do
+// usuário. Deve ser chamado com o lock adquirido.
+func (r *PromotionRepository) countRedemptions(promotionID, userID int) (int, int) {
+	total, byUser := 0, 0
+	for _, redemption := range r.redemptions {
+		if redemption.PromotionID == promotionID {
+			total++
+			if redemption.UserID == userID {
+				byUser++
+			}
+		}
+	}
+	return total, byUser
+}
+
+// withUses retorna uma cópia da promoção com a quantidade de resgates
+// vigentes. Deve ser chamado com o lock adquirido.
+func (r *PromotionRepository) withUses(p models.Promotion) models.Promotion {
+	p = clonePromotion(p)
+	p.Uses, _ = r.countRedemptions(p.ID, 0)
+	return p
+}
+
+// checkRedemptionLimits verifica se o resgate cabe nos limites da promoção,
+// considerando os resgates vigentes (total e do usuário) e os demais resgates
+// da mesma promoção no lote
+func checkRedemptionLimits(p models.Promotion, total, byUser int, batch []models.Redemption, redemption models.Redemption) error {
+	for _, other := range batch {
+		if other.PromotionID == p.ID {
+			total++
+			if other.UserID == redemption.UserID {
+				byUser++
+			}
+		}
+	}
+	if p.MaxUses > 0 && total > p.MaxUses {
+		return ErrPromotionExhausted
+	}
+	if p.MaxUsesPerUser > 0 && byUser > p.MaxUsesPerUser {
+		return ErrPromotionUserLimit
+	}
+	return nil
+}
+
+// storedPromotion retorna a promoção apenas com os campos persistidos, com
+// listas de escopo não nulas
+func storedPromotion(p models.Promotion) models.Promotion {
+	p.Uses = 0
+	if p.CategoryIDs == nil {
+		p.CategoryIDs = []int{}
+	}
+	if p.ProductIDs == nil {
+		p.ProductIDs = []int{}
+	}
+	return p
+}
+
+// clonePromotion copia a promoção sem compartilhar listas, valor e período
+func clonePromotion(p models.Promotion) models.Promotion {
+	p.CategoryIDs = append([]int{}, p.CategoryIDs...)
+	p.ProductIDs = append([]int{}, p.ProductIDs...)
+	if p.Amount != nil {
+		amount := *p.Amount
+		p.Amount = &amount
+	}
+	if p.StartsAt != nil {
+		startsAt := *p.StartsAt
+		p.StartsAt = &startsAt
+	}
+	if p.EndsAt != nil {
+		endsAt := *p.EndsAt
+		p.EndsAt = &endsAt
+	}
+	return p
+}
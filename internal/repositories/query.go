@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// SortField representa um critério de ordenação. O ID é sempre usado como
+// desempate final, em ordem crescente, para que a ordenação seja total.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SortKey retorna a representação canônica da ordenação ("-price,name"),
+// usada para vincular cursores à ordenação que os emitiu
+func SortKey(fields []SortField) string {
+	if len(fields) == 0 {
+		return "id"
+	}
+
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// timestampLayout é o formato dos instantes persistidos e usados em cursores;
+// com precisão fixa de segundos em UTC, a ordem textual coincide com a cronológica
+const timestampLayout = "2006-01-02T15:04:05Z"
+
+// formatTimestamp converte um instante para o formato persistido
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// UserSortColumns mapeia os campos ordenáveis de usuários para suas colunas
+var UserSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"email":      "email",
+	"role":       "role",
+	"created_at": "created_at",
+}
+
+// UserSortValues retorna os valores das chaves de ordenação de um usuário
+func UserSortValues(u models.User, fields []SortField) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		switch f.Field {
+		case "id":
+			values[i] = u.ID
+		case "name":
+			values[i] = u.Name
+		case "email":
+			values[i] = u.Email
+		case "role":
+			values[i] = u.Role
+		case "created_at":
+			values[i] = formatTimestamp(u.CreateAt)
+		}
+	}
+	return values
+}
+
+// OrderSortColumns mapeia os campos ordenáveis de pedidos para suas colunas
+var OrderSortColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// OrderSortValues retorna os valores das chaves de ordenação de um pedido
+func OrderSortValues(o models.Order, fields []SortField) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		switch f.Field {
+		case "id":
+			values[i] = o.ID
+		case "created_at":
+			values[i] = formatTimestamp(o.CreatedAt)
+		case "updated_at":
+			values[i] = formatTimestamp(o.UpdatedAt)
+		}
+	}
+	return values
+}
+
+// ProductSortColumns mapeia os campos ordenáveis de produtos para suas colunas
+var ProductSortColumns = map[string]string{
+	"id":       "id",
+	"name":     "name",
+	"price":    "price_amount",
+	"stock":    "stock",
+	"category": "category",
+}
+
+// ProductSortValues retorna os valores das chaves de ordenação de um produto
+func ProductSortValues(p models.Product, fields []SortField) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		switch f.Field {
+		case "id":
+			values[i] = p.ID
+		case "name":
+			values[i] = p.Name
+		case "price":
+			values[i] = p.Price.Amount
+		case "stock":
+			values[i] = p.Stock
+		case "category":
+			values[i] = p.Category
+		}
+	}
+	return values
+}
+
+// compareKeys compara duas listas de valores de ordenação respeitando a direção de cada campo
+func compareKeys(fields []SortField, a, b []interface{}) int {
+	for i, f := range fields {
+		if i >= len(a) || i >= len(b) {
+			break
+		}
+		if c := compareValues(a[i], b[i]); c != 0 {
+			if f.Desc {
+				return -c
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+// compareValues compara valores de ordenação. Números são comparados como
+// float64, pois os valores vindos de cursores são decodificados de JSON.
+func compareValues(a, b interface{}) int {
+	if as, ok := a.(string); ok {
+		bs, _ := b.(string)
+		return strings.Compare(as, bs)
+	}
+
+	af, bf := toFloat(a), toFloat(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// toFloat converte os tipos numéricos usados nas chaves de ordenação
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/search"
+)
+
+// productDocument extrai os campos pesquisáveis de um produto
+func productDocument(p models.Product) search.Document {
+	var doc search.Document
+	doc[search.FieldName] = p.Name
+	doc[search.FieldCategory] = p.Category
+	doc[search.FieldDescription] = p.Description
+	return doc
+}
+
+// searchResults converte os hits do índice em resultados, aplicando o filtro e a
+// janela. Hits cujo produto não é mais encontrado são ignorados.
+func searchResults(hits []search.Hit, filter ProductFilter, window Window, lookup func(id int) (models.Product, bool)) ([]models.ProductSearchResult, int) {
+	results := make([]models.ProductSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if product, ok := lookup(hit.ID); ok && filter.Matches(product) {
+			results = append(results, models.ProductSearchResult{Product: product, Score: hit.Score})
+		}
+	}
+
+	start := window.Offset
+	switch {
+	case start < 0:
+		start = 0
+	case start > len(results):
+		start = len(results)
+	}
+	end := len(results)
+	if window.Limit > 0 && start+window.Limit < end {
+		end = start + window.Limit
+	}
+	return results[start:end], len(results)
+}
@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// scanner abstrai *sql.Row e *sql.Rows para reaproveitar a leitura de colunas
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryRower abstrai *sql.DB e *sql.Tx para consultas de uma linha
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// queryer abstrai *sql.DB e *sql.Tx para consultas de várias linhas
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// inTx executa fn dentro de uma transação, fazendo rollback em caso de erro.
+// Com uma única conexão aberta, fn deve usar apenas tx, nunca db.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// requireAffected retorna notFound quando o comando não alterou nenhuma linha
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// requireVersion trata o resultado de um comando condicionado à versão: sem
+// linhas afetadas, retorna notFound se o registro não existir fora da lixeira
+// e ErrVersionConflict caso contrário
+func requireVersion(ctx context.Context, db queryRower, result sql.Result, table string, id int, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists int
+	err = db.QueryRowContext(ctx, "SELECT 1 FROM "+table+" WHERE id = ? AND deleted_at IS NULL", id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	if err != nil {
+		return err
+	}
+	return ErrVersionConflict
+}
+
+// isUniqueViolation indica se o erro é a violação de um índice único
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// whereClause acumula as condições e os argumentos de um WHERE
+type whereClause struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add acrescenta uma condição combinada com AND
+func (w *whereClause) add(condition string, args ...interface{}) {
+	w.conditions = append(w.conditions, condition)
+	w.args = append(w.args, args...)
+}
+
+// String retorna o WHERE montado, ou vazio se não houver condições
+func (w whereClause) String() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// orderByClause monta o ORDER BY dos campos informados, desempatando pelo id
+func orderByClause(fields []SortField, columns map[string]string) string {
+	parts := make([]string, 0, len(fields)+1)
+	for _, f := range fields {
+		direction := " ASC"
+		if f.Desc {
+			direction = " DESC"
+		}
+		parts = append(parts, columns[f.Field]+direction)
+	}
+	parts = append(parts, "id ASC")
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// addKeyset acrescenta a condição que seleciona apenas registros posteriores
+// ao keyset: (a > ?) OR (a = ? AND b > ?) OR ... OR (a = ? AND b = ? AND id > ?)
+func addKeyset(where *whereClause, fields []SortField, columns map[string]string, after *models.Keyset) {
+	if after == nil {
+		return
+	}
+
+	var alternatives []string
+	var args []interface{}
+	for i := 0; i <= len(fields); i++ {
+		var terms []string
+		var termArgs []interface{}
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns[fields[j].Field]+" = ?")
+			termArgs = append(termArgs, keysetValue(after, j))
+		}
+		if i < len(fields) {
+			operator := " > ?"
+			if fields[i].Desc {
+				operator = " < ?"
+			}
+			terms = append(terms, columns[fields[i].Field]+operator)
+			termArgs = append(termArgs, keysetValue(after, i))
+		} else {
+			terms = append(terms, "id > ?")
+			termArgs = append(termArgs, after.ID)
+		}
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		args = append(args, termArgs...)
+	}
+	where.add("("+strings.Join(alternatives, " OR ")+")", args...)
+}
+
+// keysetValue retorna o i-ésimo valor do keyset, ou nil se ausente
+func keysetValue(after *models.Keyset, i int) interface{} {
+	if i < len(after.Values) {
+		return after.Values[i]
+	}
+	return nil
+}
+
+// limitOffset converte a janela em argumentos de LIMIT/OFFSET; LIMIT -1 significa
+// sem limite no SQLite, e deslocamentos negativos, que o SQLite trataria como
+// zero, são explicitamente zerados como em applyWindow
+func limitOffset(window Window) []interface{} {
+	offset := window.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if window.Limit <= 0 {
+		return []interface{}{-1, offset}
+	}
+	return []interface{}{window.Limit, offset}
+}
+
+// parseNullTimestamp converte uma coluna de data opcional, retornando nil quando NULL
+func parseNullTimestamp(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// deletedCondition retorna a condição que separa os registros da lixeira dos demais
+func deletedCondition(deleted bool) string {
+	if deleted {
+		return "deleted_at IS NOT NULL"
+	}
+	return "deleted_at IS NULL"
+}
+
+// inCondition retorna a condição "column IN (?, ...)" com n parâmetros; sem
+// parâmetros, a condição não aceita nenhuma linha
+func inCondition(column string, n int) string {
+	if n == 0 {
+		return "0 = 1"
+	}
+	return column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// intArgs converte os inteiros em argumentos de consulta
+func intArgs(values []int) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// nullableID grava o ID zero como NULL
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
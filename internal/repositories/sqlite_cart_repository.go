@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// SQLiteCartRepository é a implementação de CartStore persistida em SQLite
+type SQLiteCartRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteCartRepository cria uma nova instância do repositório SQLite de carrinhos
+func NewSQLiteCartRepository(db *sql.DB) *SQLiteCartRepository {
+	return &SQLiteCartRepository{db: db}
+}
+
+// Get retorna o carrinho do usuário com seus itens
+func (r *SQLiteCartRepository) Get(ctx context.Context, userID int) (*models.Cart, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT user_id, currency, created_at, updated_at, expires_at FROM carts WHERE user_id = ?",
+		userID,
+	)
+	cart, err := scanCart(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCartNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT product_id, variant_id, sku, name, quantity, unit_amount, added_at FROM cart_items WHERE user_id = ? ORDER BY position",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.CartItem
+		var addedAt string
+		if err := rows.Scan(&item.ProductID, &item.VariantID, &item.SKU, &item.Name, &item.Quantity, &item.UnitPrice.Amount, &addedAt); err != nil {
+			return nil, err
+		}
+		if item.AddedAt, err = time.Parse(time.RFC3339, addedAt); err != nil {
+			return nil, fmt.Errorf("added_at inválido no carrinho do usuário %d: %w", userID, err)
+		}
+		item.UnitPrice.Currency = cart.Total.Currency
+		cart.Items = append(cart.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stored, err := storedCart(*cart)
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Save cria ou substitui o carrinho do usuário e seus itens em uma transação
+func (r *SQLiteCartRepository) Save(ctx context.Context, cart models.Cart) (*models.Cart, error) {
+	cart, err := storedCart(cart)
+	if err != nil {
+		return nil, err
+	}
+
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO carts (user_id, currency, created_at, updated_at, expires_at) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET currency = excluded.currency, created_at = excluded.created_at,
+				updated_at = excluded.updated_at, expires_at = excluded.expires_at`,
+			cart.UserID, cart.Total.Currency, formatTimestamp(cart.CreatedAt), formatTimestamp(cart.UpdatedAt), formatTimestamp(cart.ExpiresAt),
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE user_id = ?", cart.UserID); err != nil {
+			return err
+		}
+
+		for i, item := range cart.Items {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO cart_items (user_id, position, product_id, variant_id, sku, name, quantity, unit_amount, added_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				cart.UserID, i, item.ProductID, item.VariantID, item.SKU, item.Name, item.Quantity, item.UnitPrice.Amount, formatTimestamp(item.AddedAt),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Delete remove o carrinho do usuário; os itens são removidos em cascata
+func (r *SQLiteCartRepository) Delete(ctx context.Context, userID int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM carts WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrCartNotFound)
+}
+
+// PurgeExpired remove os carrinhos que expiraram até before
+func (r *SQLiteCartRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM carts WHERE expires_at <= ?", formatTimestamp(before))
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(purged), nil
+}
+
+// scanCart converte uma linha do banco em um carrinho, sem os itens
+func scanCart(s scanner) (*models.Cart, error) {
+	var cart models.Cart
+	var createdAt, updatedAt, expiresAt string
+	if err := s.Scan(&cart.UserID, &cart.Total.Currency, &createdAt, &updatedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if cart.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido no carrinho do usuário %d: %w", cart.UserID, err)
+	}
+	if cart.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("updated_at inválido no carrinho do usuário %d: %w", cart.UserID, err)
+	}
+	if cart.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+		return nil, fmt.Errorf("expires_at inválido no carrinho do usuário %d: %w", cart.UserID, err)
+	}
+	return &cart, nil
+}
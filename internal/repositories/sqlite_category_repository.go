@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// SQLiteCategoryRepository é a implementação de CategoryStore persistida em SQLite
+type SQLiteCategoryRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteCategoryRepository cria uma nova instância do repositório SQLite de categorias
+func NewSQLiteCategoryRepository(db *sql.DB) *SQLiteCategoryRepository {
+	return &SQLiteCategoryRepository{db: db}
+}
+
+// List retorna todas as categorias, ordenadas por ID
+func (r *SQLiteCategoryRepository) List(ctx context.Context) ([]models.Category, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, slug, parent_id FROM categories ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+	return categories, rows.Err()
+}
+
+// GetByID retorna uma categoria pelo ID
+func (r *SQLiteCategoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT id, name, slug, parent_id FROM categories WHERE id = ?", id)
+	category, err := scanCategory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	return category, err
+}
+
+// Create cria uma nova categoria; a unicidade do slug é garantida pelo banco
+func (r *SQLiteCategoryRepository) Create(ctx context.Context, category models.Category) (*models.Category, error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO categories (name, slug, parent_id) VALUES (?, ?, ?)",
+		category.Name, category.Slug, category.ParentID,
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrSlugExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	category = storedCategory(category)
+	category.ID = int(id)
+	return &category, nil
+}
+
+// Update substitui nome, slug e pai de uma categoria
+func (r *SQLiteCategoryRepository) Update(ctx context.Context, id int, category models.Category) (*models.Category, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE categories SET name = ?, slug = ?, parent_id = ? WHERE id = ?",
+		category.Name, category.Slug, category.ParentID, id,
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrSlugExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, ErrCategoryNotFound); err != nil {
+		return nil, err
+	}
+
+	category = storedCategory(category)
+	category.ID = id
+	return &category, nil
+}
+
+// Delete remove uma categoria
+func (r *SQLiteCategoryRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM categories WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrCategoryNotFound)
+}
+
+// scanCategory converte uma linha do banco em uma categoria
+func scanCategory(s scanner) (*models.Category, error) {
+	var category models.Category
+	var parentID sql.NullInt64
+	if err := s.Scan(&category.ID, &category.Name, &category.Slug, &parentID); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		category.ParentID = &id
+	}
+	return &category, nil
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+const orderColumns = "id, user_id, status, total_amount, currency, version, created_at, updated_at"
+
+// SQLiteOrderRepository é a implementação de OrderStore persistida em SQLite
+type SQLiteOrderRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteOrderRepository cria uma nova instância do repositório SQLite de pedidos
+func NewSQLiteOrderRepository(db *sql.DB) *SQLiteOrderRepository {
+	return &SQLiteOrderRepository{db: db}
+}
+
+// List retorna uma janela de pedidos que atendem ao filtro, na ordenação solicitada
+func (r *SQLiteOrderRepository) List(ctx context.Context, query OrderQuery) ([]models.Order, int, error) {
+	var where whereClause
+	f := query.Filter
+	if f.UserID != 0 {
+		where.add("user_id = ?", f.UserID)
+	}
+	if f.Status != "" {
+		where.add("status = ?", f.Status)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM orders"+where.String(), where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	addKeyset(&where, query.Sort, OrderSortColumns, query.Window.After)
+	orders, err := r.query(ctx,
+		"SELECT "+orderColumns+" FROM orders"+where.String()+orderByClause(query.Sort, OrderSortColumns)+" LIMIT ? OFFSET ?",
+		append(where.args, limitOffset(query.Window)...)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+// GetByID retorna um pedido pelo ID
+func (r *SQLiteOrderRepository) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	orders, err := r.query(ctx, "SELECT "+orderColumns+" FROM orders WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	return &orders[0], nil
+}
+
+// Create grava o pedido, seus itens e seus descontos em uma transação
+func (r *SQLiteOrderRepository) Create(ctx context.Context, order models.Order) (*models.Order, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO orders (user_id, status, total_amount, currency, version, created_at, updated_at) VALUES (?, ?, ?, ?, 1, ?, ?)",
+			order.UserID, order.Status, order.Total.Amount, order.Total.Currency, formatTimestamp(order.CreatedAt), formatTimestamp(order.UpdatedAt),
+		)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		order.ID = int(id)
+
+		for i, item := range order.Items {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO order_items (order_id, position, product_id, variant_id, sku, name, quantity, unit_amount, reservation_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				order.ID, i, item.ProductID, item.VariantID, item.SKU, item.Name, item.Quantity, item.UnitPrice.Amount, item.ReservationID,
+			); err != nil {
+				return err
+			}
+		}
+		for i, discount := range order.Discounts {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO order_discounts (order_id, position, promotion_id, name, code, amount, redemption_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+				order.ID, i, discount.PromotionID, discount.Name, discount.Code, discount.Amount.Amount, discount.RedemptionID,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order = withOrderTotals(cloneOrder(order))
+	order.Version = 1
+	return &order, nil
+}
+
+// UpdateStatus altera o status de um pedido
+func (r *SQLiteOrderRepository) UpdateStatus(ctx context.Context, id int, version int, status string, updatedAt time.Time) (*models.Order, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE orders SET status = ?, updated_at = ?, version = version + 1 WHERE id = ? AND version = ?",
+		status, formatTimestamp(updatedAt), id, version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrVersionConflict
+	}
+	return r.GetByID(ctx, id)
+}
+
+// query executa uma consulta de pedidos e carrega os itens e os descontos de cada um
+func (r *SQLiteOrderRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Order, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.loadItems(ctx, orders); err != nil {
+		return nil, err
+	}
+	if err := r.loadDiscounts(ctx, orders); err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i] = withOrderTotals(orders[i])
+	}
+	return orders, nil
+}
+
+// loadItems preenche os itens dos pedidos com uma única consulta
+func (r *SQLiteOrderRepository) loadItems(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(orders))
+	args := make([]interface{}, len(orders))
+	byID := make(map[int]*models.Order, len(orders))
+	for i := range orders {
+		placeholders[i] = "?"
+		args[i] = orders[i].ID
+		orders[i].Items = []models.OrderItem{}
+		byID[orders[i].ID] = &orders[i]
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT order_id, product_id, variant_id, sku, name, quantity, unit_amount, reservation_id FROM order_items WHERE order_id IN ("+strings.Join(placeholders, ", ")+") ORDER BY order_id, position",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int
+		var item models.OrderItem
+		if err := rows.Scan(&orderID, &item.ProductID, &item.VariantID, &item.SKU, &item.Name, &item.Quantity, &item.UnitPrice.Amount, &item.ReservationID); err != nil {
+			return err
+		}
+		order := byID[orderID]
+		item.UnitPrice.Currency = order.Total.Currency
+		if item.Subtotal, err = item.UnitPrice.Times(item.Quantity); err != nil {
+			return fmt.Errorf("item do pedido %d: %w", orderID, err)
+		}
+		order.Items = append(order.Items, item)
+	}
+	return rows.Err()
+}
+
+// loadDiscounts preenche os descontos dos pedidos com uma única consulta
+func (r *SQLiteOrderRepository) loadDiscounts(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(orders))
+	byID := make(map[int]*models.Order, len(orders))
+	for i := range orders {
+		args[i] = orders[i].ID
+		orders[i].Discounts = []models.OrderDiscount{}
+		byID[orders[i].ID] = &orders[i]
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT order_id, promotion_id, name, code, amount, redemption_id FROM order_discounts WHERE "+inCondition("order_id", len(orders))+" ORDER BY order_id, position",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID int
+		var discount models.OrderDiscount
+		if err := rows.Scan(&orderID, &discount.PromotionID, &discount.Name, &discount.Code, &discount.Amount.Amount, &discount.RedemptionID); err != nil {
+			return err
+		}
+		order := byID[orderID]
+		discount.Amount.Currency = order.Total.Currency
+		order.Discounts = append(order.Discounts, discount)
+	}
+	return rows.Err()
+}
+
+// scanOrder converte uma linha do banco em um pedido, sem os itens
+func scanOrder(s scanner) (*models.Order, error) {
+	var order models.Order
+	var createdAt, updatedAt string
+	if err := s.Scan(&order.ID, &order.UserID, &order.Status, &order.Total.Amount, &order.Total.Currency, &order.Version, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if order.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido para o pedido %d: %w", order.ID, err)
+	}
+	if order.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("updated_at inválido para o pedido %d: %w", order.ID, err)
+	}
+	return &order, nil
+}
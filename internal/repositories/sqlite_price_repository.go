@@ -0,0 +1,326 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// Colunas lidas do histórico e dos agendamentos de preço, na ordem esperada
+// por scanPriceChange e scanSchedule
+const (
+	priceChangeColumns = "id, product_id, amount, currency, previous_amount, previous_currency, reason, actor, schedule_id, created_at"
+	scheduleColumns    = "id, product_id, amount, currency, starts_at, ends_at, status, previous_amount, reason, actor, created_at"
+)
+
+// openSchedules filtra os agendamentos em aberto de produtos fora da lixeira
+const openSchedules = "status IN ('scheduled', 'active') AND product_id IN (SELECT id FROM products WHERE deleted_at IS NULL)"
+
+// ListPriceChanges retorna uma janela do histórico de preços do produto
+func (r *SQLiteProductRepository) ListPriceChanges(ctx context.Context, productID int, window Window) ([]models.PriceChange, int, error) {
+	var exists, total int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT 1, (SELECT COUNT(*) FROM price_changes WHERE product_id = p.id) FROM products AS p WHERE id = ? AND deleted_at IS NULL",
+		productID,
+	).Scan(&exists, &total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var where whereClause
+	where.add("product_id = ?", productID)
+	addKeyset(&where, nil, nil, window.After)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+priceChangeColumns+" FROM price_changes"+where.String()+" ORDER BY id LIMIT ? OFFSET ?",
+		append(where.args, limitOffset(window)...)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	changes := []models.PriceChange{}
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		changes = append(changes, *change)
+	}
+	return changes, total, rows.Err()
+}
+
+// ListPriceSchedules retorna os agendamentos do produto, ordenados por início
+func (r *SQLiteProductRepository) ListPriceSchedules(ctx context.Context, productID int) ([]models.PriceSchedule, error) {
+	if err := requireProduct(ctx, r.db, productID); err != nil {
+		return nil, err
+	}
+	return querySchedules(ctx, r.db, "product_id = ? ORDER BY starts_at, id", productID)
+}
+
+// CreatePriceSchedule grava um agendamento, verificando a sobreposição com os
+// demais em aberto na mesma transação
+func (r *SQLiteProductRepository) CreatePriceSchedule(ctx context.Context, schedule models.PriceSchedule) (*models.PriceSchedule, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := requireProduct(ctx, tx, schedule.ProductID); err != nil {
+			return err
+		}
+		open, err := querySchedules(ctx, tx, "product_id = ? AND status IN ('scheduled', 'active')", schedule.ProductID)
+		if err != nil {
+			return err
+		}
+		for _, s := range open {
+			if schedulesOverlap(s, schedule) {
+				return ErrScheduleOverlap
+			}
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO price_schedules (product_id, amount, currency, starts_at, ends_at, status, reason, actor, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			schedule.ProductID, schedule.Price.Amount, schedule.Price.Currency, formatTimestamp(schedule.StartsAt), nullableTimestamp(schedule.EndsAt),
+			models.ScheduleScheduled, schedule.Reason, schedule.Actor, formatTimestamp(schedule.CreatedAt),
+		)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		schedule.ID = int(id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return getSchedule(ctx, r.db, schedule.ProductID, schedule.ID)
+}
+
+// CancelPriceSchedule cancela um agendamento em aberto, restaurando o preço
+// anterior na mesma transação se ele estiver ativo
+func (r *SQLiteProductRepository) CancelPriceSchedule(ctx context.Context, productID, scheduleID int, by string, at time.Time) (*models.PriceSchedule, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := requireProduct(ctx, tx, productID); err != nil {
+			return err
+		}
+		schedule, err := getSchedule(ctx, tx, productID, scheduleID)
+		if err != nil {
+			return err
+		}
+		if !schedule.Open() {
+			return ErrScheduleClosed
+		}
+		if schedule.Status == models.ScheduleActive {
+			if err := restorePrice(ctx, tx, *schedule, "cancelamento do agendamento", by, at); err != nil {
+				return err
+			}
+		}
+		return setScheduleStatus(ctx, tx, scheduleID, models.ScheduleCancelled, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return getSchedule(ctx, r.db, productID, scheduleID)
+}
+
+// ApplyPriceSchedules aplica, em ordem cronológica e em uma única transação,
+// os inícios e términos de agendamento devidos até now
+func (r *SQLiteProductRepository) ApplyPriceSchedules(ctx context.Context, now time.Time) (int, error) {
+	var applied int
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		open, err := querySchedules(ctx, tx, openSchedules)
+		if err != nil {
+			return err
+		}
+
+		transitions := dueTransitions(open, now)
+		for _, t := range transitions {
+			schedule := t.schedule
+			switch {
+			case !t.start:
+				if err := restorePrice(ctx, tx, schedule, "fim do agendamento", actor.System, t.at); err != nil {
+					return err
+				}
+				err = setScheduleStatus(ctx, tx, schedule.ID, models.ScheduleCompleted, nil)
+			case schedule.EndsAt != nil && !schedule.EndsAt.After(now):
+				err = setScheduleStatus(ctx, tx, schedule.ID, models.ScheduleCompleted, nil)
+			default:
+				var previous models.Money
+				if err := tx.QueryRowContext(ctx, "SELECT price_amount, currency FROM products WHERE id = ?", schedule.ProductID).Scan(&previous.Amount, &previous.Currency); err != nil {
+					return err
+				}
+				if err := setPrice(ctx, tx, schedule.ProductID, schedule.Price, previous, schedule.ID, "início do agendamento", actor.System, t.at); err != nil {
+					return err
+				}
+				if schedule.EndsAt != nil {
+					err = setScheduleStatus(ctx, tx, schedule.ID, models.ScheduleActive, &previous)
+				} else {
+					err = setScheduleStatus(ctx, tx, schedule.ID, models.ScheduleCompleted, nil)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		applied = len(transitions)
+		return nil
+	})
+	return applied, err
+}
+
+// NextPriceScheduleAt retorna o próximo início ou término de agendamento
+func (r *SQLiteProductRepository) NextPriceScheduleAt(ctx context.Context) (*time.Time, error) {
+	var next sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		"SELECT MIN(CASE WHEN status = 'active' AND ends_at IS NOT NULL THEN ends_at ELSE starts_at END) FROM price_schedules WHERE "+openSchedules,
+	).Scan(&next)
+	if err != nil {
+		return nil, err
+	}
+	return parseNullTimestamp(next)
+}
+
+// setPrice grava o novo preço do produto, incrementando a versão, e o registra no histórico
+func setPrice(ctx context.Context, tx *sql.Tx, productID int, price, previous models.Money, scheduleID int, reason, by string, at time.Time) error {
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE products SET price_amount = ?, currency = ?, version = version + 1 WHERE id = ?",
+		price.Amount, price.Currency, productID,
+	); err != nil {
+		return err
+	}
+	return recordPrice(ctx, tx, productID, &previous, scheduleID, reason, by, at)
+}
+
+// restorePrice devolve ao produto o preço anterior a um agendamento ativo,
+// desde que o preço não tenha sido alterado depois do início
+func restorePrice(ctx context.Context, tx *sql.Tx, schedule models.PriceSchedule, reason, by string, at time.Time) error {
+	if schedule.PreviousPrice == nil {
+		return nil
+	}
+	var current models.Money
+	err := tx.QueryRowContext(ctx, "SELECT price_amount, currency FROM products WHERE id = ?", schedule.ProductID).Scan(&current.Amount, &current.Currency)
+	if err != nil || current != schedule.Price {
+		return err
+	}
+	return setPrice(ctx, tx, schedule.ProductID, *schedule.PreviousPrice, current, schedule.ID, reason, by, at)
+}
+
+// recordPrice registra no histórico o preço atual do produto, já gravado na
+// transação; com previous, alterações que mantêm o preço são ignoradas
+func recordPrice(ctx context.Context, tx *sql.Tx, productID int, previous *models.Money, scheduleID int, reason, by string, at time.Time) error {
+	if previous == nil {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO price_changes (product_id, amount, currency, reason, actor, schedule_id, created_at) SELECT id, price_amount, currency, ?, ?, ?, ? FROM products WHERE id = ?",
+			reason, by, nullableID(scheduleID), formatTimestamp(at), productID,
+		)
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO price_changes (product_id, amount, currency, previous_amount, previous_currency, reason, actor, schedule_id, created_at) SELECT id, price_amount, currency, ?, ?, ?, ?, ?, ? FROM products WHERE id = ? AND NOT (price_amount = ? AND currency = ?)",
+		previous.Amount, previous.Currency, reason, by, nullableID(scheduleID), formatTimestamp(at), productID, previous.Amount, previous.Currency,
+	)
+	return err
+}
+
+// setScheduleStatus altera o status do agendamento e o preço a restaurar
+func setScheduleStatus(ctx context.Context, tx *sql.Tx, id int, status string, previous *models.Money) error {
+	var previousAmount interface{}
+	if previous != nil {
+		previousAmount = previous.Amount
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE price_schedules SET status = ?, previous_amount = ? WHERE id = ?", status, previousAmount, id)
+	return err
+}
+
+// getSchedule lê um agendamento do produto
+func getSchedule(ctx context.Context, q queryRower, productID, scheduleID int) (*models.PriceSchedule, error) {
+	row := q.QueryRowContext(ctx, "SELECT "+scheduleColumns+" FROM price_schedules WHERE id = ? AND product_id = ?", scheduleID, productID)
+	schedule, err := scanSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrScheduleNotFound
+	}
+	return schedule, err
+}
+
+// querySchedules lê os agendamentos que atendem à condição where
+func querySchedules(ctx context.Context, q queryer, where string, args ...interface{}) ([]models.PriceSchedule, error) {
+	rows, err := q.QueryContext(ctx, "SELECT "+scheduleColumns+" FROM price_schedules WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []models.PriceSchedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// scanPriceChange converte uma linha do banco em uma alteração de preço
+func scanPriceChange(s scanner) (*models.PriceChange, error) {
+	var change models.PriceChange
+	var previousAmount sql.NullInt64
+	var previousCurrency sql.NullString
+	var scheduleID sql.NullInt64
+	var createdAt string
+	err := s.Scan(&change.ID, &change.ProductID, &change.Price.Amount, &change.Price.Currency, &previousAmount, &previousCurrency,
+		&change.Reason, &change.Actor, &scheduleID, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if previousAmount.Valid {
+		change.Previous = &models.Money{Amount: previousAmount.Int64, Currency: previousCurrency.String}
+	}
+	change.ScheduleID = int(scheduleID.Int64)
+	if change.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido para a alteração de preço %d: %w", change.ID, err)
+	}
+	return &change, nil
+}
+
+// scanSchedule converte uma linha do banco em um agendamento de preço
+func scanSchedule(s scanner) (*models.PriceSchedule, error) {
+	var schedule models.PriceSchedule
+	var startsAt, createdAt string
+	var endsAt sql.NullString
+	var previousAmount sql.NullInt64
+	err := s.Scan(&schedule.ID, &schedule.ProductID, &schedule.Price.Amount, &schedule.Price.Currency, &startsAt, &endsAt,
+		&schedule.Status, &previousAmount, &schedule.Reason, &schedule.Actor, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	if previousAmount.Valid {
+		schedule.PreviousPrice = &models.Money{Amount: previousAmount.Int64, Currency: schedule.Price.Currency}
+	}
+	if schedule.StartsAt, err = time.Parse(time.RFC3339, startsAt); err != nil {
+		return nil, fmt.Errorf("starts_at inválido para o agendamento %d: %w", schedule.ID, err)
+	}
+	if schedule.EndsAt, err = parseNullTimestamp(endsAt); err != nil {
+		return nil, fmt.Errorf("ends_at inválido para o agendamento %d: %w", schedule.ID, err)
+	}
+	if schedule.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido para o agendamento %d: %w", schedule.ID, err)
+	}
+	return &schedule, nil
+}
+
+// nullableTimestamp formata o instante, gravando nulo na ausência dele
+func nullableTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTimestamp(*t)
+}
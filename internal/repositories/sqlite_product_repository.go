@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/search"
+)
+
+const productColumns = "id, name, description, price_amount, currency, stock, category_id, category, options, active, version, deleted_at"
+
+// searchBatchSize é a quantidade de IDs lidos por consulta em Search
+const searchBatchSize = 500
+
+// SQLiteProductRepository é a implementação de ProductStore persistida em SQLite
+type SQLiteProductRepository struct {
+	db *sql.DB
+
+	// O índice de busca é construído a partir do banco na primeira busca e
+	// mantido em sincronia pelas escritas feitas através do repositório
+	indexMu sync.Mutex
+	index   *search.Index
+}
+
+// NewSQLiteProductRepository cria uma nova instância do repositório SQLite de produtos
+func NewSQLiteProductRepository(db *sql.DB) *SQLiteProductRepository {
+	return &SQLiteProductRepository{db: db}
+}
+
+// GetAll retorna todos os produtos fora da lixeira
+func (r *SQLiteProductRepository) GetAll(ctx context.Context) ([]models.Product, error) {
+	return r.query(ctx, "SELECT "+productColumns+" FROM products WHERE deleted_at IS NULL ORDER BY id")
+}
+
+// GetByID retorna um produto pelo ID
+func (r *SQLiteProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = ? AND deleted_at IS NULL", id)
+	product, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	return product, err
+}
+
+// List retorna uma janela de produtos que atendem ao filtro, na ordenação solicitada
+func (r *SQLiteProductRepository) List(ctx context.Context, query ProductQuery) ([]models.Product, int, error) {
+	var where whereClause
+	f := query.Filter
+	where.add(deletedCondition(f.Deleted))
+	if f.Category != "" {
+		where.add("category = ?", f.Category)
+	}
+	if f.CategoryIDs != nil {
+		where.add(inCondition("category_id", len(f.CategoryIDs)), intArgs(f.CategoryIDs)...)
+	}
+	if f.Active != nil {
+		where.add("active = ?", *f.Active)
+	}
+	if f.Currency != "" {
+		where.add("currency = ?", f.Currency)
+	}
+	if f.MinPrice != nil {
+		where.add("price_amount >= ?", *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		where.add("price_amount <= ?", *f.MaxPrice)
+	}
+	if f.InStock != nil {
+		if *f.InStock {
+			where.add("stock > 0")
+		} else {
+			where.add("stock <= 0")
+		}
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products"+where.String(), where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	addKeyset(&where, query.Sort, ProductSortColumns, query.Window.After)
+	products, err := r.query(ctx,
+		"SELECT "+productColumns+" FROM products"+where.String()+orderByClause(query.Sort, ProductSortColumns)+" LIMIT ? OFFSET ?",
+		append(where.args, limitOffset(query.Window)...)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// Search retorna os produtos que casam com o texto, ordenados por relevância
+func (r *SQLiteProductRepository) Search(ctx context.Context, text string, filter ProductFilter, window Window) ([]models.ProductSearchResult, int, error) {
+	index, err := r.searchIndex(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := index.Search(text)
+	if len(hits) == 0 {
+		return []models.ProductSearchResult{}, 0, nil
+	}
+
+	// os produtos são lidos em lotes, para que buscas amplas não excedam o
+	// limite de parâmetros por consulta do SQLite
+	byID := make(map[int]models.Product, len(hits))
+	for start := 0; start < len(hits); start += searchBatchSize {
+		batch := hits[start:min(start+searchBatchSize, len(hits))]
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for i, hit := range batch {
+			placeholders[i] = "?"
+			args[i] = hit.ID
+		}
+		products, err := r.query(ctx,
+			"SELECT "+productColumns+" FROM products WHERE id IN ("+strings.Join(placeholders, ", ")+")",
+			args...,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, p := range products {
+			byID[p.ID] = p
+		}
+	}
+	results, total := searchResults(hits, filter, window, func(id int) (models.Product, bool) {
+		p, ok := byID[id]
+		return p, ok
+	})
+	return results, total, nil
+}
+
+// Create cria um novo produto, registrando o estoque inicial e o preço nos históricos
+func (r *SQLiteProductRepository) Create(ctx context.Context, product models.Product, change StockChange) (*models.Product, error) {
+	options, err := encodeOptions(product.Options)
+	if err != nil {
+		return nil, err
+	}
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO products (name, description, price_amount, currency, stock, category_id, category, options, active, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 1)",
+			product.Name, product.Description, product.Price.Amount, product.Price.Currency, product.Stock, nullableID(product.CategoryID), product.Category, options, product.Active,
+		)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		product.ID = int(id)
+		if err := recordPrice(ctx, tx, product.ID, nil, 0, change.Reason, change.Actor, change.At); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, product.ID, 0, product.Stock, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	product.Version = 1
+	product.DeletedAt = nil
+	r.indexProduct(product)
+	return &product, nil
+}
+
+// Update atualiza um produto existente, registrando as variações de estoque e
+// de preço nos históricos na mesma transação
+func (r *SQLiteProductRepository) Update(ctx context.Context, id int, product models.Product, change StockChange) (*models.Product, error) {
+	options, err := encodeOptions(product.Options)
+	if err != nil {
+		return nil, err
+	}
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var stock, reserved int
+		var previous models.Money
+		err := tx.QueryRowContext(ctx,
+			"SELECT stock, price_amount, currency, "+reservedQuantity+" FROM products AS p WHERE id = ? AND deleted_at IS NULL",
+			formatTimestamp(change.At), id,
+		).Scan(&stock, &previous.Amount, &previous.Currency, &reserved)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"UPDATE products SET name = ?, description = ?, price_amount = ?, currency = ?, stock = ?, category_id = ?, category = ?, options = ?, active = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+			product.Name, product.Description, product.Price.Amount, product.Price.Currency, product.Stock, nullableID(product.CategoryID), product.Category, options, product.Active, id, product.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireVersion(ctx, tx, result, "products", id, ErrProductNotFound); err != nil {
+			return err
+		}
+		if product.Stock < stock && product.Stock < reserved {
+			return ErrInsufficientStock
+		}
+		if err := recordPrice(ctx, tx, id, &previous, 0, change.Reason, change.Actor, change.At); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, id, 0, product.Stock-stock, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	product.ID = id
+	product.Version++
+	product.DeletedAt = nil
+	r.indexProduct(product)
+	return &product, nil
+}
+
+// SetActive altera o estado ativo de um produto
+func (r *SQLiteProductRepository) SetActive(ctx context.Context, id int, active bool) (*models.Product, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE products SET active = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL", active, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, ErrProductNotFound); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete move um produto para a lixeira, retirando-o do índice de busca
+func (r *SQLiteProductRepository) Delete(ctx context.Context, id int, version int, deletedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE products SET deleted_at = ? WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		formatTimestamp(deletedAt), id, version,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireVersion(ctx, r.db, result, "products", id, ErrProductNotFound); err != nil {
+		return err
+	}
+
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+	if r.index != nil {
+		r.index.Remove(id)
+	}
+	return nil
+}
+
+// Restore retira um produto da lixeira e o devolve ao índice de busca
+func (r *SQLiteProductRepository) Restore(ctx context.Context, id int) (*models.Product, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE products SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, ErrProductNotFound); err != nil {
+		return nil, err
+	}
+
+	product, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.indexProduct(*product)
+	return product, nil
+}
+
+// Purge remove definitivamente os produtos excluídos antes de before
+func (r *SQLiteProductRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM products WHERE deleted_at < ?", formatTimestamp(before))
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+// CountByCategory retorna a quantidade de produtos por ID de categoria
+func (r *SQLiteProductRepository) CountByCategory(ctx context.Context, includeDeleted bool) (map[int]int, error) {
+	query := "SELECT COALESCE(category_id, 0), COUNT(*) FROM products"
+	if !includeDeleted {
+		query += " WHERE deleted_at IS NULL"
+	}
+	rows, err := r.db.QueryContext(ctx, query+" GROUP BY category_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var categoryID, count int
+		if err := rows.Scan(&categoryID, &count); err != nil {
+			return nil, err
+		}
+		counts[categoryID] = count
+	}
+	return counts, rows.Err()
+}
+
+// RenameCategory atualiza o nome da categoria nos produtos; o índice de busca
+// é descartado e reconstruído na próxima busca
+func (r *SQLiteProductRepository) RenameCategory(ctx context.Context, categoryID int, name string) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE products SET category = ? WHERE category_id = ?", name, categoryID); err != nil {
+		return err
+	}
+
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+	r.index = nil
+	return nil
+}
+
+// searchIndex retorna o índice de busca, construindo-o a partir do banco na primeira chamada
+func (r *SQLiteProductRepository) searchIndex(ctx context.Context) (*search.Index, error) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.index != nil {
+		return r.index, nil
+	}
+
+	products, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := search.NewIndex()
+	for _, p := range products {
+		index.Put(p.ID, productDocument(p))
+	}
+	r.index = index
+	return index, nil
+}
+
+// indexProduct atualiza o produto no índice de busca, se ele já foi construído
+func (r *SQLiteProductRepository) indexProduct(product models.Product) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.index != nil {
+		r.index.Put(product.ID, productDocument(product))
+	}
+}
+
+// query executa uma consulta e converte as linhas em produtos
+func (r *SQLiteProductRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Product, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *product)
+	}
+	return products, rows.Err()
+}
+
+// scanProduct converte uma linha do banco em um produto
+func scanProduct(s scanner) (*models.Product, error) {
+	var product models.Product
+	var categoryID sql.NullInt64
+	var options string
+	var deletedAt sql.NullString
+	if err := s.Scan(&product.ID, &product.Name, &product.Description, &product.Price.Amount, &product.Price.Currency, &product.Stock, &categoryID, &product.Category, &options, &product.Active, &product.Version, &deletedAt); err != nil {
+		return nil, err
+	}
+	product.CategoryID = int(categoryID.Int64)
+
+	if err := json.Unmarshal([]byte(options), &product.Options); err != nil {
+		return nil, fmt.Errorf("options inválido para o produto %d: %w", product.ID, err)
+	}
+	var err error
+	if product.DeletedAt, err = parseNullTimestamp(deletedAt); err != nil {
+		return nil, fmt.Errorf("deleted_at inválido para o produto %d: %w", product.ID, err)
+	}
+	return &product, nil
+}
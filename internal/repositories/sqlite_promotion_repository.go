@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// promotionColumns são as colunas lidas por scanPromotion; uses conta os resgates vigentes
+const promotionColumns = `id, name, code, type, percentage, amount, currency, category_ids, product_ids, min_quantity,
+	starts_at, ends_at, max_uses, max_uses_per_user, stackable, active, created_at, updated_at,
+	(SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = promotions.id)`
+
+// SQLitePromotionRepository é a implementação de PromotionStore persistida em SQLite
+type SQLitePromotionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLitePromotionRepository cria uma nova instância do repositório SQLite de promoções
+func NewSQLitePromotionRepository(db *sql.DB) *SQLitePromotionRepository {
+	return &SQLitePromotionRepository{db: db}
+}
+
+// List retorna todas as promoções, ordenadas por ID
+func (r *SQLitePromotionRepository) List(ctx context.Context) ([]models.Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+promotionColumns+" FROM promotions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []models.Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *promotion)
+	}
+	return promotions, rows.Err()
+}
+
+// GetByID retorna uma promoção pelo ID
+func (r *SQLitePromotionRepository) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	return r.get(ctx, r.db, "id = ?", id)
+}
+
+// GetByCode retorna a promoção do cupom; a coluna code não diferencia maiúsculas
+func (r *SQLitePromotionRepository) GetByCode(ctx context.Context, code string) (*models.Promotion, error) {
+	return r.get(ctx, r.db, "code = ?", code)
+}
+
+// Create cria uma nova promoção; a unicidade do código é garantida pelo banco
+func (r *SQLitePromotionRepository) Create(ctx context.Context, promotion models.Promotion) (*models.Promotion, error) {
+	promotion = storedPromotion(promotion)
+	args, err := promotionArgs(promotion)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO promotions (name, code, type, percentage, amount, currency, category_ids, product_ids, min_quantity,
+			starts_at, ends_at, max_uses, max_uses_per_user, stackable, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append(args, formatTimestamp(promotion.CreatedAt), formatTimestamp(promotion.UpdatedAt))...,
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrCouponCodeExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	promotion.ID = int(id)
+	return &promotion, nil
+}
+
+// Update substitui os dados de uma promoção, preservando a data de criação
+func (r *SQLitePromotionRepository) Update(ctx context.Context, id int, promotion models.Promotion) (*models.Promotion, error) {
+	promotion = storedPromotion(promotion)
+	args, err := promotionArgs(promotion)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE promotions SET name = ?, code = ?, type = ?, percentage = ?, amount = ?, currency = ?, category_ids = ?,
+			product_ids = ?, min_quantity = ?, starts_at = ?, ends_at = ?, max_uses = ?, max_uses_per_user = ?,
+			stackable = ?, active = ?, updated_at = ?
+		WHERE id = ?`,
+		append(args, formatTimestamp(promotion.UpdatedAt), id)...,
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrCouponCodeExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, ErrPromotionNotFound); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete remove uma promoção; os resgates são removidos em cascata
+func (r *SQLitePromotionRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM promotions WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrPromotionNotFound)
+}
+
+// UserRedemptions retorna a quantidade de resgates vigentes do usuário por promoção
+func (r *SQLitePromotionRepository) UserRedemptions(ctx context.Context, userID int) (map[int]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT promotion_id, COUNT(*) FROM promotion_redemptions WHERE user_id = ? GROUP BY promotion_id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var promotionID, count int
+		if err := rows.Scan(&promotionID, &count); err != nil {
+			return nil, err
+		}
+		counts[promotionID] = count
+	}
+	return counts, rows.Err()
+}
+
+// Redeem registra os resgates em uma transação, verificando os limites de
+// cada promoção com os resgates já gravados
+func (r *SQLitePromotionRepository) Redeem(ctx context.Context, redemptions []models.Redemption) ([]models.Redemption, error) {
+	redeemed := make([]models.Redemption, len(redemptions))
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		for i, redemption := range redemptions {
+			promotion, err := r.get(ctx, tx, "id = ?", redemption.PromotionID)
+			if err != nil {
+				return err
+			}
+			var byUser int
+			if err := tx.QueryRowContext(ctx,
+				"SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = ? AND user_id = ?",
+				redemption.PromotionID, redemption.UserID,
+			).Scan(&byUser); err != nil {
+				return err
+			}
+			// os resgates anteriores do lote já foram gravados e entram nas contagens
+			if err := checkRedemptionLimits(*promotion, promotion.Uses, byUser, []models.Redemption{redemption}, redemption); err != nil {
+				return err
+			}
+
+			result, err := tx.ExecContext(ctx,
+				"INSERT INTO promotion_redemptions (promotion_id, user_id, created_at) VALUES (?, ?, ?)",
+				redemption.PromotionID, redemption.UserID, formatTimestamp(redemption.CreatedAt),
+			)
+			if err != nil {
+				return err
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			redemption.ID = int(id)
+			redeemed[i] = redemption
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redeemed, nil
+}
+
+// ReleaseRedemption remove um resgate, liberando-o para os limites da promoção
+func (r *SQLitePromotionRepository) ReleaseRedemption(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM promotion_redemptions WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrRedemptionNotFound)
+}
+
+// get retorna a promoção que atende à condição, ou ErrPromotionNotFound
+func (r *SQLitePromotionRepository) get(ctx context.Context, q queryRower, condition string, args ...interface{}) (*models.Promotion, error) {
+	row := q.QueryRowContext(ctx, "SELECT "+promotionColumns+" FROM promotions WHERE "+condition, args...)
+	promotion, err := scanPromotion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+// promotionArgs retorna os valores das colunas editáveis da promoção, na
+// ordem de INSERT e UPDATE, sem as datas de criação e alteração
+func promotionArgs(p models.Promotion) ([]interface{}, error) {
+	categoryIDs, err := json.Marshal(p.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	productIDs, err := json.Marshal(p.ProductIDs)
+	if err != nil {
+		return nil, err
+	}
+	var code, amount, currency interface{}
+	if p.Code != "" {
+		code = p.Code
+	}
+	if p.Amount != nil {
+		amount, currency = p.Amount.Amount, p.Amount.Currency
+	}
+	return []interface{}{
+		p.Name, code, p.Type, p.Percentage, amount, currency, string(categoryIDs), string(productIDs), p.MinQuantity,
+		nullableTimestamp(p.StartsAt), nullableTimestamp(p.EndsAt), p.MaxUses, p.MaxUsesPerUser, p.Stackable, p.Active,
+	}, nil
+}
+
+// scanPromotion converte uma linha do banco em uma promoção
+func scanPromotion(s scanner) (*models.Promotion, error) {
+	var p models.Promotion
+	var code, currency, startsAt, endsAt sql.NullString
+	var amount sql.NullInt64
+	var categoryIDs, productIDs, createdAt, updatedAt string
+	err := s.Scan(&p.ID, &p.Name, &code, &p.Type, &p.Percentage, &amount, &currency, &categoryIDs, &productIDs, &p.MinQuantity,
+		&startsAt, &endsAt, &p.MaxUses, &p.MaxUsesPerUser, &p.Stackable, &p.Active, &createdAt, &updatedAt, &p.Uses)
+	if err != nil {
+		return nil, err
+	}
+	p.Code = code.String
+	if amount.Valid {
+		p.Amount = &models.Money{Amount: amount.Int64, Currency: currency.String}
+	}
+	if err := json.Unmarshal([]byte(categoryIDs), &p.CategoryIDs); err != nil {
+		return nil, fmt.Errorf("category_ids inválido para a promoção %d: %w", p.ID, err)
+	}
+	if err := json.Unmarshal([]byte(productIDs), &p.ProductIDs); err != nil {
+		return nil, fmt.Errorf("product_ids inválido para a promoção %d: %w", p.ID, err)
+	}
+	if p.StartsAt, err = parseNullTimestamp(startsAt); err != nil {
+		return nil, fmt.Errorf("starts_at inválido para a promoção %d: %w", p.ID, err)
+	}
+	if p.EndsAt, err = parseNullTimestamp(endsAt); err != nil {
+		return nil, fmt.Errorf("ends_at inválido para a promoção %d: %w", p.ID, err)
+	}
+	if p.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido para a promoção %d: %w", p.ID, err)
+	}
+	if p.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("updated_at inválido para a promoção %d: %w", p.ID, err)
+	}
+	return &p, nil
+}
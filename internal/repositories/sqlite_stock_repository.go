@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+const (
+	reservationColumns = "id, product_id, variant_id, quantity, created_at, expires_at"
+	movementColumns    = "id, product_id, variant_id, type, quantity, balance, reason, actor, created_at"
+)
+
+// reservedQuantity soma as reservas vigentes do produto p; o instante de
+// referência é o primeiro argumento
+const reservedQuantity = "(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations WHERE product_id = p.id AND expires_at > ?)"
+
+// reservedVariantQuantity soma as reservas vigentes da variante v; o instante
+// de referência é o primeiro argumento
+const reservedVariantQuantity = "(SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations WHERE variant_id = v.id AND expires_at > ?)"
+
+// AdjustStock soma delta ao estoque de um produto sem eixos de variação com um
+// comando condicionado ao estoque disponível e ao limite de math.MaxInt,
+// registrando a movimentação na mesma transação
+func (r *SQLiteProductRepository) AdjustStock(ctx context.Context, id int, delta int, change StockChange) (*models.Product, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE products AS p SET stock = stock + ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL AND options = '[]' AND stock <= ? AND (? >= 0 OR stock + ? >= "+reservedQuantity+")",
+			delta, id, stockLimit(delta), delta, delta, formatTimestamp(change.At),
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireStock(ctx, tx, result, id); err != nil {
+			return overflowError(err, delta)
+		}
+		return recordMovement(ctx, tx, id, 0, delta, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Reserve grava a reserva com um único comando condicionado ao estoque
+// disponível do produto ou da variante
+func (r *SQLiteProductRepository) Reserve(ctx context.Context, reservation models.StockReservation) (*models.StockReservation, error) {
+	now := formatTimestamp(reservation.CreatedAt)
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM stock_reservations WHERE expires_at <= ?", now); err != nil {
+		return nil, err
+	}
+
+	var result sql.Result
+	var err error
+	if reservation.VariantID != 0 {
+		result, err = r.db.ExecContext(ctx,
+			"INSERT INTO stock_reservations (product_id, variant_id, quantity, created_at, expires_at) SELECT v.product_id, v.id, ?, ?, ? FROM product_variants AS v JOIN products AS p ON p.id = v.product_id WHERE v.id = ? AND v.product_id = ? AND p.deleted_at IS NULL AND v.stock - "+reservedVariantQuantity+" >= ?",
+			reservation.Quantity, now, formatTimestamp(reservation.ExpiresAt), reservation.VariantID, reservation.ProductID, now, reservation.Quantity,
+		)
+		if err == nil {
+			err = requireVariantStock(ctx, r.db, result, reservation.ProductID, reservation.VariantID)
+		}
+	} else {
+		result, err = r.db.ExecContext(ctx,
+			"INSERT INTO stock_reservations (product_id, quantity, created_at, expires_at) SELECT id, ?, ?, ? FROM products AS p WHERE id = ? AND deleted_at IS NULL AND options = '[]' AND stock - "+reservedQuantity+" >= ?",
+			reservation.Quantity, now, formatTimestamp(reservation.ExpiresAt), reservation.ProductID, now, reservation.Quantity,
+		)
+		if err == nil {
+			err = requireStock(ctx, r.db, result, reservation.ProductID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	reservation.ID = int(id)
+	return &reservation, nil
+}
+
+// GetReservation retorna uma reserva vigente
+func (r *SQLiteProductRepository) GetReservation(ctx context.Context, id int, now time.Time) (*models.StockReservation, error) {
+	return getReservation(ctx, r.db, id, now)
+}
+
+// Reserved soma as reservas vigentes do produto ou da variante
+func (r *SQLiteProductRepository) Reserved(ctx context.Context, productID, variantID int, now time.Time) (int, error) {
+	column, id := "product_id", productID
+	if variantID != 0 {
+		column, id = "variant_id", variantID
+	}
+	var reserved int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations WHERE "+column+" = ? AND expires_at > ?",
+		id, formatTimestamp(now),
+	).Scan(&reserved)
+	return reserved, err
+}
+
+// CommitReservation confirma uma reserva vigente em uma transação
+func (r *SQLiteProductRepository) CommitReservation(ctx context.Context, id int, change StockChange) (*models.Product, error) {
+	var productID int
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		reservation, err := getReservation(ctx, tx, id, change.At)
+		if err != nil {
+			return err
+		}
+		productID = reservation.ProductID
+
+		result, err := tx.ExecContext(ctx,
+			"UPDATE products SET stock = stock - ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL AND stock >= ?",
+			reservation.Quantity, reservation.ProductID, reservation.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireStock(ctx, tx, result, reservation.ProductID); err != nil {
+			return err
+		}
+		if reservation.VariantID != 0 {
+			result, err := tx.ExecContext(ctx,
+				"UPDATE product_variants SET stock = stock - ? WHERE id = ? AND product_id = ? AND stock >= ?",
+				reservation.Quantity, reservation.VariantID, reservation.ProductID, reservation.Quantity,
+			)
+			if err != nil {
+				return err
+			}
+			if err := requireVariantStock(ctx, tx, result, reservation.ProductID, reservation.VariantID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM stock_reservations WHERE id = ?", id); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, reservation.ProductID, reservation.VariantID, -reservation.Quantity, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, productID)
+}
+
+// ReleaseReservation remove uma reserva vigente
+func (r *SQLiteProductRepository) ReleaseReservation(ctx context.Context, id int, now time.Time) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM stock_reservations WHERE id = ? AND expires_at > ?", id, formatTimestamp(now))
+	if err != nil {
+		return err
+	}
+	return requireAffected(result, ErrReservationNotFound)
+}
+
+// ListMovements retorna uma janela do histórico de estoque do produto
+func (r *SQLiteProductRepository) ListMovements(ctx context.Context, productID int, window Window) ([]models.StockMovement, int, error) {
+	var exists, total int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT 1, (SELECT COUNT(*) FROM stock_movements WHERE product_id = p.id) FROM products AS p WHERE id = ? AND deleted_at IS NULL",
+		productID,
+	).Scan(&exists, &total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var where whereClause
+	where.add("product_id = ?", productID)
+	addKeyset(&where, nil, nil, window.After)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+movementColumns+" FROM stock_movements"+where.String()+" ORDER BY id LIMIT ? OFFSET ?",
+		append(where.args, limitOffset(window)...)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []models.StockMovement{}
+	for rows.Next() {
+		var movement models.StockMovement
+		var createdAt string
+		if err := rows.Scan(&movement.ID, &movement.ProductID, &movement.VariantID, &movement.Type, &movement.Quantity, &movement.Balance, &movement.Reason, &movement.Actor, &createdAt); err != nil {
+			return nil, 0, err
+		}
+		if movement.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, 0, fmt.Errorf("created_at inválido para a movimentação %d: %w", movement.ID, err)
+		}
+		movements = append(movements, movement)
+	}
+	return movements, total, rows.Err()
+}
+
+// recordMovement registra no histórico uma variação de estoque já aplicada ao
+// produto, com o estoque resultante como saldo; variações nulas são ignoradas
+// e variantID é zero fora das variantes
+func recordMovement(ctx context.Context, tx *sql.Tx, productID, variantID, delta int, change StockChange) error {
+	if delta == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO stock_movements (product_id, variant_id, type, quantity, balance, reason, actor, created_at) SELECT id, ?, ?, ?, stock, ?, ?, ? FROM products WHERE id = ?",
+		variantID, change.Type, delta, change.Reason, change.Actor, formatTimestamp(change.At), productID,
+	)
+	return err
+}
+
+// getReservation lê uma reserva vigente pelo ID
+func getReservation(ctx context.Context, q queryRower, id int, now time.Time) (*models.StockReservation, error) {
+	row := q.QueryRowContext(ctx, "SELECT "+reservationColumns+" FROM stock_reservations WHERE id = ? AND expires_at > ?", id, formatTimestamp(now))
+
+	var reservation models.StockReservation
+	var createdAt, expiresAt string
+	err := row.Scan(&reservation.ID, &reservation.ProductID, &reservation.VariantID, &reservation.Quantity, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reservation.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido para a reserva %d: %w", reservation.ID, err)
+	}
+	if reservation.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+		return nil, fmt.Errorf("expires_at inválido para a reserva %d: %w", reservation.ID, err)
+	}
+	return &reservation, nil
+}
+
+// stockLimit retorna o maior estoque ao qual delta pode ser somado sem
+// ultrapassar math.MaxInt
+func stockLimit(delta int) int {
+	if delta > 0 {
+		return math.MaxInt - delta
+	}
+	return math.MaxInt
+}
+
+// overflowError troca ErrInsufficientStock por ErrStockOverflow em entradas,
+// que só são recusadas pelo limite de stockLimit
+func overflowError(err error, delta int) error {
+	if delta > 0 && errors.Is(err, ErrInsufficientStock) {
+		return ErrStockOverflow
+	}
+	return err
+}
+
+// requireStock trata o resultado de um comando condicionado ao estoque: sem
+// linhas afetadas, retorna ErrProductNotFound se o produto não existir fora
+// da lixeira, ErrVariantRequired se ele tiver eixos de variação e
+// ErrInsufficientStock caso contrário
+func requireStock(ctx context.Context, q queryRower, result sql.Result, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var hasOptions bool
+	err = q.QueryRowContext(ctx, "SELECT options <> '[]' FROM products WHERE id = ? AND deleted_at IS NULL", id).Scan(&hasOptions)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if hasOptions {
+		return ErrVariantRequired
+	}
+	return ErrInsufficientStock
+}
+
+// requireVariantStock trata o resultado de um comando condicionado ao estoque
+// da variante: sem linhas afetadas, retorna ErrProductNotFound ou
+// ErrVariantNotFound se o produto ou a variante não existirem, e
+// ErrInsufficientStock caso contrário
+func requireVariantStock(ctx context.Context, q queryRower, result sql.Result, productID, variantID int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var variantExists bool
+	err = q.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM product_variants WHERE id = ? AND product_id = p.id) FROM products AS p WHERE id = ? AND deleted_at IS NULL",
+		variantID, productID,
+	).Scan(&variantExists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !variantExists {
+		return ErrVariantNotFound
+	}
+	return ErrInsufficientStock
+}
@@ -0,0 +1,69 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/database"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories/storetest"
+)
+
+// openTestDB abre um banco SQLite em um arquivo temporário, com todas as
+// migrações aplicadas; o banco é fechado ao fim do subteste
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "api.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteUserStore(t *testing.T) {
+	storetest.TestUserStore(t, func(t *testing.T) repositories.UserStore {
+		return repositories.NewSQLiteUserRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteProductStore(t *testing.T) {
+	storetest.TestProductStore(t, func(t *testing.T) repositories.ProductStore {
+		return repositories.NewSQLiteProductRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteCategoryStore(t *testing.T) {
+	storetest.TestCategoryStore(t, func(t *testing.T) repositories.CategoryStore {
+		return repositories.NewSQLiteCategoryRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteOrderStore(t *testing.T) {
+	storetest.TestOrderStore(t, func(t *testing.T) repositories.OrderStore {
+		return repositories.NewSQLiteOrderRepository(openTestDB(t))
+	})
+}
+
+func TestSQLiteCartStore(t *testing.T) {
+	storetest.TestCartStore(t, func(t *testing.T) repositories.CartStore {
+		return repositories.NewSQLiteCartRepository(openTestDB(t))
+	})
+}
+
+func TestSQLitePromotionStore(t *testing.T) {
+	storetest.TestPromotionStore(t, func(t *testing.T) repositories.PromotionStore {
+		return repositories.NewSQLitePromotionRepository(openTestDB(t))
+	})
+}
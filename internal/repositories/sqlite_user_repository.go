@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+const userColumns = "id, name, email, role, active, created_at, version, deleted_at"
+
+// SQLiteUserRepository é a implementação de UserStore persistida em SQLite
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository cria uma nova instância do repositório SQLite de usuários
+func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+// GetAll retorna todos os usuários fora da lixeira
+func (r *SQLiteUserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+userColumns+" FROM users WHERE deleted_at IS NULL ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// List retorna uma janela de usuários que atendem ao filtro, na ordenação solicitada
+func (r *SQLiteUserRepository) List(ctx context.Context, query UserQuery) ([]models.User, int, error) {
+	var where whereClause
+	f := query.Filter
+	where.add(deletedCondition(f.Deleted))
+	if f.Role != "" {
+		where.add("role = ?", f.Role)
+	}
+	if f.Active != nil {
+		where.add("active = ?", *f.Active)
+	}
+	if f.EmailDomain != "" {
+		where.add("LOWER(SUBSTR(email, INSTR(email, '@') + 1)) = LOWER(?)", f.EmailDomain)
+	}
+	if f.CreatedAfter != nil {
+		where.add("created_at >= ?", formatTimestamp(*f.CreatedAfter))
+	}
+	if f.CreatedBefore != nil {
+		where.add("created_at < ?", formatTimestamp(*f.CreatedBefore))
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+where.String(), where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	addKeyset(&where, query.Sort, UserSortColumns, query.Window.After)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+userColumns+" FROM users"+where.String()+orderByClause(query.Sort, UserSortColumns)+" LIMIT ? OFFSET ?",
+		append(where.args, limitOffset(query.Window)...)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+	return users, total, rows.Err()
+}
+
+// GetByID retorna um usuário pelo ID
+func (r *SQLiteUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ? AND deleted_at IS NULL", id)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// Create cria um novo usuário
+func (r *SQLiteUserRepository) Create(ctx context.Context, user models.User) (*models.User, error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO users (name, email, role, active, created_at, version) VALUES (?, ?, ?, ?, ?, 1)",
+		user.Name, user.Email, user.Role, user.Active, formatTimestamp(user.CreateAt),
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrEmailExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	user.ID = int(id)
+	user.Version = 1
+	user.DeletedAt = nil
+	return &user, nil
+}
+
+// Update atualiza um usuário existente
+func (r *SQLiteUserRepository) Update(ctx context.Context, id int, user models.User) (*models.User, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET name = ?, email = ?, role = ?, active = ?, created_at = ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		user.Name, user.Email, user.Role, user.Active, formatTimestamp(user.CreateAt), id, user.Version,
+	)
+	if isUniqueViolation(err) {
+		return nil, ErrEmailExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := requireVersion(ctx, r.db, result, "users", id, ErrUserNotFound); err != nil {
+		return nil, err
+	}
+
+	user.ID = id
+	user.Version++
+	user.DeletedAt = nil
+	return &user, nil
+}
+
+// SetActive altera o estado ativo de um usuário
+func (r *SQLiteUserRepository) SetActive(ctx context.Context, id int, active bool) (*models.User, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE users SET active = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL", active, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, ErrUserNotFound); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete move um usuário para a lixeira
+func (r *SQLiteUserRepository) Delete(ctx context.Context, id int, version int, deletedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET deleted_at = ? WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		formatTimestamp(deletedAt), id, version,
+	)
+	if err != nil {
+		return err
+	}
+	return requireVersion(ctx, r.db, result, "users", id, ErrUserNotFound)
+}
+
+// Restore retira um usuário da lixeira, desde que seu email não tenha sido
+// reutilizado por outro usuário
+func (r *SQLiteUserRepository) Restore(ctx context.Context, id int) (*models.User, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if isUniqueViolation(err) {
+		return nil, ErrEmailExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, ErrUserNotFound); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Purge remove definitivamente os usuários excluídos antes de before
+func (r *SQLiteUserRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE deleted_at < ?", formatTimestamp(before))
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	return int(purged), err
+}
+
+// scanUser converte uma linha do banco em um usuário
+func scanUser(s scanner) (*models.User, error) {
+	var user models.User
+	var createdAt string
+	var deletedAt sql.NullString
+	if err := s.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.Active, &createdAt, &user.Version, &deletedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if user.CreateAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("created_at inválido para o usuário %d: %w", user.ID, err)
+	}
+	if user.DeletedAt, err = parseNullTimestamp(deletedAt); err != nil {
+		return nil, fmt.Errorf("deleted_at inválido para o usuário %d: %w", user.ID, err)
+	}
+	return &user, nil
+}
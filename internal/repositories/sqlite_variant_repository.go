@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// variantColumns lista as colunas da variante, com a moeda do produto para o
+// preço próprio; as consultas devem juntar products AS p
+const variantColumns = "v.id, v.product_id, v.sku, v.options, v.price_amount, p.currency, v.stock, v.active"
+
+// ListVariants retorna as variantes do produto, ordenadas por ID
+func (r *SQLiteProductRepository) ListVariants(ctx context.Context, productID int) ([]models.Variant, error) {
+	if err := requireProduct(ctx, r.db, productID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+variantColumns+" FROM product_variants AS v JOIN products AS p ON p.id = v.product_id WHERE v.product_id = ? ORDER BY v.id",
+		productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []models.Variant{}
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, *variant)
+	}
+	return variants, rows.Err()
+}
+
+// GetVariant retorna uma variante do produto
+func (r *SQLiteProductRepository) GetVariant(ctx context.Context, productID, variantID int) (*models.Variant, error) {
+	return getVariant(ctx, r.db, productID, variantID)
+}
+
+// CreateVariant grava uma variante e soma seu estoque ao do produto em uma transação
+func (r *SQLiteProductRepository) CreateVariant(ctx context.Context, variant models.Variant, productVersion int, change StockChange) (*models.Variant, error) {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return nil, err
+	}
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := bumpProductVersion(ctx, tx, variant.ProductID, productVersion, variant.Stock); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO product_variants (product_id, sku, options, price_amount, stock, active) VALUES (?, ?, ?, ?, ?, ?)",
+			variant.ProductID, variant.SKU, string(options), overrideAmount(variant.PriceOverride), variant.Stock, variant.Active,
+		)
+		if isUniqueViolation(err) {
+			return ErrSKUExists
+		}
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		variant.ID = int(id)
+		return recordMovement(ctx, tx, variant.ProductID, variant.ID, variant.Stock, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetVariant(ctx, variant.ProductID, variant.ID)
+}
+
+// UpdateVariant substitui uma variante e aplica ao produto a diferença de
+// estoque em uma transação
+func (r *SQLiteProductRepository) UpdateVariant(ctx context.Context, variant models.Variant, productVersion int, change StockChange) (*models.Variant, error) {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return nil, err
+	}
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := bumpProductVersion(ctx, tx, variant.ProductID, productVersion, 0); err != nil {
+			return err
+		}
+		var stock, reserved int
+		err := tx.QueryRowContext(ctx,
+			"SELECT stock, "+reservedVariantQuantity+" FROM product_variants AS v WHERE id = ? AND product_id = ?",
+			formatTimestamp(change.At), variant.ID, variant.ProductID,
+		).Scan(&stock, &reserved)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			return err
+		}
+		delta := variant.Stock - stock
+		if delta < 0 && variant.Stock < reserved {
+			return ErrInsufficientStock
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE product_variants SET sku = ?, options = ?, price_amount = ?, stock = ?, active = ? WHERE id = ?",
+			variant.SKU, string(options), overrideAmount(variant.PriceOverride), variant.Stock, variant.Active, variant.ID,
+		)
+		if isUniqueViolation(err) {
+			return ErrSKUExists
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET stock = stock + ? WHERE id = ?", delta, variant.ProductID); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, variant.ProductID, variant.ID, delta, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetVariant(ctx, variant.ProductID, variant.ID)
+}
+
+// DeleteVariant remove uma variante sem reservas vigentes e retira seu
+// estoque do produto em uma transação
+func (r *SQLiteProductRepository) DeleteVariant(ctx context.Context, productID, variantID int, productVersion int, change StockChange) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := bumpProductVersion(ctx, tx, productID, productVersion, 0); err != nil {
+			return err
+		}
+		var stock, reserved int
+		err := tx.QueryRowContext(ctx,
+			"SELECT stock, "+reservedVariantQuantity+" FROM product_variants AS v WHERE id = ? AND product_id = ?",
+			formatTimestamp(change.At), variantID, productID,
+		).Scan(&stock, &reserved)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if reserved > 0 {
+			return ErrVariantReserved
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM product_variants WHERE id = ?", variantID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET stock = stock - ? WHERE id = ?", stock, productID); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, productID, variantID, -stock, change)
+	})
+}
+
+// AdjustVariantStock soma delta ao estoque da variante com um comando
+// condicionado ao estoque disponível e ao limite do estoque do produto, que
+// inclui o da variante, e aplica a mesma variação ao produto, registrando a
+// movimentação na mesma transação
+func (r *SQLiteProductRepository) AdjustVariantStock(ctx context.Context, productID, variantID int, delta int, change StockChange) (*models.Product, error) {
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE product_variants AS v SET stock = stock + ? WHERE id = ? AND product_id = ? AND EXISTS (SELECT 1 FROM products WHERE id = v.product_id AND deleted_at IS NULL AND stock <= ?) AND (? >= 0 OR stock + ? >= "+reservedVariantQuantity+")",
+			delta, variantID, productID, stockLimit(delta), delta, delta, formatTimestamp(change.At),
+		)
+		if err != nil {
+			return err
+		}
+		if err := requireVariantStock(ctx, tx, result, productID, variantID); err != nil {
+			return overflowError(err, delta)
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET stock = stock + ?, version = version + 1 WHERE id = ?", delta, productID); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, productID, variantID, delta, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, productID)
+}
+
+// bumpProductVersion soma delta ao estoque do produto e incrementa sua versão
+// se version for a atual, com os erros de requireVersion
+func bumpProductVersion(ctx context.Context, tx *sql.Tx, productID, version, delta int) error {
+	result, err := tx.ExecContext(ctx,
+		"UPDATE products SET stock = stock + ?, version = version + 1 WHERE id = ? AND version = ? AND deleted_at IS NULL",
+		delta, productID, version,
+	)
+	if err != nil {
+		return err
+	}
+	return requireVersion(ctx, tx, result, "products", productID, ErrProductNotFound)
+}
+
+// requireProduct retorna ErrProductNotFound se o produto não existir fora da lixeira
+func requireProduct(ctx context.Context, q queryRower, id int) error {
+	var exists int
+	err := q.QueryRowContext(ctx, "SELECT 1 FROM products WHERE id = ? AND deleted_at IS NULL", id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	return err
+}
+
+// getVariant lê uma variante de um produto fora da lixeira
+func getVariant(ctx context.Context, q queryRower, productID, variantID int) (*models.Variant, error) {
+	if err := requireProduct(ctx, q, productID); err != nil {
+		return nil, err
+	}
+	row := q.QueryRowContext(ctx,
+		"SELECT "+variantColumns+" FROM product_variants AS v JOIN products AS p ON p.id = v.product_id WHERE v.id = ? AND v.product_id = ?",
+		variantID, productID,
+	)
+	variant, err := scanVariant(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVariantNotFound
+	}
+	return variant, err
+}
+
+// scanVariant converte uma linha do banco em uma variante
+func scanVariant(s scanner) (*models.Variant, error) {
+	var variant models.Variant
+	var options, currency string
+	var priceAmount sql.NullInt64
+	if err := s.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &options, &priceAmount, &currency, &variant.Stock, &variant.Active); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &variant.Options); err != nil {
+		return nil, fmt.Errorf("options inválido para a variante %d: %w", variant.ID, err)
+	}
+	if priceAmount.Valid {
+		variant.PriceOverride = &models.Money{Amount: priceAmount.Int64, Currency: currency}
+	}
+	return &variant, nil
+}
+
+// encodeOptions serializa os eixos de variação, gravando a lista vazia como "[]"
+func encodeOptions(options []models.ProductOption) (string, error) {
+	if len(options) == 0 {
+		return "[]", nil
+	}
+	encoded, err := json.Marshal(options)
+	return string(encoded), err
+}
+
+// overrideAmount retorna o valor do preço próprio da variante, ou nulo
+func overrideAmount(price *models.Money) interface{} {
+	if price == nil {
+		return nil
+	}
+	return price.Amount
+}
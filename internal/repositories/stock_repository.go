@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// AdjustStock soma delta ao estoque de um produto; retiradas respeitam as
+// reservas vigentes e entradas não podem passar de math.MaxInt
+func (r *ProductRepository) AdjustStock(ctx context.Context, id int, delta int, change StockChange) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	if len(r.products[i].Options) > 0 {
+		return nil, ErrVariantRequired
+	}
+	if delta < 0 && r.products[i].Stock+delta < r.reserved(id, change.At) {
+		return nil, ErrInsufficientStock
+	}
+	if delta > 0 && r.products[i].Stock > math.MaxInt-delta {
+		return nil, ErrStockOverflow
+	}
+	r.products[i].Stock += delta
+	r.products[i].Version++
+	r.record(id, 0, delta, r.products[i].Stock, change)
+	product := cloneProduct(r.products[i])
+	return &product, nil
+}
+
+// Reserve grava uma reserva se o estoque disponível, do produto ou da
+// variante, comportar a quantidade
+func (r *ProductRepository) Reserve(ctx context.Context, reservation models.StockReservation) (*models.StockReservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropExpiredReservations(reservation.CreatedAt)
+	i := r.find(reservation.ProductID, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	switch {
+	case reservation.VariantID != 0:
+		j := r.findVariant(reservation.ProductID, reservation.VariantID)
+		if j < 0 {
+			return nil, ErrVariantNotFound
+		}
+		if r.variants[j].Stock-r.reservedVariant(reservation.VariantID, reservation.CreatedAt) < reservation.Quantity {
+			return nil, ErrInsufficientStock
+		}
+	case len(r.products[i].Options) > 0:
+		return nil, ErrVariantRequired
+	case r.products[i].Stock-r.reserved(reservation.ProductID, reservation.CreatedAt) < reservation.Quantity:
+		return nil, ErrInsufficientStock
+	}
+
+	reservation.ID = r.nextReservationID
+	r.nextReservationID++
+	r.reservations[reservation.ID] = reservation
+	return &reservation, nil
+}
+
+// GetReservation retorna uma reserva vigente
+func (r *ProductRepository) GetReservation(ctx context.Context, id int, now time.Time) (*models.StockReservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok || !reservation.Active(now) {
+		return nil, ErrReservationNotFound
+	}
+	return &reservation, nil
+}
+
+// Reserved soma as reservas vigentes do produto ou da variante
+func (r *ProductRepository) Reserved(ctx context.Context, productID, variantID int, now time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if variantID != 0 {
+		return r.reservedVariant(variantID, now), nil
+	}
+	return r.reserved(productID, now), nil
+}
+
+// CommitReservation confirma uma reserva vigente, retirando a quantidade do estoque
+func (r *ProductRepository) CommitReservation(ctx context.Context, id int, change StockChange) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok || !reservation.Active(change.At) {
+		return nil, ErrReservationNotFound
+	}
+	i := r.find(reservation.ProductID, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	if r.products[i].Stock < reservation.Quantity {
+		return nil, ErrInsufficientStock
+	}
+	if reservation.VariantID != 0 {
+		j := r.findVariant(reservation.ProductID, reservation.VariantID)
+		if j < 0 {
+			return nil, ErrVariantNotFound
+		}
+		if r.variants[j].Stock < reservation.Quantity {
+			return nil, ErrInsufficientStock
+		}
+		r.variants[j].Stock -= reservation.Quantity
+	}
+
+	delete(r.reservations, id)
+	r.products[i].Stock -= reservation.Quantity
+	r.products[i].Version++
+	r.record(reservation.ProductID, reservation.VariantID, -reservation.Quantity, r.products[i].Stock, change)
+	product := cloneProduct(r.products[i])
+	return &product, nil
+}
+
+// ReleaseReservation remove uma reserva vigente
+func (r *ProductRepository) ReleaseReservation(ctx context.Context, id int, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok || !reservation.Active(now) {
+		return ErrReservationNotFound
+	}
+	delete(r.reservations, id)
+	return nil
+}
+
+// ListMovements retorna uma janela do histórico de estoque do produto
+func (r *ProductRepository) ListMovements(ctx context.Context, productID int, window Window) ([]models.StockMovement, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.find(productID, false) < 0 {
+		return nil, 0, ErrProductNotFound
+	}
+	var movements []models.StockMovement
+	for _, movement := range r.movements {
+		if movement.ProductID == productID {
+			movements = append(movements, movement)
+		}
+	}
+
+	page := applyWindow(movements, window, nil, func(models.StockMovement) []interface{} { return nil }, movementID)
+	return page, len(movements), nil
+}
+
+// record acrescenta uma movimentação ao histórico; variações nulas são
+// ignoradas e variantID é zero fora das variantes. Deve ser chamado com o
+// lock de escrita adquirido.
+func (r *ProductRepository) record(productID, variantID, delta, balance int, change StockChange) {
+	if delta == 0 {
+		return
+	}
+	r.movements = append(r.movements, models.StockMovement{
+		ID:        r.nextMovementID,
+		ProductID: productID,
+		VariantID: variantID,
+		Type:      change.Type,
+		Quantity:  delta,
+		Balance:   balance,
+		Reason:    change.Reason,
+		Actor:     change.Actor,
+		CreatedAt: change.At,
+	})
+	r.nextMovementID++
+}
+
+// movementID retorna o ID da movimentação, usado como chave da paginação
+func movementID(m models.StockMovement) int {
+	return m.ID
+}
+
+// reserved soma as quantidades reservadas e vigentes do produto. Deve ser
+// chamado com o lock adquirido.
+func (r *ProductRepository) reserved(productID int, now time.Time) int {
+	total := 0
+	for _, reservation := range r.reservations {
+		if reservation.ProductID == productID && reservation.Active(now) {
+			total += reservation.Quantity
+		}
+	}
+	return total
+}
+
+// dropExpiredReservations descarta as reservas expiradas. Deve ser chamado
+// com o lock de escrita adquirido.
+func (r *ProductRepository) dropExpiredReservations(now time.Time) {
+	for id, reservation := range r.reservations {
+		if !reservation.Active(now) {
+			delete(r.reservations, id)
+		}
+	}
+}
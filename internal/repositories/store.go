@@ -0,0 +1,434 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// ErrVersionConflict indica que o registro foi alterado desde a versão informada
+var ErrVersionConflict = errors.New("o registro foi alterado por outra requisição")
+
+// Window delimita a fatia de registros retornada por List.
+// Limit menor ou igual a zero significa "sem limite"; quando After está
+// presente, apenas registros posteriores à posição são considerados.
+type Window struct {
+	Offset int
+	Limit  int
+	After  *models.Keyset
+}
+
+// UserFilter representa os filtros de uma listagem de usuários.
+// Campos nulos ou vazios não restringem o resultado.
+type UserFilter struct {
+	Role          string
+	Active        *bool
+	EmailDomain   string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Deleted seleciona os usuários da lixeira em vez dos demais
+	Deleted bool
+}
+
+// Matches indica se o usuário atende a todos os filtros. CreatedAfter é
+// inclusivo e CreatedBefore é exclusivo; EmailDomain ignora maiúsculas.
+func (f UserFilter) Matches(u models.User) bool {
+	if (u.DeletedAt != nil) != f.Deleted {
+		return false
+	}
+	if f.Role != "" && u.Role != f.Role {
+		return false
+	}
+	if f.Active != nil && u.Active != *f.Active {
+		return false
+	}
+	if f.EmailDomain != "" && !strings.EqualFold(emailDomain(u.Email), f.EmailDomain) {
+		return false
+	}
+	if f.CreatedAfter != nil && u.CreateAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !u.CreateAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// UserQuery representa os critérios de uma listagem de usuários
+type UserQuery struct {
+	Filter UserFilter
+	Sort   []SortField
+	Window Window
+}
+
+// ProductFilter representa os filtros de uma listagem de produtos.
+// Campos nulos ou vazios não restringem o resultado.
+type ProductFilter struct {
+	// Category restringe ao nome exato da categoria
+	Category string
+	// CategoryIDs restringe às categorias informadas; nil não restringe e uma
+	// lista vazia não aceita nenhum produto
+	CategoryIDs []int
+	Active      *bool
+	// Currency restringe a moeda; MinPrice e MaxPrice são valores em
+	// unidades menores dessa moeda
+	Currency string
+	MinPrice *int64
+	MaxPrice *int64
+	InStock  *bool
+	// Deleted seleciona os produtos da lixeira em vez dos demais
+	Deleted bool
+}
+
+// Matches indica se o produto atende a todos os filtros
+func (f ProductFilter) Matches(p models.Product) bool {
+	if (p.DeletedAt != nil) != f.Deleted {
+		return false
+	}
+	if f.Category != "" && p.Category != f.Category {
+		return false
+	}
+	if f.CategoryIDs != nil && !containsID(f.CategoryIDs, p.CategoryID) {
+		return false
+	}
+	if f.Active != nil && p.Active != *f.Active {
+		return false
+	}
+	if f.Currency != "" && p.Price.Currency != f.Currency {
+		return false
+	}
+	if f.MinPrice != nil && p.Price.Amount < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && p.Price.Amount > *f.MaxPrice {
+		return false
+	}
+	if f.InStock != nil && (p.Stock > 0) != *f.InStock {
+		return false
+	}
+	return true
+}
+
+// ProductQuery representa os critérios de uma listagem de produtos
+type ProductQuery struct {
+	Filter ProductFilter
+	Sort   []SortField
+	Window Window
+}
+
+// OrderFilter representa os filtros de uma listagem de pedidos.
+// Campos vazios não restringem o resultado.
+type OrderFilter struct {
+	UserID int
+	Status string
+}
+
+// Matches indica se o pedido atende a todos os filtros
+func (f OrderFilter) Matches(o models.Order) bool {
+	if f.UserID != 0 && o.UserID != f.UserID {
+		return false
+	}
+	if f.Status != "" && o.Status != f.Status {
+		return false
+	}
+	return true
+}
+
+// OrderQuery representa os critérios de uma listagem de pedidos
+type OrderQuery struct {
+	Filter OrderFilter
+	Sort   []SortField
+	Window Window
+}
+
+// StockChange descreve a origem de uma alteração de estoque, registrada no
+// histórico como uma movimentação do tipo Type (models.Movement*)
+type StockChange struct {
+	Type   string
+	Reason string
+	Actor  string
+	At     time.Time
+}
+
+// UserStore define as operações de persistência de usuários. Usuários na
+// lixeira só são visíveis em List com Filter.Deleted, Restore e Purge.
+type UserStore interface {
+	GetAll(ctx context.Context) ([]models.User, error)
+	// List retorna a janela solicitada, na ordenação da consulta (desempatada
+	// por ID), e o total de usuários que atendem ao filtro (sem considerar a janela)
+	List(ctx context.Context, query UserQuery) ([]models.User, int, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	// Create grava o usuário com a versão 1. O email é único entre os usuários
+	// fora da lixeira, sem diferenciar maiúsculas, e a verificação é atômica
+	// com a gravação: um email em uso resulta em ErrEmailExists.
+	Create(ctx context.Context, user models.User) (*models.User, error)
+	// Update substitui o usuário se user.Version for a versão atual, ou retorna
+	// ErrVersionConflict; o registro gravado recebe a versão seguinte. O email
+	// segue a mesma regra de unicidade de Create.
+	Update(ctx context.Context, id int, user models.User) (*models.User, error)
+	// SetActive altera apenas o estado ativo do usuário, incrementa a versão e
+	// retorna o registro atualizado
+	SetActive(ctx context.Context, id int, active bool) (*models.User, error)
+	// Delete move o usuário para a lixeira, registrando deletedAt, se version
+	// for a versão atual, ou retorna ErrVersionConflict
+	Delete(ctx context.Context, id int, version int, deletedAt time.Time) error
+	// Restore retira o usuário da lixeira e retorna o registro restaurado, ou
+	// ErrEmailExists se o email passou a ser usado por outro usuário
+	Restore(ctx context.Context, id int) (*models.User, error)
+	// Purge remove definitivamente os usuários excluídos antes de before e
+	// retorna quantos foram removidos
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+// ProductStore define as operações de persistência de produtos. Produtos na
+// lixeira só são visíveis em List com Filter.Deleted, Restore e Purge.
+type ProductStore interface {
+	GetAll(ctx context.Context) ([]models.Product, error)
+	GetByID(ctx context.Context, id int) (*models.Product, error)
+	// List retorna a janela solicitada, na ordenação da consulta (desempatada
+	// por ID), e o total de produtos que atendem ao filtro (sem considerar a janela)
+	List(ctx context.Context, query ProductQuery) ([]models.Product, int, error)
+	// Search retorna a janela solicitada dos produtos que casam com o texto e
+	// atendem ao filtro, ordenados por relevância, e o total de produtos encontrados
+	Search(ctx context.Context, text string, filter ProductFilter, window Window) ([]models.ProductSearchResult, int, error)
+	// Create grava o produto com a versão 1; um estoque inicial diferente de
+	// zero é registrado no histórico como a movimentação descrita em change, e
+	// o preço inicial, no histórico de preços com o motivo, o ator e o
+	// instante de change
+	Create(ctx context.Context, product models.Product, change StockChange) (*models.Product, error)
+	// Update substitui o produto se product.Version for a versão atual, ou
+	// retorna ErrVersionConflict; o registro gravado recebe a versão seguinte.
+	// Se o estoque mudar, a diferença é registrada no histórico como a
+	// movimentação descrita em change; se o preço mudar, a alteração é
+	// registrada no histórico de preços como em Create, na mesma operação.
+	// Reduzir o estoque abaixo das reservas vigentes em change.At resulta em
+	// ErrInsufficientStock.
+	Update(ctx context.Context, id int, product models.Product, change StockChange) (*models.Product, error)
+	// SetActive altera apenas o estado ativo do produto, incrementa a versão e
+	// retorna o registro atualizado
+	SetActive(ctx context.Context, id int, active bool) (*models.Product, error)
+	// Delete move o produto para a lixeira, registrando deletedAt, se version
+	// for a versão atual, ou retorna ErrVersionConflict
+	Delete(ctx context.Context, id int, version int, deletedAt time.Time) error
+	// Restore retira o produto da lixeira e retorna o registro restaurado
+	Restore(ctx context.Context, id int) (*models.Product, error)
+	// Purge remove definitivamente os produtos excluídos antes de before e
+	// retorna quantos foram removidos, junto com as variantes, as reservas e
+	// os agendamentos de preço deles
+	Purge(ctx context.Context, before time.Time) (int, error)
+
+	// AdjustStock soma delta ao estoque do produto, registra a movimentação
+	// descrita em change, incrementa a versão e retorna o registro atualizado.
+	// A verificação é atômica com a gravação: uma retirada que deixaria o
+	// estoque abaixo das reservas vigentes em change.At resulta em
+	// ErrInsufficientStock, e uma entrada que levaria o estoque além de
+	// math.MaxInt, em ErrStockOverflow. Em produtos com eixos de variação, o
+	// estoque só é alterado pelas variantes, e o resultado é ErrVariantRequired.
+	AdjustStock(ctx context.Context, id int, delta int, change StockChange) (*models.Product, error)
+	// AdjustVariantStock soma delta ao estoque da variante e ao do produto,
+	// com as mesmas regras de AdjustStock aplicadas às reservas da variante e
+	// ao limite do estoque do produto; uma variante que não pertence ao
+	// produto resulta em ErrVariantNotFound
+	AdjustVariantStock(ctx context.Context, productID, variantID int, delta int, change StockChange) (*models.Product, error)
+
+	// ListVariants retorna as variantes do produto, ordenadas por ID, ou
+	// ErrProductNotFound se o produto não existir fora da lixeira
+	ListVariants(ctx context.Context, productID int) ([]models.Variant, error)
+	// GetVariant retorna uma variante do produto, ou ErrVariantNotFound. O
+	// preço efetivo (Price) não é preenchido pelo store.
+	GetVariant(ctx context.Context, productID, variantID int) (*models.Variant, error)
+	// CreateVariant grava a variante se productVersion for a versão atual do
+	// produto, ou retorna ErrVersionConflict; um SKU em uso resulta em
+	// ErrSKUExists. O estoque da variante é somado ao do produto e registrado
+	// no histórico como a movimentação descrita em change, e a versão do
+	// produto é incrementada, na mesma operação.
+	CreateVariant(ctx context.Context, variant models.Variant, productVersion int, change StockChange) (*models.Variant, error)
+	// UpdateVariant substitui a variante com as mesmas regras de CreateVariant,
+	// aplicando ao produto a diferença de estoque; reduzir o estoque abaixo
+	// das reservas vigentes da variante em change.At resulta em ErrInsufficientStock
+	UpdateVariant(ctx context.Context, variant models.Variant, productVersion int, change StockChange) (*models.Variant, error)
+	// DeleteVariant remove a variante, retirando seu estoque do produto, com a
+	// mesma regra de versão de CreateVariant; uma variante com reservas
+	// vigentes em change.At resulta em ErrVariantReserved
+	DeleteVariant(ctx context.Context, productID, variantID int, productVersion int, change StockChange) error
+
+	// CountByCategory retorna a quantidade de produtos por ID de categoria,
+	// incluindo os da lixeira apenas com includeDeleted
+	CountByCategory(ctx context.Context, includeDeleted bool) (map[int]int, error)
+	// RenameCategory atualiza o nome da categoria nos produtos que a
+	// referenciam, inclusive os da lixeira, sem alterar a versão deles
+	RenameCategory(ctx context.Context, categoryID int, name string) error
+	// Reserve grava a reserva, atribuindo-lhe um ID, se o estoque disponível
+	// em reservation.CreatedAt (estoque menos reservas vigentes) comportar a
+	// quantidade, ou retorna ErrInsufficientStock. Com reservation.VariantID,
+	// o estoque considerado é o da variante; sem ele, produtos com eixos de
+	// variação resultam em ErrVariantRequired.
+	Reserve(ctx context.Context, reservation models.StockReservation) (*models.StockReservation, error)
+	// GetReservation retorna a reserva se ela estiver vigente em now, ou
+	// ErrReservationNotFound
+	GetReservation(ctx context.Context, id int, now time.Time) (*models.StockReservation, error)
+	// Reserved soma as reservas vigentes em now do produto ou, com variantID
+	// diferente de zero, da variante; sem reservas, o resultado é zero
+	Reserved(ctx context.Context, productID, variantID int, now time.Time) (int, error)
+	// CommitReservation remove a reserva vigente em change.At e retira a
+	// quantidade do estoque do produto (e da variante reservada, se houver),
+	// registrando a movimentação descrita em change e incrementando a versão,
+	// e retorna o produto atualizado
+	CommitReservation(ctx context.Context, id int, change StockChange) (*models.Product, error)
+	// ReleaseReservation remove a reserva vigente, devolvendo a quantidade ao
+	// estoque disponível
+	ReleaseReservation(ctx context.Context, id int, now time.Time) error
+	// ListMovements retorna a janela solicitada do histórico de estoque do
+	// produto, em ordem cronológica (por ID), e o total de movimentações, ou
+	// ErrProductNotFound se o produto não existir fora da lixeira
+	ListMovements(ctx context.Context, productID int, window Window) ([]models.StockMovement, int, error)
+
+	// ListPriceChanges retorna a janela solicitada do histórico de preços do
+	// produto, em ordem cronológica (por ID), e o total de alterações, ou
+	// ErrProductNotFound se o produto não existir fora da lixeira
+	ListPriceChanges(ctx context.Context, productID int, window Window) ([]models.PriceChange, int, error)
+	// ListPriceSchedules retorna todos os agendamentos de preço do produto,
+	// ordenados por início, com a mesma regra de existência de ListPriceChanges
+	ListPriceSchedules(ctx context.Context, productID int) ([]models.PriceSchedule, error)
+	// CreatePriceSchedule grava o agendamento com o status scheduled. A
+	// verificação é atômica com a gravação: um período que se sobrepõe ao de
+	// outro agendamento em aberto do produto resulta em ErrScheduleOverlap.
+	CreatePriceSchedule(ctx context.Context, schedule models.PriceSchedule) (*models.PriceSchedule, error)
+	// CancelPriceSchedule cancela um agendamento em aberto, ou retorna
+	// ErrScheduleClosed. Se ele estiver ativo e o preço do produto ainda for
+	// o agendado, o preço anterior é restaurado e registrado no histórico com
+	// o ator by e o instante at, incrementando a versão do produto.
+	CancelPriceSchedule(ctx context.Context, productID, scheduleID int, by string, at time.Time) (*models.PriceSchedule, error)
+	// ApplyPriceSchedules aplica, em ordem cronológica e na mesma operação,
+	// os inícios e términos de agendamentos devidos até now, e retorna quantos
+	// foram processados. Cada alteração é registrada no histórico de preços
+	// no instante agendado, com o ator actor.System, e incrementa a versão do
+	// produto; um término só restaura o preço anterior se o preço ainda for o
+	// agendado, e um agendamento cujo período inteiro já passou é concluído
+	// sem alterar o preço. Produtos na lixeira são ignorados.
+	ApplyPriceSchedules(ctx context.Context, now time.Time) (int, error)
+	// NextPriceScheduleAt retorna o instante do próximo início ou término de
+	// agendamento dos produtos fora da lixeira, ou nil se não houver
+	NextPriceScheduleAt(ctx context.Context) (*time.Time, error)
+}
+
+// OrderStore define as operações de persistência de pedidos. Pedidos não são
+// excluídos: cancelamentos e reembolsos são mudanças de status.
+type OrderStore interface {
+	// List retorna a janela solicitada, na ordenação da consulta (desempatada
+	// por ID), e o total de pedidos que atendem ao filtro (sem considerar a janela)
+	List(ctx context.Context, query OrderQuery) ([]models.Order, int, error)
+	GetByID(ctx context.Context, id int) (*models.Order, error)
+	// Create grava o pedido, seus itens e seus descontos, na ordem informada,
+	// com a versão 1; subtotal e desconto são recalculados a partir deles
+	Create(ctx context.Context, order models.Order) (*models.Order, error)
+	// UpdateStatus altera o status do pedido se version for a versão atual, ou
+	// retorna ErrVersionConflict; registra updatedAt, incrementa a versão e
+	// retorna o registro atualizado
+	UpdateStatus(ctx context.Context, id int, version int, status string, updatedAt time.Time) (*models.Order, error)
+}
+
+// CategoryStore define as operações de persistência de categorias. A
+// hierarquia não é validada pelo store: ciclos e pais inexistentes são
+// responsabilidade do serviço.
+type CategoryStore interface {
+	// List retorna todas as categorias, ordenadas por ID, sem contagens nem filhas
+	List(ctx context.Context) ([]models.Category, error)
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	// Create grava a categoria; um slug em uso resulta em ErrSlugExists
+	Create(ctx context.Context, category models.Category) (*models.Category, error)
+	// Update substitui nome, slug e pai da categoria, com a mesma regra de
+	// unicidade do slug de Create
+	Update(ctx context.Context, id int, category models.Category) (*models.Category, error)
+	// Delete remove a categoria definitivamente
+	Delete(ctx context.Context, id int) error
+}
+
+// CartStore define as operações de persistência de carrinhos, um por usuário.
+// Os carrinhos são gravados por inteiro, com os itens na ordem informada;
+// apenas os campos registrados dos itens são persistidos.
+type CartStore interface {
+	// Get retorna o carrinho do usuário, ou ErrCartNotFound, mesmo que já tenha expirado
+	Get(ctx context.Context, userID int) (*models.Cart, error)
+	// Save cria ou substitui o carrinho do usuário
+	Save(ctx context.Context, cart models.Cart) (*models.Cart, error)
+	// Delete remove o carrinho do usuário, ou retorna ErrCartNotFound
+	Delete(ctx context.Context, userID int) error
+	// PurgeExpired remove os carrinhos que expiraram até before e retorna
+	// quantos foram removidos
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// PromotionStore define as operações de persistência de promoções e dos seus
+// resgates. Uses é calculado a cada leitura a partir dos resgates vigentes, e
+// os limites de uso são verificados pelo store no registro dos resgates.
+type PromotionStore interface {
+	// List retorna todas as promoções, ordenadas por ID
+	List(ctx context.Context) ([]models.Promotion, error)
+	GetByID(ctx context.Context, id int) (*models.Promotion, error)
+	// GetByCode retorna a promoção do cupom, sem diferenciar maiúsculas, ou
+	// ErrPromotionNotFound
+	GetByCode(ctx context.Context, code string) (*models.Promotion, error)
+	// Create grava a promoção; um código de cupom em uso resulta em
+	// ErrCouponCodeExists, e promoções sem código nunca conflitam
+	Create(ctx context.Context, promotion models.Promotion) (*models.Promotion, error)
+	// Update substitui os dados da promoção, exceto a data de criação, com a
+	// mesma regra de unicidade do código de Create
+	Update(ctx context.Context, id int, promotion models.Promotion) (*models.Promotion, error)
+	// Delete remove a promoção e seus resgates
+	Delete(ctx context.Context, id int) error
+	// UserRedemptions retorna a quantidade de resgates vigentes do usuário,
+	// indexada pelo ID da promoção
+	UserRedemptions(ctx context.Context, userID int) (map[int]int, error)
+	// Redeem registra os resgates de uma só vez. Se algum exceder o limite
+	// total ou por usuário da sua promoção, nenhum é registrado e o retorno é
+	// ErrPromotionExhausted ou ErrPromotionUserLimit.
+	Redeem(ctx context.Context, redemptions []models.Redemption) ([]models.Redemption, error)
+	// ReleaseRedemption remove um resgate, ou retorna ErrRedemptionNotFound
+	ReleaseRedemption(ctx context.Context, id int) error
+}
+
+// Garante em tempo de compilação que as implementações satisfazem as interfaces
+var (
+	_ UserStore    = (*UserRepository)(nil)
+	_ ProductStore = (*ProductRepository)(nil)
+	_ UserStore    = (*SQLiteUserRepository)(nil)
+	_ ProductStore = (*SQLiteProductRepository)(nil)
+	_ OrderStore   = (*OrderRepository)(nil)
+	_ OrderStore   = (*SQLiteOrderRepository)(nil)
+	_ CartStore    = (*CartRepository)(nil)
+	_ CartStore    = (*SQLiteCartRepository)(nil)
+
+	_ CategoryStore = (*CategoryRepository)(nil)
+	_ CategoryStore = (*SQLiteCategoryRepository)(nil)
+
+	_ PromotionStore = (*PromotionRepository)(nil)
+	_ PromotionStore = (*SQLitePromotionRepository)(nil)
+)
+
+// NormalizeEmail retorna o email sem espaços nas bordas e em minúsculas, forma
+// usada na comparação de unicidade
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// containsID indica se id está na lista
+func containsID(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// emailDomain retorna a parte do email após o "@"
+func emailDomain(email string) string {
+	if i := strings.LastIndex(email, "@"); i >= 0 {
+		return email[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,2994 @@
+// Package storetest contém a suíte de conformidade compartilhada pelas
+// implementações de repositories.UserStore, repositories.ProductStore,
+// repositories.CategoryStore, repositories.OrderStore, repositories.CartStore e
+// repositories.PromotionStore.
+//
+// Cada backend deve chamar TestUserStore, TestProductStore, TestCategoryStore,
+// TestOrderStore, TestCartStore e TestPromotionStore a partir dos seus próprios
+// testes, garantindo que todos se comportam da mesma forma.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+// UserStoreFactory cria uma instância isolada de UserStore para cada subteste
+type UserStoreFactory func(t *testing.T) repositories.UserStore
+
+// ProductStoreFactory cria uma instância isolada de ProductStore para cada subteste
+type ProductStoreFactory func(t *testing.T) repositories.ProductStore
+
+// CategoryStoreFactory cria uma instância isolada de CategoryStore para cada subteste
+type CategoryStoreFactory func(t *testing.T) repositories.CategoryStore
+
+// OrderStoreFactory cria uma instância isolada de OrderStore para cada subteste
+type OrderStoreFactory func(t *testing.T) repositories.OrderStore
+
+// CartStoreFactory cria uma instância isolada de CartStore para cada subteste
+type CartStoreFactory func(t *testing.T) repositories.CartStore
+
+// PromotionStoreFactory cria uma instância isolada de PromotionStore para cada subteste
+type PromotionStoreFactory func(t *testing.T) repositories.PromotionStore
+
+// TestUserStore executa a suíte de conformidade contra um UserStore
+func TestUserStore(t *testing.T, newStore UserStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("create@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.ID <= 0 {
+			t.Fatalf("Create: esperado ID positivo, obtido %d", created.ID)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != *created {
+			t.Errorf("GetByID: esperado %+v, obtido %+v", *created, *got)
+		}
+	})
+
+	t.Run("CreateAssignsDistinctIDs", func(t *testing.T) {
+		store := newStore(t)
+
+		first, err := store.Create(ctx, newUser("first@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		second, err := store.Create(ctx, newUser("second@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if first.ID == second.ID {
+			t.Errorf("Create: IDs repetidos (%d)", first.ID)
+		}
+	})
+
+	t.Run("GetAllIncludesCreated", func(t *testing.T) {
+		store := newStore(t)
+
+		before, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		created, err := store.Create(ctx, newUser("list@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		after, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(after) != len(before)+1 {
+			t.Fatalf("GetAll: esperado %d usuários, obtido %d", len(before)+1, len(after))
+		}
+		if !containsUser(after, created.ID) {
+			t.Errorf("GetAll: usuário %d ausente", created.ID)
+		}
+	})
+
+	t.Run("ListPaginates", func(t *testing.T) {
+		store := newStore(t)
+
+		for i := 0; i < 3; i++ {
+			if _, err := store.Create(ctx, newUser(fmt.Sprintf("page%d@example.com", i))); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		all, total, err := store.List(ctx, repositories.UserQuery{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(all) != total {
+			t.Fatalf("List sem limite: esperado %d usuários, obtido %d", total, len(all))
+		}
+
+		var seen []int
+		for page := 1; (page-1)*2 < total; page++ {
+			users, pageTotal, err := store.List(ctx, repositories.UserQuery{Window: repositories.Window{Offset: (page - 1) * 2, Limit: 2}})
+			if err != nil {
+				t.Fatalf("List página %d: %v", page, err)
+			}
+			if pageTotal != total {
+				t.Errorf("List página %d: esperado total %d, obtido %d", page, total, pageTotal)
+			}
+			if len(users) > 2 {
+				t.Errorf("List página %d: limite 2 excedido (%d)", page, len(users))
+			}
+			for _, u := range users {
+				seen = append(seen, u.ID)
+			}
+		}
+		assertAscendingUnique(t, seen, total)
+	})
+
+	t.Run("ListFiltersAndSorts", func(t *testing.T) {
+		store := newStore(t)
+
+		day := func(d int) time.Time { return time.Date(2030, 3, d, 9, 0, 0, 0, time.UTC) }
+		specs := []models.User{
+			{Name: "Bruna", Email: "bruna@filtro.test", Role: "manager", Active: true, CreateAt: day(1)},
+			{Name: "Alice", Email: "alice@FILTRO.test", Role: "manager", Active: false, CreateAt: day(2)},
+			{Name: "Caio", Email: "caio@outro.test", Role: "manager", Active: true, CreateAt: day(3)},
+			{Name: "Davi", Email: "davi@filtro.test", Role: "user", Active: true, CreateAt: day(4)},
+		}
+		var created []models.User
+		for _, u := range specs {
+			c, err := store.Create(ctx, u)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			created = append(created, *c)
+		}
+
+		yes := true
+		after, before := day(2), day(4)
+		cases := []struct {
+			name   string
+			filter repositories.UserFilter
+			sort   []repositories.SortField
+			want   []int
+		}{
+			{"Domain", repositories.UserFilter{EmailDomain: "filtro.test"}, nil, []int{created[0].ID, created[1].ID, created[3].ID}},
+			{"RoleActive", repositories.UserFilter{EmailDomain: "filtro.test", Role: "manager", Active: &yes}, nil, []int{created[0].ID}},
+			{"CreatedRange", repositories.UserFilter{CreatedAfter: &after, CreatedBefore: &before}, nil, []int{created[1].ID, created[2].ID}},
+			{"SortByName", repositories.UserFilter{Role: "manager", CreatedAfter: &after}, []repositories.SortField{{Field: "name"}}, []int{created[1].ID, created[2].ID}},
+			{"SortByCreatedDesc", repositories.UserFilter{EmailDomain: "filtro.test"}, []repositories.SortField{{Field: "created_at", Desc: true}}, []int{created[3].ID, created[1].ID, created[0].ID}},
+		}
+		for _, tc := range cases {
+			users, total, err := store.List(ctx, repositories.UserQuery{Filter: tc.filter, Sort: tc.sort})
+			if err != nil {
+				t.Fatalf("%s: List: %v", tc.name, err)
+			}
+			got := make([]int, len(users))
+			for i, u := range users {
+				got[i] = u.ID
+			}
+			if total != len(tc.want) || fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("%s: esperado IDs %v, obtido %v (total %d)", tc.name, tc.want, got, total)
+			}
+		}
+
+		// Keyset sobre created_at percorre a listagem sem repetir registros
+		sort := []repositories.SortField{{Field: "created_at", Desc: true}}
+		filter := repositories.UserFilter{EmailDomain: "filtro.test"}
+		first, _, err := store.List(ctx, repositories.UserQuery{Filter: filter, Sort: sort, Window: repositories.Window{Limit: 2}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		last := first[len(first)-1]
+		rest, _, err := store.List(ctx, repositories.UserQuery{Filter: filter, Sort: sort, Window: repositories.Window{
+			Limit: 2,
+			After: &models.Keyset{Sort: repositories.SortKey(sort), Values: repositories.UserSortValues(last, sort), ID: last.ID},
+		}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(rest) != 1 || rest[0].ID != created[0].ID {
+			t.Errorf("List após keyset: esperado [%d], obtido %+v", created[0].ID, rest)
+		}
+	})
+
+	t.Run("EmailIsUniqueIgnoringCase", func(t *testing.T) {
+		store := newStore(t)
+
+		owner, err := store.Create(ctx, newUser("unico@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Create(ctx, newUser("UNICO@Example.com")); !errors.Is(err, repositories.ErrEmailExists) {
+			t.Errorf("Create com email repetido: esperado ErrEmailExists, obtido %v", err)
+		}
+
+		other, err := store.Create(ctx, newUser("outro@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		changed := *other
+		changed.Email = "Unico@example.com"
+		if _, err := store.Update(ctx, other.ID, changed); !errors.Is(err, repositories.ErrEmailExists) {
+			t.Errorf("Update para email em uso: esperado ErrEmailExists, obtido %v", err)
+		}
+		if _, err := store.Update(ctx, owner.ID, *owner); err != nil {
+			t.Errorf("Update mantendo o próprio email: %v", err)
+		}
+
+		// Um usuário na lixeira libera o email, mas não pode ser restaurado se ele for reutilizado
+		if err := store.Delete(ctx, owner.ID, owner.Version+1, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Create(ctx, newUser("unico@example.com")); err != nil {
+			t.Fatalf("Create após Delete: %v", err)
+		}
+		if _, err := store.Restore(ctx, owner.ID); !errors.Is(err, repositories.ErrEmailExists) {
+			t.Errorf("Restore com email reutilizado: esperado ErrEmailExists, obtido %v", err)
+		}
+	})
+
+	t.Run("ConcurrentCreatesWithSameEmail", func(t *testing.T) {
+		store := newStore(t)
+
+		const attempts = 16
+		errs := make(chan error, attempts)
+		// start libera todas as goroutines ao mesmo tempo, para que as
+		// gravações de fato concorram
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				email := "corrida@example.com"
+				if i%2 == 1 {
+					email = "Corrida@Example.com"
+				}
+				<-start
+				_, err := store.Create(ctx, newUser(email))
+				errs <- err
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+
+		created := 0
+		for err := range errs {
+			switch {
+			case err == nil:
+				created++
+			case !errors.Is(err, repositories.ErrEmailExists):
+				t.Errorf("Create concorrente: esperado ErrEmailExists, obtido %v", err)
+			}
+		}
+		if created != 1 {
+			t.Errorf("Create concorrente: esperado exatamente 1 usuário criado, obtido %d", created)
+		}
+
+		all, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		stored := 0
+		for _, u := range all {
+			if strings.EqualFold(u.Email, "corrida@example.com") {
+				stored++
+			}
+		}
+		if stored != 1 {
+			t.Errorf("GetAll: esperado 1 usuário com o email disputado, obtido %d", stored)
+		}
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.GetByID(ctx, 999999); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("GetByID: esperado ErrUserNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("update@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		changed := *created
+		changed.Name = "Nome Alterado"
+		changed.Role = "manager"
+		changed.Active = false
+
+		updated, err := store.Update(ctx, created.ID, changed)
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		changed.Version = created.Version + 1
+		if *updated != changed {
+			t.Errorf("Update: esperado %+v, obtido %+v", changed, *updated)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if *got != changed {
+			t.Errorf("GetByID após Update: esperado %+v, obtido %+v", changed, *got)
+		}
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Update(ctx, 999999, newUser("missing@example.com")); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("Update: esperado ErrUserNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("RejectsStaleVersion", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("stale@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.Version != 1 {
+			t.Fatalf("Create: esperado versão 1, obtido %d", created.Version)
+		}
+
+		if _, err := store.Update(ctx, created.ID, *created); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if _, err := store.Update(ctx, created.ID, *created); !errors.Is(err, repositories.ErrVersionConflict) {
+			t.Errorf("Update com versão antiga: esperado ErrVersionConflict, obtido %v", err)
+		}
+
+		toggled, err := store.SetActive(ctx, created.ID, false)
+		if err != nil {
+			t.Fatalf("SetActive: %v", err)
+		}
+		if toggled.Version != created.Version+2 {
+			t.Errorf("SetActive: esperado versão %d, obtido %d", created.Version+2, toggled.Version)
+		}
+
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); !errors.Is(err, repositories.ErrVersionConflict) {
+			t.Errorf("Delete com versão antiga: esperado ErrVersionConflict, obtido %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, toggled.Version, deletedAt); err != nil {
+			t.Errorf("Delete com versão atual: %v", err)
+		}
+	})
+
+	t.Run("SetActive", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("toggle@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		for _, active := range []bool{false, false, true} {
+			got, err := store.SetActive(ctx, created.ID, active)
+			if err != nil {
+				t.Fatalf("SetActive(%v): %v", active, err)
+			}
+			if got.Active != active {
+				t.Errorf("SetActive(%v): obtido active=%v", active, got.Active)
+			}
+			stored, err := store.GetByID(ctx, created.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if *stored != *got {
+				t.Errorf("GetByID após SetActive: esperado %+v, obtido %+v", *got, *stored)
+			}
+		}
+
+		if _, err := store.SetActive(ctx, 999999, true); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("SetActive: esperado ErrUserNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("DeleteMovesToTrash", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("delete@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.GetByID(ctx, created.ID); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("GetByID após Delete: esperado ErrUserNotFound, obtido %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("Delete repetido: esperado ErrUserNotFound, obtido %v", err)
+		}
+		if _, err := store.SetActive(ctx, created.ID, false); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("SetActive na lixeira: esperado ErrUserNotFound, obtido %v", err)
+		}
+
+		all, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if containsUser(all, created.ID) {
+			t.Errorf("GetAll: registro na lixeira não deveria aparecer")
+		}
+		live, _, err := store.List(ctx, repositories.UserQuery{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if containsUser(live, created.ID) {
+			t.Errorf("List: registro na lixeira não deveria aparecer")
+		}
+
+		trash, total, err := store.List(ctx, repositories.UserQuery{Filter: repositories.UserFilter{Deleted: true}})
+		if err != nil {
+			t.Fatalf("List da lixeira: %v", err)
+		}
+		if total != 1 || len(trash) != 1 || trash[0].ID != created.ID {
+			t.Fatalf("List da lixeira: esperado apenas o ID %d, obtido %+v", created.ID, trash)
+		}
+		if trash[0].DeletedAt == nil || !trash[0].DeletedAt.Equal(deletedAt) {
+			t.Errorf("List da lixeira: esperado deleted_at %v, obtido %v", deletedAt, trash[0].DeletedAt)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("restore@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Restore(ctx, created.ID); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("Restore fora da lixeira: esperado ErrUserNotFound, obtido %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		restored, err := store.Restore(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if *restored != *created {
+			t.Errorf("Restore: esperado %+v, obtido %+v", *created, *restored)
+		}
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID após Restore: %v", err)
+		}
+		if *got != *created {
+			t.Errorf("GetByID após Restore: esperado %+v, obtido %+v", *created, *got)
+		}
+		if _, err := store.Restore(ctx, 999999); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("Restore: esperado ErrUserNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("PurgeRemovesOnlyExpired", func(t *testing.T) {
+		store := newStore(t)
+
+		old, err := store.Create(ctx, newUser("purge-old@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		recent, err := store.Create(ctx, newUser("purge-new@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := store.Delete(ctx, old.ID, old.Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := store.Delete(ctx, recent.ID, recent.Version, deletedAt.Add(48*time.Hour)); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		purged, err := store.Purge(ctx, deletedAt.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("Purge: esperado 1 registro removido, obtido %d", purged)
+		}
+		if _, err := store.Restore(ctx, old.ID); !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Errorf("Restore após Purge: esperado ErrUserNotFound, obtido %v", err)
+		}
+		if _, err := store.Restore(ctx, recent.ID); err != nil {
+			t.Errorf("Restore de registro não expirado: %v", err)
+		}
+	})
+
+	t.Run("ReturnedValuesAreCopies", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newUser("copy@example.com"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		got.Name = "Alterado fora do store"
+
+		again, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if again.Name != created.Name {
+			t.Errorf("GetByID: alteração externa vazou para o store (%q)", again.Name)
+		}
+	})
+}
+
+// TestProductStore executa a suíte de conformidade contra um ProductStore
+func TestProductStore(t *testing.T, newStore ProductStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Teste", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.ID <= 0 {
+			t.Fatalf("Create: esperado ID positivo, obtido %d", created.ID)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalProducts(*got, *created) {
+			t.Errorf("GetByID: esperado %+v, obtido %+v", *created, *got)
+		}
+	})
+
+	t.Run("GetAllIncludesCreated", func(t *testing.T) {
+		store := newStore(t)
+
+		before, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		created, err := store.Create(ctx, newProduct("Produto Lista", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		after, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(after) != len(before)+1 {
+			t.Fatalf("GetAll: esperado %d produtos, obtido %d", len(before)+1, len(after))
+		}
+		if !containsProduct(after, created.ID) {
+			t.Errorf("GetAll: produto %d ausente", created.ID)
+		}
+	})
+
+	t.Run("ListByCategory", func(t *testing.T) {
+		store := newStore(t)
+
+		inCategory, err := store.Create(ctx, newProduct("Na Categoria", "Categoria Conformidade"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		other, err := store.Create(ctx, newProduct("Fora da Categoria", "Outra Categoria"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		products, total, err := store.List(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{Category: "Categoria Conformidade"}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != len(products) {
+			t.Errorf("List: total %d diferente da quantidade retornada %d", total, len(products))
+		}
+		if !containsProduct(products, inCategory.ID) {
+			t.Errorf("List: produto %d ausente", inCategory.ID)
+		}
+		if containsProduct(products, other.ID) {
+			t.Errorf("List: produto %d de outra categoria retornado", other.ID)
+		}
+
+		empty, total, err := store.List(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{Category: "Categoria Inexistente"}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(empty) != 0 || total != 0 {
+			t.Errorf("List: esperado nenhum produto, obtido %d (total %d)", len(empty), total)
+		}
+	})
+
+	t.Run("ListByCategoryIDs", func(t *testing.T) {
+		store := newStore(t)
+
+		var ids []int
+		for i, categoryID := range []int{901, 902, 903} {
+			product := newProduct(fmt.Sprintf("Produto Categoria %d", i), "Testes")
+			product.CategoryID = categoryID
+			created, err := store.Create(ctx, product, stockChange(reservedAt))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids = append(ids, created.ID)
+		}
+
+		products, total, err := store.List(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{CategoryIDs: []int{901, 903}}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		assertIDs(t, "List", products, []int{ids[0], ids[2]})
+		if total != 2 {
+			t.Errorf("List: esperado total 2, obtido %d", total)
+		}
+
+		empty, total, err := store.List(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{CategoryIDs: []int{}}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(empty) != 0 || total != 0 {
+			t.Errorf("List com lista vazia: esperado nenhum produto, obtido %d (total %d)", len(empty), total)
+		}
+	})
+
+	t.Run("CountAndRenameCategory", func(t *testing.T) {
+		store := newStore(t)
+
+		var created []*models.Product
+		for i := 0; i < 3; i++ {
+			product := newProduct(fmt.Sprintf("Produto Contado %d", i), "Nome Antigo")
+			product.CategoryID = 911
+			p, err := store.Create(ctx, product, stockChange(reservedAt))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			created = append(created, p)
+		}
+		if err := store.Delete(ctx, created[2].ID, created[2].Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		counts, err := store.CountByCategory(ctx, false)
+		if err != nil {
+			t.Fatalf("CountByCategory: %v", err)
+		}
+		if counts[911] != 2 {
+			t.Errorf("CountByCategory: esperado 2 produtos fora da lixeira, obtido %d", counts[911])
+		}
+		if counts, err = store.CountByCategory(ctx, true); err != nil || counts[911] != 3 {
+			t.Errorf("CountByCategory com lixeira: esperado 3 produtos, obtido %d (%v)", counts[911], err)
+		}
+
+		if err := store.RenameCategory(ctx, 911, "Nome Novo"); err != nil {
+			t.Fatalf("RenameCategory: %v", err)
+		}
+		got, err := store.GetByID(ctx, created[0].ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Category != "Nome Novo" || got.CategoryID != 911 {
+			t.Errorf("RenameCategory: esperado categoria 911 \"Nome Novo\", obtido %d %q", got.CategoryID, got.Category)
+		}
+		products, _, err := store.List(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{Category: "Nome Novo"}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		assertIDs(t, "List pelo novo nome", products, []int{created[0].ID, created[1].ID})
+	})
+
+	t.Run("ListPaginates", func(t *testing.T) {
+		store := newStore(t)
+
+		for i := 0; i < 5; i++ {
+			if _, err := store.Create(ctx, newProduct(fmt.Sprintf("Paginado %d", i), "Categoria Paginada"), stockChange(reservedAt)); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		var seen []int
+		for page := 1; page <= 3; page++ {
+			query := repositories.ProductQuery{
+				Filter: repositories.ProductFilter{Category: "Categoria Paginada"},
+				Window: repositories.Window{Offset: (page - 1) * 2, Limit: 2},
+			}
+			products, total, err := store.List(ctx, query)
+			if err != nil {
+				t.Fatalf("List página %d: %v", page, err)
+			}
+			if total != 5 {
+				t.Errorf("List página %d: esperado total 5, obtido %d", page, total)
+			}
+			for _, p := range products {
+				seen = append(seen, p.ID)
+			}
+		}
+		assertAscendingUnique(t, seen, 5)
+
+		beyond, _, err := store.List(ctx, repositories.ProductQuery{
+			Filter: repositories.ProductFilter{Category: "Categoria Paginada"},
+			Window: repositories.Window{Offset: 18, Limit: 2},
+		})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(beyond) != 0 {
+			t.Errorf("List além da última página: esperado vazio, obtido %d", len(beyond))
+		}
+	})
+
+	t.Run("ListAfterKeysetIsStableUnderDeletes", func(t *testing.T) {
+		store := newStore(t)
+
+		var ids []int
+		for i := 0; i < 5; i++ {
+			created, err := store.Create(ctx, newProduct(fmt.Sprintf("Keyset %d", i), "Categoria Keyset"), stockChange(reservedAt))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids = append(ids, created.ID)
+		}
+
+		first, _, err := store.List(ctx, repositories.ProductQuery{
+			Filter: repositories.ProductFilter{Category: "Categoria Keyset"},
+			Window: repositories.Window{Limit: 2},
+		})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(first) != 2 {
+			t.Fatalf("List: esperado 2 produtos, obtido %d", len(first))
+		}
+
+		// Remover um item já visto não pode fazer a próxima página pular ou repetir registros
+		if err := store.Delete(ctx, first[0].ID, first[0].Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		second, total, err := store.List(ctx, repositories.ProductQuery{
+			Filter: repositories.ProductFilter{Category: "Categoria Keyset"},
+			Window: repositories.Window{Limit: 2, After: &models.Keyset{Sort: "id", ID: first[1].ID}},
+		})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 4 {
+			t.Errorf("List: esperado total 4, obtido %d", total)
+		}
+		if len(second) != 2 || second[0].ID != ids[2] || second[1].ID != ids[3] {
+			t.Errorf("List após keyset: esperado IDs %v, obtido %+v", ids[2:4], second)
+		}
+	})
+
+	t.Run("ListFilters", func(t *testing.T) {
+		store := newStore(t)
+
+		cheap := newProduct("Barato", "Categoria Filtro")
+		cheap.Price = brl(1000)
+		expensive := newProduct("Caro", "Categoria Filtro")
+		expensive.Price = brl(100000)
+		outOfStock := newProduct("Sem Estoque", "Categoria Filtro")
+		outOfStock.Price = brl(5000)
+		outOfStock.Stock = 0
+		inactive := newProduct("Inativo", "Categoria Filtro")
+		inactive.Price = brl(5000)
+		inactive.Active = false
+		dollar := newProduct("Dólar", "Categoria Filtro")
+		dollar.Price = models.Money{Amount: 5000, Currency: "USD"}
+
+		ids := make(map[string]int)
+		for _, p := range []models.Product{cheap, expensive, outOfStock, inactive, dollar} {
+			created, err := store.Create(ctx, p, stockChange(reservedAt))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids[p.Name] = created.ID
+		}
+
+		yes, no := true, false
+		var minPrice, maxPrice int64 = 2000, 10000
+		cases := []struct {
+			name   string
+			filter repositories.ProductFilter
+			want   []string
+		}{
+			{"Active", repositories.ProductFilter{Active: &no}, []string{"Inativo"}},
+			{"InStock", repositories.ProductFilter{InStock: &no}, []string{"Sem Estoque"}},
+			{"Currency", repositories.ProductFilter{Currency: "USD"}, []string{"Dólar"}},
+			{"PriceRange", repositories.ProductFilter{Currency: "BRL", MinPrice: &minPrice, MaxPrice: &maxPrice}, []string{"Sem Estoque", "Inativo"}},
+			{"Combined", repositories.ProductFilter{Currency: "BRL", Active: &yes, InStock: &yes, MaxPrice: &maxPrice}, []string{"Barato"}},
+		}
+		for _, tc := range cases {
+			tc.filter.Category = "Categoria Filtro"
+			products, total, err := store.List(ctx, repositories.ProductQuery{Filter: tc.filter})
+			if err != nil {
+				t.Fatalf("%s: List: %v", tc.name, err)
+			}
+			if total != len(tc.want) || len(products) != len(tc.want) {
+				t.Errorf("%s: esperado %d produtos, obtido %d (total %d)", tc.name, len(tc.want), len(products), total)
+				continue
+			}
+			for i, name := range tc.want {
+				if products[i].ID != ids[name] {
+					t.Errorf("%s: posição %d esperava %q, obtido %q", tc.name, i, name, products[i].Name)
+				}
+			}
+		}
+	})
+
+	t.Run("ListSortsWithKeyset", func(t *testing.T) {
+		store := newStore(t)
+
+		// Preços repetidos exercitam o desempate por nome e por ID
+		specs := []struct {
+			name  string
+			price int64
+		}{
+			{"B", 30}, {"A", 30}, {"C", 10}, {"A", 30}, {"D", 50},
+		}
+		var created []models.Product
+		for _, spec := range specs {
+			p := newProduct(spec.name, "Categoria Ordenada")
+			p.Price = brl(spec.price)
+			c, err := store.Create(ctx, p, stockChange(reservedAt))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			created = append(created, *c)
+		}
+		want := []int{created[4].ID, created[1].ID, created[3].ID, created[0].ID, created[2].ID}
+
+		sort := []repositories.SortField{{Field: "price", Desc: true}, {Field: "name"}}
+		filter := repositories.ProductFilter{Category: "Categoria Ordenada"}
+
+		all, _, err := store.List(ctx, repositories.ProductQuery{Filter: filter, Sort: sort})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		assertIDs(t, "List ordenado", all, want)
+
+		var walked []models.Product
+		var after *models.Keyset
+		for {
+			page, _, err := store.List(ctx, repositories.ProductQuery{
+				Filter: filter,
+				Sort:   sort,
+				Window: repositories.Window{Limit: 2, After: after},
+			})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			walked = append(walked, page...)
+			last := page[len(page)-1]
+			after = &models.Keyset{
+				Sort:   repositories.SortKey(sort),
+				Values: []interface{}{last.Price.Amount, last.Name},
+				ID:     last.ID,
+			}
+		}
+		assertIDs(t, "List por keyset", walked, want)
+	})
+
+	t.Run("SearchNormalizesAndRanks", func(t *testing.T) {
+		store := newStore(t)
+
+		inName := newProduct("Zumbificador Elétrico", "Brinquedos")
+		inCategory := newProduct("Caixa", "Zumbificadores")
+		inDescription := newProduct("Cabo", "Acessórios")
+		inDescription.Description = "Compatível com o zumbificador"
+		unrelated := newProduct("Cadeira", "Móveis")
+
+		ids := make(map[string]int)
+		for _, p := range []models.Product{inName, inCategory, inDescription, unrelated} {
+			created, err := store.Create(ctx, p, stockChange(reservedAt))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids[p.Name] = created.ID
+		}
+
+		results, total, err := store.Search(ctx, "ZUMBIFICADÔR", repositories.ProductFilter{}, repositories.Window{})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if total != 3 || len(results) != 3 {
+			t.Fatalf("Search: esperado 3 resultados, obtido %d (total %d)", len(results), total)
+		}
+		if results[0].Product.ID != ids[inName.Name] {
+			t.Errorf("Search: esperado %q em primeiro, obtido %q", inName.Name, results[0].Product.Name)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Score > results[i-1].Score {
+				t.Errorf("Search: resultados fora de ordem de relevância: %+v", results)
+			}
+		}
+
+		// O índice deve acompanhar atualizações e remoções (registros recém-criados estão na versão 1)
+		renamed := inName
+		renamed.ID = ids[inName.Name]
+		renamed.Version = 1
+		renamed.Name = "Teclado"
+		renamed.Description = ""
+		if _, err := store.Update(ctx, renamed.ID, renamed, stockChange(reservedAt)); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := store.Delete(ctx, ids[inCategory.Name], 1, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		results, total, err = store.Search(ctx, "zumbificador", repositories.ProductFilter{}, repositories.Window{})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if total != 1 || len(results) != 1 || results[0].Product.ID != ids[inDescription.Name] {
+			t.Errorf("Search após alterações: esperado apenas %q, obtido %+v", inDescription.Name, results)
+		}
+	})
+
+	t.Run("SearchSpansManyHits", func(t *testing.T) {
+		store := newStore(t)
+
+		// mais produtos que um lote de leitura do SQLite
+		const count = 600
+		for i := 0; i < count; i++ {
+			if _, err := store.Create(ctx, newProduct(fmt.Sprintf("Xilogravura %03d", i), "Testes"), stockChange(reservedAt)); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		results, total, err := store.Search(ctx, "xilogravura", repositories.ProductFilter{}, repositories.Window{Offset: count - 10, Limit: 20})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if total != count || len(results) != 10 {
+			t.Fatalf("Search: esperado total %d e 10 resultados, obtido total %d e %d resultados", count, total, len(results))
+		}
+		seen := make(map[int]bool)
+		all, _, err := store.Search(ctx, "xilogravura", repositories.ProductFilter{}, repositories.Window{})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		for _, r := range all {
+			seen[r.Product.ID] = true
+		}
+		if len(all) != count || len(seen) != count {
+			t.Errorf("Search sem janela: esperado %d produtos distintos, obtido %d (%d distintos)", count, len(all), len(seen))
+		}
+	})
+
+	t.Run("SearchFoldsAccentsAndCase", func(t *testing.T) {
+		store := newStore(t)
+
+		vase, err := store.Create(ctx, newProduct("Ânfora Rústica", "Cerâmicas"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		pendulum, err := store.Create(ctx, newProduct("PÊNDULO NÁUTICO", "Decoração"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		for query, want := range map[string]int{
+			"anfora":    vase.ID,
+			"ÂNFORA":    vase.ID,
+			"rustica":   vase.ID,
+			"CERAMICAS": vase.ID,
+			"pêndulo":   pendulum.ID,
+			"nautico":   pendulum.ID,
+			"decoracao": pendulum.ID,
+		} {
+			results, total, err := store.Search(ctx, query, repositories.ProductFilter{}, repositories.Window{})
+			if err != nil {
+				t.Fatalf("Search(%q): %v", query, err)
+			}
+			if total != 1 || len(results) != 1 || results[0].Product.ID != want {
+				t.Errorf("Search(%q): esperado apenas o produto %d, obtido %+v", query, want, results)
+			}
+		}
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.GetByID(ctx, 999999); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("GetByID: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Original", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		changed := *created
+		changed.Name = "Produto Alterado"
+		changed.Price = models.Money{Amount: 4250, Currency: "USD"}
+		changed.Stock = 0
+		changed.Active = false
+
+		updated, err := store.Update(ctx, created.ID, changed, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		changed.Version = created.Version + 1
+		if !equalProducts(*updated, changed) {
+			t.Errorf("Update: esperado %+v, obtido %+v", changed, *updated)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !equalProducts(*got, changed) {
+			t.Errorf("GetByID após Update: esperado %+v, obtido %+v", changed, *got)
+		}
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Update(ctx, 999999, newProduct("Inexistente", "Testes"), stockChange(reservedAt)); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("Update: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("RejectsStaleVersion", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Concorrente", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.Version != 1 {
+			t.Fatalf("Create: esperado versão 1, obtido %d", created.Version)
+		}
+
+		if _, err := store.Update(ctx, created.ID, *created, stockChange(reservedAt)); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if _, err := store.Update(ctx, created.ID, *created, stockChange(reservedAt)); !errors.Is(err, repositories.ErrVersionConflict) {
+			t.Errorf("Update com versão antiga: esperado ErrVersionConflict, obtido %v", err)
+		}
+
+		toggled, err := store.SetActive(ctx, created.ID, false)
+		if err != nil {
+			t.Fatalf("SetActive: %v", err)
+		}
+		if toggled.Version != created.Version+2 {
+			t.Errorf("SetActive: esperado versão %d, obtido %d", created.Version+2, toggled.Version)
+		}
+
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); !errors.Is(err, repositories.ErrVersionConflict) {
+			t.Errorf("Delete com versão antiga: esperado ErrVersionConflict, obtido %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, toggled.Version, deletedAt); err != nil {
+			t.Errorf("Delete com versão atual: %v", err)
+		}
+	})
+
+	t.Run("SetActive", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Alternado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		for _, active := range []bool{false, false, true} {
+			got, err := store.SetActive(ctx, created.ID, active)
+			if err != nil {
+				t.Fatalf("SetActive(%v): %v", active, err)
+			}
+			if got.Active != active {
+				t.Errorf("SetActive(%v): obtido active=%v", active, got.Active)
+			}
+			stored, err := store.GetByID(ctx, created.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if !equalProducts(*stored, *got) {
+				t.Errorf("GetByID após SetActive: esperado %+v, obtido %+v", *got, *stored)
+			}
+		}
+
+		if _, err := store.SetActive(ctx, 999999, true); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("SetActive: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("DeleteMovesToTrash", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Removido", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.GetByID(ctx, created.ID); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("GetByID após Delete: esperado ErrProductNotFound, obtido %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("Delete repetido: esperado ErrProductNotFound, obtido %v", err)
+		}
+		if _, err := store.SetActive(ctx, created.ID, false); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("SetActive na lixeira: esperado ErrProductNotFound, obtido %v", err)
+		}
+
+		all, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if containsProduct(all, created.ID) {
+			t.Errorf("GetAll: registro na lixeira não deveria aparecer")
+		}
+		live, _, err := store.List(ctx, repositories.ProductQuery{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if containsProduct(live, created.ID) {
+			t.Errorf("List: registro na lixeira não deveria aparecer")
+		}
+
+		trash, total, err := store.List(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{Deleted: true}})
+		if err != nil {
+			t.Fatalf("List da lixeira: %v", err)
+		}
+		if total != 1 || len(trash) != 1 || trash[0].ID != created.ID {
+			t.Fatalf("List da lixeira: esperado apenas o ID %d, obtido %+v", created.ID, trash)
+		}
+		if trash[0].DeletedAt == nil || !trash[0].DeletedAt.Equal(deletedAt) {
+			t.Errorf("List da lixeira: esperado deleted_at %v, obtido %v", deletedAt, trash[0].DeletedAt)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Restaurado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Restore(ctx, created.ID); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("Restore fora da lixeira: esperado ErrProductNotFound, obtido %v", err)
+		}
+		if err := store.Delete(ctx, created.ID, created.Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		restored, err := store.Restore(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if !equalProducts(*restored, *created) {
+			t.Errorf("Restore: esperado %+v, obtido %+v", *created, *restored)
+		}
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID após Restore: %v", err)
+		}
+		if !equalProducts(*got, *created) {
+			t.Errorf("GetByID após Restore: esperado %+v, obtido %+v", *created, *got)
+		}
+		if _, err := store.Restore(ctx, 999999); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("Restore: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("PurgeRemovesOnlyExpired", func(t *testing.T) {
+		store := newStore(t)
+
+		old, err := store.Create(ctx, newProduct("Produto Expurgado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		recent, err := store.Create(ctx, newProduct("Produto Recente", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := store.Delete(ctx, old.ID, old.Version, deletedAt); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := store.Delete(ctx, recent.ID, recent.Version, deletedAt.Add(48*time.Hour)); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		purged, err := store.Purge(ctx, deletedAt.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("Purge: esperado 1 registro removido, obtido %d", purged)
+		}
+		if _, err := store.Restore(ctx, old.ID); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("Restore após Purge: esperado ErrProductNotFound, obtido %v", err)
+		}
+		if _, err := store.Restore(ctx, recent.ID); err != nil {
+			t.Fatalf("Restore de registro não expirado: %v", err)
+		}
+		if _, total, err := store.ListMovements(ctx, recent.ID, repositories.Window{}); err != nil || total != 1 {
+			t.Errorf("ListMovements do produto mantido: esperado 1 movimentação, obtido %d (%v)", total, err)
+		}
+	})
+
+	t.Run("AdjustStock", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Estoque", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		added, err := store.AdjustStock(ctx, created.ID, 5, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("AdjustStock: %v", err)
+		}
+		if added.Stock != 15 || added.Version != created.Version+1 {
+			t.Errorf("AdjustStock: esperado estoque 15 na versão %d, obtido %d na versão %d", created.Version+1, added.Stock, added.Version)
+		}
+		if _, err := store.AdjustStock(ctx, created.ID, -16, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("AdjustStock além do estoque: esperado ErrInsufficientStock, obtido %v", err)
+		}
+		removed, err := store.AdjustStock(ctx, created.ID, -15, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("AdjustStock: %v", err)
+		}
+		if removed.Stock != 0 {
+			t.Errorf("AdjustStock: esperado estoque 0, obtido %d", removed.Stock)
+		}
+		if _, err := store.AdjustStock(ctx, 999999, 1, stockChange(reservedAt)); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("AdjustStock: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("StockNeverOverflows", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Lotado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		full, err := store.AdjustStock(ctx, created.ID, math.MaxInt-created.Stock, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("AdjustStock até o limite: %v", err)
+		}
+		if full.Stock != math.MaxInt {
+			t.Errorf("AdjustStock: esperado estoque %d, obtido %d", math.MaxInt, full.Stock)
+		}
+		for _, delta := range []int{1, math.MaxInt} {
+			if _, err := store.AdjustStock(ctx, created.ID, delta, stockChange(reservedAt)); !errors.Is(err, repositories.ErrStockOverflow) {
+				t.Errorf("AdjustStock(%d) além do limite: esperado ErrStockOverflow, obtido %v", delta, err)
+			}
+		}
+		product, err := store.AdjustStock(ctx, created.ID, -1, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("AdjustStock: %v", err)
+		}
+		if product.Stock != math.MaxInt-1 || product.Version != full.Version+1 {
+			t.Errorf("AdjustStock: esperado estoque %d na versão %d, obtido %d na versão %d", math.MaxInt-1, full.Version+1, product.Stock, product.Version)
+		}
+		assertLedgerMatchesStock(t, store, product)
+
+		optioned, err := store.Create(ctx, newOptionedProduct("Produto Variado Lotado"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		red, err := store.CreateVariant(ctx, newVariant(optioned.ID, "LOT-VERMELHO", "Vermelho", 4), optioned.Version, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		blue, err := store.CreateVariant(ctx, newVariant(optioned.ID, "LOT-AZUL", "Azul", 0), optioned.Version+1, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		if _, err := store.AdjustVariantStock(ctx, optioned.ID, red.ID, math.MaxInt-red.Stock, stockChange(reservedAt)); err != nil {
+			t.Fatalf("AdjustVariantStock até o limite: %v", err)
+		}
+		// o limite vale para o estoque do produto, que soma o das variantes
+		if _, err := store.AdjustVariantStock(ctx, optioned.ID, blue.ID, 1, stockChange(reservedAt)); !errors.Is(err, repositories.ErrStockOverflow) {
+			t.Errorf("AdjustVariantStock além do limite do produto: esperado ErrStockOverflow, obtido %v", err)
+		}
+		product, err = store.GetByID(ctx, optioned.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Stock != math.MaxInt {
+			t.Errorf("AdjustVariantStock: esperado estoque %d, obtido %d", math.MaxInt, product.Stock)
+		}
+		variant, err := store.GetVariant(ctx, optioned.ID, blue.ID)
+		if err != nil {
+			t.Fatalf("GetVariant: %v", err)
+		}
+		if variant.Stock != 0 {
+			t.Errorf("AdjustVariantStock recusado: esperado estoque 0 na variante, obtido %d", variant.Stock)
+		}
+		assertLedgerMatchesStock(t, store, product)
+	})
+
+	t.Run("ConcurrentDecrementsNeverOversell", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Disputado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		const attempts = 50
+		errs := make(chan error, attempts)
+		var wg sync.WaitGroup
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := store.AdjustStock(ctx, created.ID, -1, stockChange(reservedAt))
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		assertStockOutcome(t, "AdjustStock concorrente", errs, created.Stock)
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Stock != 0 {
+			t.Errorf("AdjustStock concorrente: esperado estoque 0, obtido %d", got.Stock)
+		}
+		assertLedgerMatchesStock(t, store, got)
+	})
+
+	t.Run("ConcurrentReservationsNeverOversell", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Reservado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		const attempts = 50
+		errs := make(chan error, attempts)
+		var wg sync.WaitGroup
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := store.Reserve(ctx, newReservation(created.ID, 1))
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		assertStockOutcome(t, "Reserve concorrente", errs, created.Stock)
+		if _, err := store.AdjustStock(ctx, created.ID, -1, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("AdjustStock com todo o estoque reservado: esperado ErrInsufficientStock, obtido %v", err)
+		}
+	})
+
+	t.Run("ReservationLifecycle", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Ciclo", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		reservation, err := store.Reserve(ctx, newReservation(created.ID, 8))
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+		if reservation.ID == 0 || reservation.ProductID != created.ID || reservation.Quantity != 8 {
+			t.Errorf("Reserve: reserva inesperada %+v", reservation)
+		}
+		assertReserved(t, store, created.ID, 0, reservedAt, 8)
+		assertReserved(t, store, created.ID, 0, reservation.ExpiresAt, 0)
+		if _, err := store.Reserve(ctx, newReservation(created.ID, 3)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("Reserve além do disponível: esperado ErrInsufficientStock, obtido %v", err)
+		}
+		if _, err := store.AdjustStock(ctx, created.ID, -3, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("AdjustStock sobre estoque reservado: esperado ErrInsufficientStock, obtido %v", err)
+		}
+
+		got, err := store.GetReservation(ctx, reservation.ID, reservedAt)
+		if err != nil {
+			t.Fatalf("GetReservation: %v", err)
+		}
+		if got.Quantity != 8 || !got.ExpiresAt.Equal(reservation.ExpiresAt) {
+			t.Errorf("GetReservation: esperado %+v, obtido %+v", reservation, got)
+		}
+
+		if err := store.ReleaseReservation(ctx, reservation.ID, reservedAt); err != nil {
+			t.Fatalf("ReleaseReservation: %v", err)
+		}
+		if err := store.ReleaseReservation(ctx, reservation.ID, reservedAt); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("ReleaseReservation repetido: esperado ErrReservationNotFound, obtido %v", err)
+		}
+		assertReserved(t, store, created.ID, 0, reservedAt, 0)
+		if _, err := store.CommitReservation(ctx, reservation.ID, stockChange(reservedAt)); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("CommitReservation após liberar: esperado ErrReservationNotFound, obtido %v", err)
+		}
+
+		reservation, err = store.Reserve(ctx, newReservation(created.ID, 4))
+		if err != nil {
+			t.Fatalf("Reserve após liberar: %v", err)
+		}
+		product, err := store.CommitReservation(ctx, reservation.ID, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CommitReservation: %v", err)
+		}
+		if product.Stock != 6 || product.Version != created.Version+1 {
+			t.Errorf("CommitReservation: esperado estoque 6 na versão %d, obtido %d na versão %d", created.Version+1, product.Stock, product.Version)
+		}
+		if _, err := store.GetReservation(ctx, reservation.ID, reservedAt); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("GetReservation após confirmar: esperado ErrReservationNotFound, obtido %v", err)
+		}
+		if _, err := store.CommitReservation(ctx, reservation.ID, stockChange(reservedAt)); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("CommitReservation repetido: esperado ErrReservationNotFound, obtido %v", err)
+		}
+		if _, err := store.Reserve(ctx, newReservation(999999, 1)); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("Reserve: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("UpdateRespectsReservations", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Reservado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		reservation, err := store.Reserve(ctx, newReservation(created.ID, 8))
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+
+		changed := *created
+		changed.Stock = 7
+		if _, err := store.Update(ctx, created.ID, changed, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("Update abaixo do estoque reservado: esperado ErrInsufficientStock, obtido %v", err)
+		}
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Stock != created.Stock || got.Version != created.Version {
+			t.Errorf("Update rejeitado alterou o produto: esperado estoque %d na versão %d, obtido %d na versão %d", created.Stock, created.Version, got.Stock, got.Version)
+		}
+
+		changed.Stock = 8
+		if _, err := store.Update(ctx, created.ID, changed, stockChange(reservedAt)); err != nil {
+			t.Fatalf("Update até o estoque reservado: %v", err)
+		}
+		product, err := store.CommitReservation(ctx, reservation.ID, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CommitReservation após o Update: %v", err)
+		}
+		if product.Stock != 0 {
+			t.Errorf("CommitReservation: esperado estoque 0, obtido %d", product.Stock)
+		}
+
+		other, err := store.Create(ctx, newProduct("Produto Reserva Vencida", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		expiring, err := store.Reserve(ctx, newReservation(other.ID, 10))
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+		emptied := *other
+		emptied.Stock = 0
+		if _, err := store.Update(ctx, other.ID, emptied, stockChange(expiring.ExpiresAt)); err != nil {
+			t.Errorf("Update após expirar a reserva: %v", err)
+		}
+	})
+
+	t.Run("ExpiredReservationsAreIgnored", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Expirado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		reservation, err := store.Reserve(ctx, newReservation(created.ID, 10))
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+
+		expired := reservation.ExpiresAt
+		if _, err := store.GetReservation(ctx, reservation.ID, expired); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("GetReservation expirada: esperado ErrReservationNotFound, obtido %v", err)
+		}
+		if _, err := store.CommitReservation(ctx, reservation.ID, stockChange(expired)); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("CommitReservation expirada: esperado ErrReservationNotFound, obtido %v", err)
+		}
+		if err := store.ReleaseReservation(ctx, reservation.ID, expired); !errors.Is(err, repositories.ErrReservationNotFound) {
+			t.Errorf("ReleaseReservation expirada: esperado ErrReservationNotFound, obtido %v", err)
+		}
+		product, err := store.AdjustStock(ctx, created.ID, -10, stockChange(expired))
+		if err != nil {
+			t.Fatalf("AdjustStock após expirar a reserva: %v", err)
+		}
+		if product.Stock != 0 {
+			t.Errorf("AdjustStock: esperado estoque 0, obtido %d", product.Stock)
+		}
+	})
+
+	t.Run("StockChangesAreRecorded", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Histórico", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		changed := *created
+		changed.Stock = 7
+		updated, err := store.Update(ctx, created.ID, changed, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		renamed := *updated
+		renamed.Name = "Produto Histórico Renomeado"
+		if _, err := store.Update(ctx, created.ID, renamed, stockChange(reservedAt)); err != nil {
+			t.Fatalf("Update sem alterar o estoque: %v", err)
+		}
+		purchase := repositories.StockChange{Type: models.MovementPurchase, Reason: "nota 123", Actor: "compras", At: reservedAt.Add(time.Minute)}
+		if _, err := store.AdjustStock(ctx, created.ID, 5, purchase); err != nil {
+			t.Fatalf("AdjustStock: %v", err)
+		}
+		if _, err := store.AdjustStock(ctx, created.ID, -100, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Fatalf("AdjustStock além do estoque: esperado ErrInsufficientStock, obtido %v", err)
+		}
+		reservation, err := store.Reserve(ctx, newReservation(created.ID, 2))
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+		product, err := store.CommitReservation(ctx, reservation.ID, repositories.StockChange{Type: models.MovementSale, Actor: "loja", At: reservedAt})
+		if err != nil {
+			t.Fatalf("CommitReservation: %v", err)
+		}
+
+		movements, total, err := store.ListMovements(ctx, created.ID, repositories.Window{})
+		if err != nil {
+			t.Fatalf("ListMovements: %v", err)
+		}
+		wantQuantities := []int{10, -3, 5, -2}
+		wantBalances := []int{10, 7, 12, 10}
+		if total != len(wantQuantities) || len(movements) != len(wantQuantities) {
+			t.Fatalf("ListMovements: esperado %d movimentações, obtido %d (total %d)", len(wantQuantities), len(movements), total)
+		}
+		for i, m := range movements {
+			if m.ProductID != created.ID || m.Quantity != wantQuantities[i] || m.Balance != wantBalances[i] {
+				t.Errorf("ListMovements[%d]: esperado quantidade %d e saldo %d, obtido %+v", i, wantQuantities[i], wantBalances[i], m)
+			}
+			if i > 0 && m.ID <= movements[i-1].ID {
+				t.Errorf("ListMovements: IDs fora de ordem cronológica: %d após %d", m.ID, movements[i-1].ID)
+			}
+		}
+		if m := movements[2]; m.Type != models.MovementPurchase || m.Reason != "nota 123" || m.Actor != "compras" || !m.CreatedAt.Equal(purchase.At) {
+			t.Errorf("ListMovements: movimentação de compra inesperada %+v", m)
+		}
+		if m := movements[3]; m.Type != models.MovementSale || m.Actor != "loja" {
+			t.Errorf("ListMovements: movimentação de venda inesperada %+v", m)
+		}
+		assertLedgerMatchesStock(t, store, product)
+
+		page, total, err := store.ListMovements(ctx, created.ID, repositories.Window{Offset: 1, Limit: 2})
+		if err != nil {
+			t.Fatalf("ListMovements paginado: %v", err)
+		}
+		if total != 4 || len(page) != 2 || page[0].ID != movements[1].ID {
+			t.Errorf("ListMovements paginado: esperado a 2ª e a 3ª movimentações de 4, obtido %+v (total %d)", page, total)
+		}
+		after, _, err := store.ListMovements(ctx, created.ID, repositories.Window{After: &models.Keyset{ID: movements[2].ID}})
+		if err != nil {
+			t.Fatalf("ListMovements após keyset: %v", err)
+		}
+		if len(after) != 1 || after[0].ID != movements[3].ID {
+			t.Errorf("ListMovements após keyset: esperado apenas a última movimentação, obtido %+v", after)
+		}
+		if _, _, err := store.ListMovements(ctx, 999999, repositories.Window{}); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("ListMovements: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("PriceChangesAreRecorded", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Preço", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		renamed := *created
+		renamed.Name = "Produto Preço Renomeado"
+		updated, err := store.Update(ctx, created.ID, renamed, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Update sem alterar o preço: %v", err)
+		}
+		repriced := *updated
+		repriced.Price = brl(17990)
+		change := repositories.StockChange{Type: models.MovementAdjustment, Reason: "promoção", Actor: "marketing", At: reservedAt.Add(time.Minute)}
+		if _, err := store.Update(ctx, created.ID, repriced, change); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		changes, total, err := store.ListPriceChanges(ctx, created.ID, repositories.Window{})
+		if err != nil {
+			t.Fatalf("ListPriceChanges: %v", err)
+		}
+		if total != 2 || len(changes) != 2 {
+			t.Fatalf("ListPriceChanges: esperado 2 alterações, obtido %+v (total %d)", changes, total)
+		}
+		if c := changes[0]; c.Price != brl(19990) || c.Previous != nil || c.Actor != "storetest" {
+			t.Errorf("ListPriceChanges: preço inicial inesperado %+v", c)
+		}
+		if c := changes[1]; c.Price != brl(17990) || c.Previous == nil || *c.Previous != brl(19990) ||
+			c.Reason != "promoção" || c.Actor != "marketing" || !c.CreatedAt.Equal(change.At) || c.ScheduleID != 0 {
+			t.Errorf("ListPriceChanges: alteração inesperada %+v", c)
+		}
+		page, _, err := store.ListPriceChanges(ctx, created.ID, repositories.Window{After: &models.Keyset{ID: changes[0].ID}})
+		if err != nil {
+			t.Fatalf("ListPriceChanges após keyset: %v", err)
+		}
+		if len(page) != 1 || page[0].ID != changes[1].ID {
+			t.Errorf("ListPriceChanges após keyset: esperado apenas a última alteração, obtido %+v", page)
+		}
+		if _, _, err := store.ListPriceChanges(ctx, 999999, repositories.Window{}); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("ListPriceChanges: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("PriceSchedules", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Agendado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		friday := reservedAt.Add(24 * time.Hour)
+		sunday := friday.Add(48 * time.Hour)
+		sale, err := store.CreatePriceSchedule(ctx, newSchedule(created.ID, brl(14990), friday, &sunday))
+		if err != nil {
+			t.Fatalf("CreatePriceSchedule: %v", err)
+		}
+		if sale.ID == 0 || sale.Status != models.ScheduleScheduled || !sale.StartsAt.Equal(friday) || sale.EndsAt == nil || !sale.EndsAt.Equal(sunday) {
+			t.Errorf("CreatePriceSchedule: agendamento inesperado %+v", sale)
+		}
+		if _, err := store.CreatePriceSchedule(ctx, newSchedule(created.ID, brl(9990), friday.Add(time.Hour), nil)); !errors.Is(err, repositories.ErrScheduleOverlap) {
+			t.Errorf("CreatePriceSchedule dentro de outro período: esperado ErrScheduleOverlap, obtido %v", err)
+		}
+		later, err := store.CreatePriceSchedule(ctx, newSchedule(created.ID, brl(21990), sunday, nil))
+		if err != nil {
+			t.Fatalf("CreatePriceSchedule no término de outro: %v", err)
+		}
+
+		next, err := store.NextPriceScheduleAt(ctx)
+		if err != nil {
+			t.Fatalf("NextPriceScheduleAt: %v", err)
+		}
+		if next == nil || !next.Equal(friday) {
+			t.Errorf("NextPriceScheduleAt: esperado %v, obtido %v", friday, next)
+		}
+		if applied, err := store.ApplyPriceSchedules(ctx, friday.Add(-time.Second)); err != nil || applied != 0 {
+			t.Errorf("ApplyPriceSchedules antes do início: esperado 0, obtido %d (%v)", applied, err)
+		}
+		if applied, err := store.ApplyPriceSchedules(ctx, friday.Add(time.Minute)); err != nil || applied != 1 {
+			t.Fatalf("ApplyPriceSchedules no início: esperado 1, obtido %d (%v)", applied, err)
+		}
+		product, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Price != brl(14990) || product.Version != created.Version+1 {
+			t.Errorf("ApplyPriceSchedules: esperado preço 149,90 na versão %d, obtido %v na versão %d", created.Version+1, product.Price, product.Version)
+		}
+
+		// o término da promoção e o novo preço definitivo vencem juntos
+		if applied, err := store.ApplyPriceSchedules(ctx, sunday); err != nil || applied != 2 {
+			t.Fatalf("ApplyPriceSchedules no término: esperado 2, obtido %d (%v)", applied, err)
+		}
+		product, err = store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Price != brl(21990) {
+			t.Errorf("ApplyPriceSchedules: esperado preço 219,90, obtido %v", product.Price)
+		}
+		schedules, err := store.ListPriceSchedules(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("ListPriceSchedules: %v", err)
+		}
+		if len(schedules) != 2 || schedules[0].ID != sale.ID || schedules[0].Status != models.ScheduleCompleted || schedules[1].ID != later.ID || schedules[1].Status != models.ScheduleCompleted {
+			t.Errorf("ListPriceSchedules: agendamentos inesperados %+v", schedules)
+		}
+		changes, _, err := store.ListPriceChanges(ctx, created.ID, repositories.Window{})
+		if err != nil {
+			t.Fatalf("ListPriceChanges: %v", err)
+		}
+		wantPrices := []models.Money{brl(19990), brl(14990), brl(19990), brl(21990)}
+		wantAt := []time.Time{reservedAt, friday, sunday, sunday}
+		if len(changes) != len(wantPrices) {
+			t.Fatalf("ListPriceChanges: esperado %d alterações, obtido %+v", len(wantPrices), changes)
+		}
+		for i, c := range changes {
+			if c.Price != wantPrices[i] || !c.CreatedAt.Equal(wantAt[i]) {
+				t.Errorf("ListPriceChanges[%d]: esperado %v em %v, obtido %+v", i, wantPrices[i], wantAt[i], c)
+			}
+		}
+		if c := changes[1]; c.ScheduleID != sale.ID || c.Actor != actor.System {
+			t.Errorf("ListPriceChanges: início do agendamento inesperado %+v", c)
+		}
+		if next, err := store.NextPriceScheduleAt(ctx); err != nil || next != nil {
+			t.Errorf("NextPriceScheduleAt sem agendamentos em aberto: esperado nil, obtido %v (%v)", next, err)
+		}
+		if _, err := store.CancelPriceSchedule(ctx, created.ID, sale.ID, "storetest", sunday); !errors.Is(err, repositories.ErrScheduleClosed) {
+			t.Errorf("CancelPriceSchedule concluído: esperado ErrScheduleClosed, obtido %v", err)
+		}
+	})
+
+	t.Run("CancelActivePriceSchedule", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Cancelado", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		start := reservedAt.Add(time.Hour)
+		end := start.Add(time.Hour)
+		sale, err := store.CreatePriceSchedule(ctx, newSchedule(created.ID, brl(9990), start, &end))
+		if err != nil {
+			t.Fatalf("CreatePriceSchedule: %v", err)
+		}
+		if _, err := store.ApplyPriceSchedules(ctx, start); err != nil {
+			t.Fatalf("ApplyPriceSchedules: %v", err)
+		}
+		cancelled, err := store.CancelPriceSchedule(ctx, created.ID, sale.ID, "gerente", start.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("CancelPriceSchedule: %v", err)
+		}
+		if cancelled.Status != models.ScheduleCancelled {
+			t.Errorf("CancelPriceSchedule: esperado status cancelled, obtido %+v", cancelled)
+		}
+		product, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Price != brl(19990) {
+			t.Errorf("CancelPriceSchedule: esperado o preço anterior 199,90, obtido %v", product.Price)
+		}
+		if applied, err := store.ApplyPriceSchedules(ctx, end); err != nil || applied != 0 {
+			t.Errorf("ApplyPriceSchedules após cancelar: esperado 0, obtido %d (%v)", applied, err)
+		}
+
+		// um agendamento cujo período inteiro já passou não altera o preço
+		past := end.Add(time.Hour)
+		pastEnd := past.Add(time.Hour)
+		if _, err := store.CreatePriceSchedule(ctx, newSchedule(created.ID, brl(4990), past, &pastEnd)); err != nil {
+			t.Fatalf("CreatePriceSchedule: %v", err)
+		}
+		if applied, err := store.ApplyPriceSchedules(ctx, pastEnd.Add(time.Minute)); err != nil || applied != 1 {
+			t.Errorf("ApplyPriceSchedules de período vencido: esperado 1, obtido %d (%v)", applied, err)
+		}
+		product, err = store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Price != brl(19990) {
+			t.Errorf("ApplyPriceSchedules de período vencido: esperado preço 199,90, obtido %v", product.Price)
+		}
+		changes, _, err := store.ListPriceChanges(ctx, created.ID, repositories.Window{})
+		if err != nil {
+			t.Fatalf("ListPriceChanges: %v", err)
+		}
+		if len(changes) != 3 || changes[2].Actor != "gerente" || changes[2].Price != brl(19990) {
+			t.Errorf("ListPriceChanges: esperado o cancelamento por gerente por último, obtido %+v", changes)
+		}
+		if _, err := store.CancelPriceSchedule(ctx, created.ID, 999999, "gerente", end); !errors.Is(err, repositories.ErrScheduleNotFound) {
+			t.Errorf("CancelPriceSchedule: esperado ErrScheduleNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("VariantsAggregateStock", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newOptionedProduct("Produto Variado"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if len(created.Options) != 1 || created.Options[0].Name != "Cor" {
+			t.Errorf("Create: eixos de variação inesperados %+v", created.Options)
+		}
+		red, err := store.CreateVariant(ctx, newVariant(created.ID, "VAR-VERMELHO", "Vermelho", 4), created.Version, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		if red.ID == 0 || red.ProductID != created.ID || red.Options["Cor"] != "Vermelho" || red.Stock != 4 {
+			t.Errorf("CreateVariant: variante inesperada %+v", red)
+		}
+		if _, err := store.CreateVariant(ctx, newVariant(created.ID, "VAR-AZUL", "Azul", 6), created.Version, stockChange(reservedAt)); !errors.Is(err, repositories.ErrVersionConflict) {
+			t.Errorf("CreateVariant com versão antiga: esperado ErrVersionConflict, obtido %v", err)
+		}
+		product, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Stock != 4 || product.Version != created.Version+1 {
+			t.Errorf("CreateVariant: esperado estoque 4 na versão %d, obtido %d na versão %d", created.Version+1, product.Stock, product.Version)
+		}
+		blue := newVariant(created.ID, "VAR-AZUL", "Azul", 6)
+		blue.PriceOverride = &models.Money{Amount: 24990, Currency: "BRL"}
+		if _, err := store.CreateVariant(ctx, blue, product.Version, stockChange(reservedAt)); err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		if _, err := store.CreateVariant(ctx, newVariant(created.ID, "var-azul", "Verde", 1), product.Version+1, stockChange(reservedAt)); !errors.Is(err, repositories.ErrSKUExists) {
+			t.Errorf("CreateVariant com SKU repetido: esperado ErrSKUExists, obtido %v", err)
+		}
+
+		variants, err := store.ListVariants(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("ListVariants: %v", err)
+		}
+		if len(variants) != 2 || variants[0].ID != red.ID || variants[1].PriceOverride == nil || *variants[1].PriceOverride != *blue.PriceOverride {
+			t.Errorf("ListVariants: variantes inesperadas %+v", variants)
+		}
+		if _, err := store.AdjustStock(ctx, created.ID, 1, stockChange(reservedAt)); !errors.Is(err, repositories.ErrVariantRequired) {
+			t.Errorf("AdjustStock sem variante: esperado ErrVariantRequired, obtido %v", err)
+		}
+		product, err = store.AdjustVariantStock(ctx, created.ID, red.ID, -3, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("AdjustVariantStock: %v", err)
+		}
+		if product.Stock != 7 {
+			t.Errorf("AdjustVariantStock: esperado estoque 7, obtido %d", product.Stock)
+		}
+		if _, err := store.AdjustVariantStock(ctx, created.ID, red.ID, -2, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("AdjustVariantStock além do estoque da variante: esperado ErrInsufficientStock, obtido %v", err)
+		}
+
+		changed := variants[1]
+		changed.Stock = 2
+		changed.Active = false
+		updated, err := store.UpdateVariant(ctx, changed, product.Version, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("UpdateVariant: %v", err)
+		}
+		if updated.Stock != 2 || updated.Active {
+			t.Errorf("UpdateVariant: variante inesperada %+v", updated)
+		}
+		if err := store.DeleteVariant(ctx, created.ID, red.ID, product.Version+1, stockChange(reservedAt)); err != nil {
+			t.Fatalf("DeleteVariant: %v", err)
+		}
+		if _, err := store.GetVariant(ctx, created.ID, red.ID); !errors.Is(err, repositories.ErrVariantNotFound) {
+			t.Errorf("GetVariant após excluir: esperado ErrVariantNotFound, obtido %v", err)
+		}
+		product, err = store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if product.Stock != 2 {
+			t.Errorf("DeleteVariant: esperado estoque 2, obtido %d", product.Stock)
+		}
+		assertLedgerMatchesStock(t, store, product)
+		if _, err := store.ListVariants(ctx, 999999); !errors.Is(err, repositories.ErrProductNotFound) {
+			t.Errorf("ListVariants: esperado ErrProductNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("VariantReservations", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newOptionedProduct("Produto Variado Reservado"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		red, err := store.CreateVariant(ctx, newVariant(created.ID, "RES-VERMELHO", "Vermelho", 5), created.Version, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		blue, err := store.CreateVariant(ctx, newVariant(created.ID, "RES-AZUL", "Azul", 1), created.Version+1, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		version := created.Version + 2
+
+		if _, err := store.Reserve(ctx, newReservation(created.ID, 1)); !errors.Is(err, repositories.ErrVariantRequired) {
+			t.Errorf("Reserve sem variante: esperado ErrVariantRequired, obtido %v", err)
+		}
+		request := newReservation(created.ID, 2)
+		request.VariantID = blue.ID
+		if _, err := store.Reserve(ctx, request); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("Reserve além do estoque da variante: esperado ErrInsufficientStock, obtido %v", err)
+		}
+		request.VariantID = red.ID
+		reservation, err := store.Reserve(ctx, request)
+		if err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+		if reservation.VariantID != red.ID {
+			t.Errorf("Reserve: esperado a variante %d, obtido %+v", red.ID, reservation)
+		}
+		assertReserved(t, store, created.ID, red.ID, reservedAt, 2)
+		assertReserved(t, store, created.ID, blue.ID, reservedAt, 0)
+		assertReserved(t, store, created.ID, 0, reservedAt, 2)
+		if err := store.DeleteVariant(ctx, created.ID, red.ID, version, stockChange(reservedAt)); !errors.Is(err, repositories.ErrVariantReserved) {
+			t.Errorf("DeleteVariant reservada: esperado ErrVariantReserved, obtido %v", err)
+		}
+		shrunk := *red
+		shrunk.Stock = 1
+		if _, err := store.UpdateVariant(ctx, shrunk, version, stockChange(reservedAt)); !errors.Is(err, repositories.ErrInsufficientStock) {
+			t.Errorf("UpdateVariant abaixo do reservado: esperado ErrInsufficientStock, obtido %v", err)
+		}
+
+		product, err := store.CommitReservation(ctx, reservation.ID, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CommitReservation: %v", err)
+		}
+		if product.Stock != 4 {
+			t.Errorf("CommitReservation: esperado estoque 4, obtido %d", product.Stock)
+		}
+		variant, err := store.GetVariant(ctx, created.ID, red.ID)
+		if err != nil {
+			t.Fatalf("GetVariant: %v", err)
+		}
+		if variant.Stock != 3 {
+			t.Errorf("CommitReservation: esperado estoque 3 na variante, obtido %d", variant.Stock)
+		}
+		movements, _, err := store.ListMovements(ctx, created.ID, repositories.Window{})
+		if err != nil {
+			t.Fatalf("ListMovements: %v", err)
+		}
+		if last := movements[len(movements)-1]; last.VariantID != red.ID || last.Quantity != -2 {
+			t.Errorf("ListMovements: esperado a baixa da variante %d, obtido %+v", red.ID, last)
+		}
+		assertLedgerMatchesStock(t, store, product)
+	})
+
+	t.Run("ReturnedValuesAreCopies", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newProduct("Produto Cópia", "Testes"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		all, err := store.GetAll(ctx)
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		for i := range all {
+			all[i].Name = "Alterado fora do store"
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != created.Name {
+			t.Errorf("GetAll: alteração externa vazou para o store (%q)", got.Name)
+		}
+
+		optioned, err := store.Create(ctx, newOptionedProduct("Produto Cópia Variado"), stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		optioned.Options[0].Values[0] = "Alterado"
+		variant, err := store.CreateVariant(ctx, newVariant(optioned.ID, "COPIA-AZUL", "Azul", 1), optioned.Version, stockChange(reservedAt))
+		if err != nil {
+			t.Fatalf("CreateVariant: %v", err)
+		}
+		variant.Options["Cor"] = "Alterado"
+		gotProduct, err := store.GetByID(ctx, optioned.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		gotVariant, err := store.GetVariant(ctx, optioned.ID, variant.ID)
+		if err != nil {
+			t.Fatalf("GetVariant: %v", err)
+		}
+		if gotProduct.Options[0].Values[0] != "Vermelho" || gotVariant.Options["Cor"] != "Azul" {
+			t.Errorf("Create/CreateVariant: alteração externa vazou para o store (%+v, %+v)", gotProduct.Options, gotVariant.Options)
+		}
+	})
+}
+
+// TestOrderStore executa a suíte de conformidade contra um OrderStore
+func TestOrderStore(t *testing.T, newStore OrderStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		order := newOrder(7)
+		created, err := store.Create(ctx, order)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.ID == 0 || created.Version != 1 {
+			t.Errorf("Create: esperado ID atribuído e versão 1, obtido ID %d versão %d", created.ID, created.Version)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.UserID != 7 || got.Status != models.OrderPending || got.Total != order.Total || !got.CreatedAt.Equal(order.CreatedAt) {
+			t.Errorf("GetByID: esperado %+v, obtido %+v", order, got)
+		}
+		if len(got.Items) != len(order.Items) {
+			t.Fatalf("GetByID: esperado %d itens, obtido %d", len(order.Items), len(got.Items))
+		}
+		for i, item := range got.Items {
+			if item != order.Items[i] {
+				t.Errorf("GetByID: item %d esperado %+v, obtido %+v", i, order.Items[i], item)
+			}
+		}
+	})
+
+	t.Run("DiscountsAreStored", func(t *testing.T) {
+		store := newStore(t)
+
+		order := newOrder(7)
+		order.Discounts = []models.OrderDiscount{
+			{PromotionID: 3, Name: "Semana do cliente", Amount: brl(310), RedemptionID: 21},
+			{PromotionID: 5, Name: "Cupom", Code: "BLACK10", Amount: brl(500), RedemptionID: 22},
+		}
+		order.Total = brl(2289)
+		created, err := store.Create(ctx, order)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.Subtotal != brl(3099) || created.Discount != brl(810) {
+			t.Errorf("Create: esperado subtotal e desconto calculados, obtido %+v", created)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !reflect.DeepEqual(got.Discounts, order.Discounts) || got.Subtotal != brl(3099) || got.Discount != brl(810) || got.Total != brl(2289) {
+			t.Errorf("GetByID: esperado os descontos %+v, obtido %+v", order.Discounts, got)
+		}
+
+		plain, err := store.Create(ctx, newOrder(7))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if got, err := store.GetByID(ctx, plain.ID); err != nil || got.Discounts == nil || len(got.Discounts) != 0 || got.Discount != brl(0) {
+			t.Errorf("GetByID: esperado pedido sem descontos com lista vazia, obtido %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.GetByID(ctx, 999999); !errors.Is(err, repositories.ErrOrderNotFound) {
+			t.Errorf("GetByID: esperado ErrOrderNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("ListFiltersAndPaginates", func(t *testing.T) {
+		store := newStore(t)
+
+		var ids []int
+		for i := 0; i < 5; i++ {
+			created, err := store.Create(ctx, newOrder(1+i%2))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids = append(ids, created.ID)
+		}
+		if _, err := store.UpdateStatus(ctx, ids[0], 1, models.OrderPaid, orderedAt); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+
+		byUser, total, err := store.List(ctx, repositories.OrderQuery{Filter: repositories.OrderFilter{UserID: 1}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 3 || len(byUser) != 3 || byUser[0].ID != ids[0] || len(byUser[0].Items) != 2 {
+			t.Errorf("List por usuário: esperado os pedidos 1, 3 e 5 com itens, obtido %+v (total %d)", byUser, total)
+		}
+
+		paid, total, err := store.List(ctx, repositories.OrderQuery{Filter: repositories.OrderFilter{Status: models.OrderPaid}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 1 || len(paid) != 1 || paid[0].ID != ids[0] {
+			t.Errorf("List por status: esperado apenas o pedido %d, obtido %+v", ids[0], paid)
+		}
+
+		sort := []repositories.SortField{{Field: "id", Desc: true}}
+		page, total, err := store.List(ctx, repositories.OrderQuery{Sort: sort, Window: repositories.Window{Offset: 1, Limit: 2}})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 5 || len(page) != 2 || page[0].ID != ids[3] || page[1].ID != ids[2] {
+			t.Errorf("List paginado: esperado os pedidos %d e %d, obtido %+v", ids[3], ids[2], page)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newOrder(1))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		paidAt := orderedAt.Add(time.Hour)
+		paid, err := store.UpdateStatus(ctx, created.ID, created.Version, models.OrderPaid, paidAt)
+		if err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		if paid.Status != models.OrderPaid || paid.Version != 2 || !paid.UpdatedAt.Equal(paidAt) || len(paid.Items) != 2 {
+			t.Errorf("UpdateStatus: registro inesperado %+v", paid)
+		}
+		if _, err := store.UpdateStatus(ctx, created.ID, created.Version, models.OrderCancelled, paidAt); !errors.Is(err, repositories.ErrVersionConflict) {
+			t.Errorf("UpdateStatus com versão antiga: esperado ErrVersionConflict, obtido %v", err)
+		}
+		if _, err := store.UpdateStatus(ctx, 999999, 1, models.OrderPaid, paidAt); !errors.Is(err, repositories.ErrOrderNotFound) {
+			t.Errorf("UpdateStatus: esperado ErrOrderNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("ReturnedValuesAreCopies", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newOrder(1))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		got.Items[0].Quantity = 999
+
+		again, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if again.Items[0].Quantity == 999 {
+			t.Errorf("GetByID: alteração externa vazou para o store")
+		}
+	})
+}
+
+// orderedAt é o instante de criação dos pedidos da suíte
+var orderedAt = time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC)
+
+func newOrder(userID int) models.Order {
+	items := []models.OrderItem{
+		{ProductID: 1, Name: "Produto A", Quantity: 2, UnitPrice: brl(1050), Subtotal: brl(2100), ReservationID: 11},
+		{ProductID: 2, Name: "Produto B", Quantity: 1, UnitPrice: brl(999), Subtotal: brl(999)},
+	}
+	return models.Order{
+		UserID:    userID,
+		Status:    models.OrderPending,
+		Items:     items,
+		Total:     brl(3099),
+		CreatedAt: orderedAt,
+		UpdatedAt: orderedAt,
+	}
+}
+
+// TestCategoryStore executa a suíte de conformidade contra um CategoryStore
+func TestCategoryStore(t *testing.T, newStore CategoryStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		parent, err := store.Create(ctx, models.Category{Name: "Raiz Conformidade", Slug: "raiz-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		child, err := store.Create(ctx, models.Category{Name: "Filha Conformidade", Slug: "filha-conformidade", ParentID: &parent.ID})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if parent.ID <= 0 || child.ID <= 0 || parent.ID == child.ID {
+			t.Fatalf("Create: esperado IDs positivos e distintos, obtido %d e %d", parent.ID, child.ID)
+		}
+
+		got, err := store.GetByID(ctx, child.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != child.Name || got.Slug != child.Slug || got.ParentID == nil || *got.ParentID != parent.ID {
+			t.Errorf("GetByID: esperado %+v, obtido %+v", *child, *got)
+		}
+
+		all, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var found int
+		for i, c := range all {
+			if i > 0 && all[i-1].ID >= c.ID {
+				t.Errorf("List: esperado ordem crescente de ID, obtido %d após %d", c.ID, all[i-1].ID)
+			}
+			if c.ID == parent.ID || c.ID == child.ID {
+				found++
+			}
+		}
+		if found != 2 {
+			t.Errorf("List: esperado as 2 categorias criadas, encontradas %d", found)
+		}
+	})
+
+	t.Run("SlugIsUnique", func(t *testing.T) {
+		store := newStore(t)
+
+		first, err := store.Create(ctx, models.Category{Name: "Única", Slug: "unica-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Create(ctx, models.Category{Name: "Outra", Slug: "unica-conformidade"}); !errors.Is(err, repositories.ErrSlugExists) {
+			t.Errorf("Create com slug repetido: esperado ErrSlugExists, obtido %v", err)
+		}
+
+		second, err := store.Create(ctx, models.Category{Name: "Segunda", Slug: "segunda-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Update(ctx, second.ID, models.Category{Name: "Segunda", Slug: first.Slug}); !errors.Is(err, repositories.ErrSlugExists) {
+			t.Errorf("Update com slug de outra categoria: esperado ErrSlugExists, obtido %v", err)
+		}
+		if _, err := store.Update(ctx, first.ID, models.Category{Name: "Única Renomeada", Slug: first.Slug}); err != nil {
+			t.Errorf("Update mantendo o próprio slug: %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+
+		parent, err := store.Create(ctx, models.Category{Name: "Pai", Slug: "pai-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		category, err := store.Create(ctx, models.Category{Name: "Antiga", Slug: "antiga-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		updated, err := store.Update(ctx, category.ID, models.Category{Name: "Nova", Slug: "nova-conformidade", ParentID: &parent.ID})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.ID != category.ID || updated.Name != "Nova" || updated.Slug != "nova-conformidade" {
+			t.Errorf("Update: obtido %+v", *updated)
+		}
+
+		got, err := store.GetByID(ctx, category.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.Name != "Nova" || got.ParentID == nil || *got.ParentID != parent.ID {
+			t.Errorf("GetByID: esperado a categoria atualizada, obtido %+v", *got)
+		}
+
+		if _, err := store.Update(ctx, 999999, models.Category{Name: "X", Slug: "x-conformidade"}); !errors.Is(err, repositories.ErrCategoryNotFound) {
+			t.Errorf("Update: esperado ErrCategoryNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		category, err := store.Create(ctx, models.Category{Name: "Removida", Slug: "removida-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := store.Delete(ctx, category.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.GetByID(ctx, category.ID); !errors.Is(err, repositories.ErrCategoryNotFound) {
+			t.Errorf("GetByID após Delete: esperado ErrCategoryNotFound, obtido %v", err)
+		}
+		if err := store.Delete(ctx, category.ID); !errors.Is(err, repositories.ErrCategoryNotFound) {
+			t.Errorf("Delete repetido: esperado ErrCategoryNotFound, obtido %v", err)
+		}
+		if _, err := store.Create(ctx, models.Category{Name: "Removida", Slug: "removida-conformidade"}); err != nil {
+			t.Errorf("Create reutilizando o slug removido: %v", err)
+		}
+	})
+
+	t.Run("ReturnedValuesAreCopies", func(t *testing.T) {
+		store := newStore(t)
+
+		parent, err := store.Create(ctx, models.Category{Name: "Pai Cópia", Slug: "pai-copia-conformidade"})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		child, err := store.Create(ctx, models.Category{Name: "Filha Cópia", Slug: "filha-copia-conformidade", ParentID: &parent.ID})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		got, err := store.GetByID(ctx, child.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		got.Name = "Alterada"
+		*got.ParentID = 999999
+
+		again, err := store.GetByID(ctx, child.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if again.Name == "Alterada" || *again.ParentID != parent.ID {
+			t.Errorf("GetByID: alteração externa vazou para o store")
+		}
+	})
+}
+
+// TestCartStore executa a suíte de conformidade contra um CartStore
+func TestCartStore(t *testing.T, newStore CartStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		cart := newCart(7, cartedAt)
+		saved, err := store.Save(ctx, cart)
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if saved.Total != brl(3099) || saved.Items[0].Subtotal != brl(2100) {
+			t.Errorf("Save: esperado total e subtotais calculados, obtido %+v", saved)
+		}
+
+		got, err := store.Get(ctx, 7)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got.UserID != 7 || got.Total != saved.Total || !got.CreatedAt.Equal(cart.CreatedAt) || !got.ExpiresAt.Equal(cart.ExpiresAt) {
+			t.Errorf("Get: esperado %+v, obtido %+v", saved, got)
+		}
+		if len(got.Items) != len(saved.Items) {
+			t.Fatalf("Get: esperado %d itens, obtido %d", len(saved.Items), len(got.Items))
+		}
+		for i, item := range got.Items {
+			if item.ProductID != saved.Items[i].ProductID || item.UnitPrice != saved.Items[i].UnitPrice ||
+				item.Subtotal != saved.Items[i].Subtotal || !item.AddedAt.Equal(saved.Items[i].AddedAt) {
+				t.Errorf("Get: item %d esperado %+v, obtido %+v", i, saved.Items[i], item)
+			}
+		}
+	})
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Get(ctx, 999999); !errors.Is(err, repositories.ErrCartNotFound) {
+			t.Errorf("Get: esperado ErrCartNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("SaveReplacesCart", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Save(ctx, newCart(1, cartedAt)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		cart := newCart(1, cartedAt)
+		cart.Items = cart.Items[1:]
+		cart.Items[0].Quantity = 3
+		if _, err := store.Save(ctx, cart); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		got, err := store.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if len(got.Items) != 1 || got.Items[0].ProductID != 2 || got.Items[0].Quantity != 3 || got.Total != brl(2997) {
+			t.Errorf("Get: esperado apenas o produto 2 com 3 unidades, obtido %+v", got)
+		}
+
+		cart.Items = nil
+		if _, err := store.Save(ctx, cart); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if got, err := store.Get(ctx, 1); err != nil || len(got.Items) != 0 || got.Items == nil {
+			t.Errorf("Get: esperado carrinho vazio com lista de itens, obtido %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Save(ctx, newCart(1, cartedAt)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if err := store.Delete(ctx, 1); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Get(ctx, 1); !errors.Is(err, repositories.ErrCartNotFound) {
+			t.Errorf("Get após Delete: esperado ErrCartNotFound, obtido %v", err)
+		}
+		if err := store.Delete(ctx, 1); !errors.Is(err, repositories.ErrCartNotFound) {
+			t.Errorf("Delete repetido: esperado ErrCartNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("PurgeExpired", func(t *testing.T) {
+		store := newStore(t)
+
+		for userID, updatedAt := range map[int]time.Time{1: cartedAt, 2: cartedAt.Add(2 * time.Hour)} {
+			if _, err := store.Save(ctx, newCart(userID, updatedAt)); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+		}
+
+		purged, err := store.PurgeExpired(ctx, cartedAt.Add(90*time.Minute))
+		if err != nil {
+			t.Fatalf("PurgeExpired: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("PurgeExpired: esperado 1 carrinho removido, obtido %d", purged)
+		}
+		if _, err := store.Get(ctx, 1); !errors.Is(err, repositories.ErrCartNotFound) {
+			t.Errorf("Get do carrinho expirado: esperado ErrCartNotFound, obtido %v", err)
+		}
+		if _, err := store.Get(ctx, 2); err != nil {
+			t.Errorf("Get do carrinho vigente: %v", err)
+		}
+	})
+
+	t.Run("ReturnedValuesAreCopies", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Save(ctx, newCart(1, cartedAt)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		got, err := store.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		got.Items[0].Quantity = 999
+
+		again, err := store.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if again.Items[0].Quantity == 999 {
+			t.Errorf("Get: alteração externa vazou para o store")
+		}
+	})
+}
+
+// cartedAt é o instante de criação dos carrinhos da suíte
+var cartedAt = time.Date(2024, 3, 3, 15, 0, 0, 0, time.UTC)
+
+// newCart cria um carrinho atualizado em updatedAt que expira uma hora depois;
+// subtotais e total ficam por conta do store
+func newCart(userID int, updatedAt time.Time) models.Cart {
+	return models.Cart{
+		UserID: userID,
+		Items: []models.CartItem{
+			{ProductID: 1, Name: "Produto A", Quantity: 2, UnitPrice: brl(1050), AddedAt: cartedAt},
+			{ProductID: 2, Name: "Produto B", Quantity: 1, UnitPrice: brl(999), AddedAt: updatedAt},
+		},
+		Total:     brl(0),
+		CreatedAt: cartedAt,
+		UpdatedAt: updatedAt,
+		ExpiresAt: updatedAt.Add(time.Hour),
+	}
+}
+
+// TestPromotionStore executa a suíte de conformidade contra um PromotionStore
+func TestPromotionStore(t *testing.T, newStore PromotionStoreFactory) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		promotion := newPromotion("BLACK10")
+		amount := brl(1500)
+		endsAt := promotedAt.Add(24 * time.Hour)
+		promotion.Type, promotion.Percentage, promotion.Amount = models.DiscountFixed, 0, &amount
+		promotion.CategoryIDs, promotion.ProductIDs = []int{2}, []int{5, 7}
+		promotion.StartsAt, promotion.EndsAt = &promotedAt, &endsAt
+		created, err := store.Create(ctx, promotion)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if created.ID <= 0 {
+			t.Fatalf("Create: esperado ID positivo, obtido %d", created.ID)
+		}
+
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !reflect.DeepEqual(*got, *created) {
+			t.Errorf("GetByID: esperado %+v, obtido %+v", *created, *got)
+		}
+		if byCode, err := store.GetByCode(ctx, "black10"); err != nil || byCode.ID != created.ID {
+			t.Errorf("GetByCode: esperado a promoção %d sem diferenciar maiúsculas, obtido %+v (%v)", created.ID, byCode, err)
+		}
+		if _, err := store.GetByCode(ctx, "NATAL"); !errors.Is(err, repositories.ErrPromotionNotFound) {
+			t.Errorf("GetByCode: esperado ErrPromotionNotFound, obtido %v", err)
+		}
+		if _, err := store.GetByID(ctx, 999999); !errors.Is(err, repositories.ErrPromotionNotFound) {
+			t.Errorf("GetByID: esperado ErrPromotionNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("CouponCodeIsUnique", func(t *testing.T) {
+		store := newStore(t)
+
+		first, err := store.Create(ctx, newPromotion("BLACK10"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Create(ctx, newPromotion("Black10")); !errors.Is(err, repositories.ErrCouponCodeExists) {
+			t.Errorf("Create com código repetido: esperado ErrCouponCodeExists, obtido %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := store.Create(ctx, newPromotion("")); err != nil {
+				t.Errorf("Create de promoção automática %d: %v", i, err)
+			}
+		}
+
+		second, err := store.Create(ctx, newPromotion("NATAL"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Update(ctx, second.ID, newPromotion("BLACK10")); !errors.Is(err, repositories.ErrCouponCodeExists) {
+			t.Errorf("Update com código de outra promoção: esperado ErrCouponCodeExists, obtido %v", err)
+		}
+		if _, err := store.Update(ctx, first.ID, newPromotion("BLACK10")); err != nil {
+			t.Errorf("Update mantendo o próprio código: %v", err)
+		}
+	})
+
+	t.Run("ListIncludesCreated", func(t *testing.T) {
+		store := newStore(t)
+
+		var ids []int
+		for _, code := range []string{"A1", "", "B2"} {
+			created, err := store.Create(ctx, newPromotion(code))
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			ids = append(ids, created.ID)
+		}
+
+		promotions, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(promotions) != 3 || promotions[0].ID != ids[0] || promotions[1].ID != ids[1] || promotions[2].ID != ids[2] {
+			t.Errorf("List: esperado as promoções %v em ordem, obtido %+v", ids, promotions)
+		}
+		if promotions[1].Code != "" || promotions[1].CategoryIDs == nil || promotions[1].ProductIDs == nil {
+			t.Errorf("List: esperado promoção automática com listas de escopo vazias, obtido %+v", promotions[1])
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newPromotion("BLACK10"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		changed := newPromotion("BLACK15")
+		changed.Percentage = 15
+		changed.CreatedAt = promotedAt.Add(time.Hour)
+		changed.UpdatedAt = promotedAt.Add(2 * time.Hour)
+		updated, err := store.Update(ctx, created.ID, changed)
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.Code != "BLACK15" || updated.Percentage != 15 || !updated.CreatedAt.Equal(promotedAt) || !updated.UpdatedAt.Equal(changed.UpdatedAt) {
+			t.Errorf("Update: registro inesperado %+v", updated)
+		}
+		if _, err := store.Update(ctx, 999999, changed); !errors.Is(err, repositories.ErrPromotionNotFound) {
+			t.Errorf("Update: esperado ErrPromotionNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("DeleteRemovesRedemptions", func(t *testing.T) {
+		store := newStore(t)
+
+		created, err := store.Create(ctx, newPromotion("BLACK10"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(created.ID, 1)}); err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		if err := store.Delete(ctx, created.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.GetByID(ctx, created.ID); !errors.Is(err, repositories.ErrPromotionNotFound) {
+			t.Errorf("GetByID após Delete: esperado ErrPromotionNotFound, obtido %v", err)
+		}
+		if uses, err := store.UserRedemptions(ctx, 1); err != nil || len(uses) != 0 {
+			t.Errorf("UserRedemptions após Delete: esperado nenhum resgate, obtido %v (%v)", uses, err)
+		}
+		if err := store.Delete(ctx, created.ID); !errors.Is(err, repositories.ErrPromotionNotFound) {
+			t.Errorf("Delete repetido: esperado ErrPromotionNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("RedeemCountsUses", func(t *testing.T) {
+		store := newStore(t)
+
+		first, err := store.Create(ctx, newPromotion("BLACK10"))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		second, err := store.Create(ctx, newPromotion(""))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		redeemed, err := store.Redeem(ctx, []models.Redemption{
+			newRedemption(first.ID, 1), newRedemption(second.ID, 1), newRedemption(first.ID, 2),
+		})
+		if err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		if len(redeemed) != 3 || redeemed[0].ID <= 0 || redeemed[0].ID == redeemed[2].ID || redeemed[1].PromotionID != second.ID {
+			t.Errorf("Redeem: esperado 3 resgates com IDs distintos, obtido %+v", redeemed)
+		}
+
+		if got, err := store.GetByID(ctx, first.ID); err != nil || got.Uses != 2 {
+			t.Errorf("GetByID: esperado 2 usos, obtido %+v (%v)", got, err)
+		}
+		uses, err := store.UserRedemptions(ctx, 1)
+		if err != nil {
+			t.Fatalf("UserRedemptions: %v", err)
+		}
+		if !reflect.DeepEqual(uses, map[int]int{first.ID: 1, second.ID: 1}) {
+			t.Errorf("UserRedemptions: esperado um resgate de cada promoção, obtido %v", uses)
+		}
+
+		if err := store.ReleaseRedemption(ctx, redeemed[0].ID); err != nil {
+			t.Fatalf("ReleaseRedemption: %v", err)
+		}
+		if got, err := store.GetByID(ctx, first.ID); err != nil || got.Uses != 1 {
+			t.Errorf("GetByID após ReleaseRedemption: esperado 1 uso, obtido %+v (%v)", got, err)
+		}
+		if err := store.ReleaseRedemption(ctx, redeemed[0].ID); !errors.Is(err, repositories.ErrRedemptionNotFound) {
+			t.Errorf("ReleaseRedemption repetido: esperado ErrRedemptionNotFound, obtido %v", err)
+		}
+		if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(999999, 1)}); !errors.Is(err, repositories.ErrPromotionNotFound) {
+			t.Errorf("Redeem de promoção inexistente: esperado ErrPromotionNotFound, obtido %v", err)
+		}
+	})
+
+	t.Run("RedeemEnforcesLimits", func(t *testing.T) {
+		store := newStore(t)
+
+		promotion := newPromotion("BLACK10")
+		promotion.MaxUses, promotion.MaxUsesPerUser = 3, 1
+		limited, err := store.Create(ctx, promotion)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		free, err := store.Create(ctx, newPromotion(""))
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(limited.ID, 1)}); err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(free.ID, 1), newRedemption(limited.ID, 1)}); !errors.Is(err, repositories.ErrPromotionUserLimit) {
+			t.Errorf("Redeem acima do limite por usuário: esperado ErrPromotionUserLimit, obtido %v", err)
+		}
+		if uses, err := store.UserRedemptions(ctx, 1); err != nil || uses[free.ID] != 0 {
+			t.Errorf("Redeem recusado: esperado nenhum resgate do lote, obtido %v (%v)", uses, err)
+		}
+		if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(limited.ID, 2), newRedemption(limited.ID, 2)}); !errors.Is(err, repositories.ErrPromotionUserLimit) {
+			t.Errorf("Redeem repetido no lote: esperado ErrPromotionUserLimit, obtido %v", err)
+		}
+
+		for _, userID := range []int{2, 3} {
+			if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(limited.ID, userID)}); err != nil {
+				t.Fatalf("Redeem do usuário %d: %v", userID, err)
+			}
+		}
+		if _, err := store.Redeem(ctx, []models.Redemption{newRedemption(limited.ID, 4)}); !errors.Is(err, repositories.ErrPromotionExhausted) {
+			t.Errorf("Redeem acima do limite total: esperado ErrPromotionExhausted, obtido %v", err)
+		}
+	})
+
+	t.Run("ConcurrentRedemptionsRespectLimit", func(t *testing.T) {
+		store := newStore(t)
+
+		promotion := newPromotion("BLACK10")
+		promotion.MaxUses = 3
+		created, err := store.Create(ctx, promotion)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		const attempts = 10
+		var wg sync.WaitGroup
+		errs := make(chan error, attempts)
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func(userID int) {
+				defer wg.Done()
+				_, err := store.Redeem(ctx, []models.Redemption{newRedemption(created.ID, userID)})
+				errs <- err
+			}(i + 1)
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			case !errors.Is(err, repositories.ErrPromotionExhausted):
+				t.Errorf("Redeem: erro inesperado %v", err)
+			}
+		}
+		if succeeded != 3 {
+			t.Errorf("Redeem: esperado 3 resgates, obtido %d", succeeded)
+		}
+	})
+
+	t.Run("ReturnedValuesAreCopies", func(t *testing.T) {
+		store := newStore(t)
+
+		promotion := newPromotion("BLACK10")
+		promotion.ProductIDs = []int{5}
+		created, err := store.Create(ctx, promotion)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		got, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		got.ProductIDs[0] = 999
+
+		again, err := store.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if again.ProductIDs[0] == 999 {
+			t.Errorf("GetByID: alteração externa vazou para o store")
+		}
+	})
+}
+
+// promotedAt é o instante de criação das promoções da suíte
+var promotedAt = time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+
+// newPromotion cria uma promoção percentual ativa de 10%, sem escopo nem
+// limites; sem código, a promoção é automática
+func newPromotion(code string) models.Promotion {
+	return models.Promotion{
+		Name:       "Promoção " + code,
+		Code:       code,
+		Type:       models.DiscountPercentage,
+		Percentage: 10,
+		Stackable:  true,
+		Active:     true,
+		CreatedAt:  promotedAt,
+		UpdatedAt:  promotedAt,
+	}
+}
+
+func newRedemption(promotionID, userID int) models.Redemption {
+	return models.Redemption{PromotionID: promotionID, UserID: userID, CreatedAt: promotedAt}
+}
+
+// deletedAt é o instante de exclusão usado pelos subtestes da lixeira
+var deletedAt = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// reservedAt é o instante de criação das reservas; elas vencem uma hora depois
+var reservedAt = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newUser(email string) models.User {
+	return models.User{
+		Name:     "Usuário Conformidade",
+		Email:    email,
+		Role:     "user",
+		Active:   true,
+		CreateAt: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func newProduct(name, category string) models.Product {
+	return models.Product{
+		Name:        name,
+		Description: "Produto criado pela suíte de conformidade",
+		Price:       brl(19990),
+		Stock:       10,
+		Category:    category,
+		Active:      true,
+	}
+}
+
+// newOptionedProduct cria um produto sem estoque próprio, variando por cor
+func newOptionedProduct(name string) models.Product {
+	product := newProduct(name, "Testes")
+	product.Stock = 0
+	product.Options = []models.ProductOption{{Name: "Cor", Values: []string{"Vermelho", "Azul", "Verde"}}}
+	return product
+}
+
+func newVariant(productID int, sku, color string, stock int) models.Variant {
+	return models.Variant{
+		ProductID: productID,
+		SKU:       sku,
+		Options:   map[string]string{"Cor": color},
+		Stock:     stock,
+		Active:    true,
+	}
+}
+
+func newSchedule(productID int, price models.Money, startsAt time.Time, endsAt *time.Time) models.PriceSchedule {
+	return models.PriceSchedule{
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Actor:     "storetest",
+		CreatedAt: reservedAt,
+	}
+}
+
+// stockChange é a origem das alterações de estoque feitas pela suíte
+func stockChange(at time.Time) repositories.StockChange {
+	return repositories.StockChange{Type: models.MovementAdjustment, Reason: "conformidade", Actor: "storetest", At: at}
+}
+
+func newReservation(productID, quantity int) models.StockReservation {
+	return models.StockReservation{
+		ProductID: productID,
+		Quantity:  quantity,
+		CreatedAt: reservedAt,
+		ExpiresAt: reservedAt.Add(time.Hour),
+	}
+}
+
+// assertStockOutcome verifica que exatamente stock operações concorrentes de
+// uma unidade tiveram sucesso e que as demais falharam por falta de estoque
+func assertStockOutcome(t *testing.T, label string, errs <-chan error, stock int) {
+	t.Helper()
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, repositories.ErrInsufficientStock):
+			t.Errorf("%s: esperado ErrInsufficientStock, obtido %v", label, err)
+		}
+	}
+	if succeeded != stock {
+		t.Errorf("%s: esperado exatamente %d sucessos, obtido %d", label, stock, succeeded)
+	}
+}
+
+// assertReserved verifica a soma das reservas vigentes em now do produto ou da variante
+func assertReserved(t *testing.T, store repositories.ProductStore, productID, variantID int, now time.Time, want int) {
+	t.Helper()
+	reserved, err := store.Reserved(context.Background(), productID, variantID, now)
+	if err != nil {
+		t.Fatalf("Reserved: %v", err)
+	}
+	if reserved != want {
+		t.Errorf("Reserved(%d, %d): esperado %d, obtido %d", productID, variantID, want, reserved)
+	}
+}
+
+// assertLedgerMatchesStock verifica que o histórico reproduz o estoque do produto
+func assertLedgerMatchesStock(t *testing.T, store repositories.ProductStore, product *models.Product) {
+	t.Helper()
+	movements, _, err := store.ListMovements(context.Background(), product.ID, repositories.Window{})
+	if err != nil {
+		t.Fatalf("ListMovements: %v", err)
+	}
+	sum := 0
+	for _, m := range movements {
+		sum += m.Quantity
+	}
+	if sum != product.Stock {
+		t.Errorf("histórico: soma das movimentações %d difere do estoque %d", sum, product.Stock)
+	}
+	if n := len(movements); n > 0 && movements[n-1].Balance != product.Stock {
+		t.Errorf("histórico: saldo da última movimentação %d difere do estoque %d", movements[n-1].Balance, product.Stock)
+	}
+}
+
+// equalProducts compara dois produtos, tratando eixos de variação nulos e
+// vazios como iguais
+func equalProducts(a, b models.Product) bool {
+	if len(a.Options) == 0 && len(b.Options) == 0 {
+		a.Options, b.Options = nil, nil
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// brl retorna um valor em reais a partir dos centavos
+func brl(cents int64) models.Money {
+	return models.Money{Amount: cents, Currency: "BRL"}
+}
+
+// assertAscendingUnique verifica que a paginação percorreu want IDs em ordem, sem repetições
+func assertAscendingUnique(t *testing.T, ids []int, want int) {
+	t.Helper()
+
+	if len(ids) != want {
+		t.Fatalf("paginação: esperado %d registros, obtido %d", want, len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("paginação: IDs fora de ordem ou repetidos: %v", ids)
+		}
+	}
+}
+
+// assertIDs verifica que os produtos vieram exatamente na ordem de IDs esperada
+func assertIDs(t *testing.T, label string, products []models.Product, want []int) {
+	t.Helper()
+
+	got := make([]int, len(products))
+	for i, p := range products {
+		got[i] = p.ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("%s: esperado IDs %v, obtido %v", label, want, got)
+	}
+}
+
+func containsUser(users []models.User, id int) bool {
+	for _, u := range users {
+		if u.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsProduct(products []models.Product, id int) bool {
+	for _, p := range products {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,21 +1,26 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
 )
 
 var (
 	ErrUserNotFound = errors.New("usuário não encontrado")
+	ErrEmailExists  = errors.New("email já cadastrado")
 )
 
-// UserRepository gerencia os dados de usuários em memória
+// UserRepository é a implementação em memória de UserStore
 type UserRepository struct {
 	mu     sync.RWMutex
 	users  []models.User
 	nextID int
+	// emails indexa os usuários fora da lixeira pelo email normalizado
+	emails map[string]int
 }
 
 // NewUserRepository cria uma nova instância do repositório com dados pré-prontos
@@ -28,7 +33,8 @@ func NewUserRepository() *UserRepository {
 				Email:    "joao.silva@example.com",
 				Role:     "admin",
 				Active:   true,
-				CreateAt: "2024-01-15T10:00:00Z",
+				CreateAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+				Version:  1,
 			},
 			{
 				ID:       2,
@@ -36,7 +42,8 @@ func NewUserRepository() *UserRepository {
 				Email:    "maria.santos@example.com",
 				Role:     "user",
 				Active:   true,
-				CreateAt: "2024-01-16T11:30:00Z",
+				CreateAt: time.Date(2024, 1, 16, 11, 30, 0, 0, time.UTC),
+				Version:  1,
 			},
 			{
 				ID:       3,
@@ -44,7 +51,8 @@ func NewUserRepository() *UserRepository {
 				Email:    "pedro.oliveira@example.com",
 				Role:     "user",
 				Active:   false,
-				CreateAt: "2024-01-17T14:20:00Z",
+				CreateAt: time.Date(2024, 1, 17, 14, 20, 0, 0, time.UTC),
+				Version:  1,
 			},
 			{
 				ID:       4,
@@ -52,7 +60,8 @@ func NewUserRepository() *UserRepository {
 				Email:    "ana.costa@example.com",
 				Role:     "manager",
 				Active:   true,
-				CreateAt: "2024-01-18T09:15:00Z",
+				CreateAt: time.Date(2024, 1, 18, 9, 15, 0, 0, time.UTC),
+				Version:  1,
 			},
 			{
 				ID:       5,
@@ -60,70 +69,189 @@ func NewUserRepository() *UserRepository {
 				Email:    "carlos.ferreira@example.com",
 				Role:     "user",
 				Active:   true,
-				CreateAt: "2024-01-19T16:45:00Z",
+				CreateAt: time.Date(2024, 1, 19, 16, 45, 0, 0, time.UTC),
+				Version:  1,
 			},
 		},
 		nextID: 6,
+		emails: make(map[string]int),
+	}
+	for _, u := range repo.users {
+		repo.emails[NormalizeEmail(u.Email)] = u.ID
 	}
 	return repo
 }
 
-// GetAll retorna todos os usuários
-func (r *UserRepository) GetAll() []models.User {
+// GetAll retorna todos os usuários fora da lixeira
+func (r *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.users
+
+	users := make([]models.User, 0, len(r.users))
+	for _, u := range r.users {
+		if u.DeletedAt == nil {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// List retorna uma janela de usuários que atendem ao filtro, na ordenação solicitada
+func (r *UserRepository) List(ctx context.Context, query UserQuery) ([]models.User, int, error) {
+	r.mu.RLock()
+	filtered := make([]models.User, 0, len(r.users))
+	for i := range r.users {
+		if query.Filter.Matches(r.users[i]) {
+			filtered = append(filtered, r.users[i])
+		}
+	}
+	r.mu.RUnlock()
+
+	keys := func(u models.User) []interface{} { return UserSortValues(u, query.Sort) }
+	sortByKeys(filtered, query.Sort, keys, userID)
+	users := applyWindow(filtered, query.Window, query.Sort, keys, userID)
+	return users, len(filtered), nil
 }
 
 // GetByID retorna um usuário pelo ID
-func (r *UserRepository) GetByID(id int) (*models.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	for i := range r.users {
-		if r.users[i].ID == id {
-			return &r.users[i], nil
-		}
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrUserNotFound
 	}
-	return nil, ErrUserNotFound
+	user := r.users[i]
+	return &user, nil
 }
 
 // Create cria um novo usuário
-func (r *UserRepository) Create(user models.User) models.User {
+func (r *UserRepository) Create(ctx context.Context, user models.User) (*models.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	key := NormalizeEmail(user.Email)
+	if _, taken := r.emails[key]; taken {
+		return nil, ErrEmailExists
+	}
+
 	user.ID = r.nextID
+	user.Version = 1
+	user.DeletedAt = nil
 	r.nextID++
 	r.users = append(r.users, user)
-	return user
+	r.emails[key] = user.ID
+	return &user, nil
 }
 
 // Update atualiza um usuário existente
-func (r *UserRepository) Update(id int, user models.User) (*models.User, error) {
+func (r *UserRepository) Update(ctx context.Context, id int, user models.User) (*models.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for i := range r.users {
-		if r.users[i].ID == id {
-			user.ID = id
-			r.users[i] = user
-			return &r.users[i], nil
-		}
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrUserNotFound
+	}
+	if r.users[i].Version != user.Version {
+		return nil, ErrVersionConflict
 	}
-	return nil, ErrUserNotFound
+	key := NormalizeEmail(user.Email)
+	if owner, taken := r.emails[key]; taken && owner != id {
+		return nil, ErrEmailExists
+	}
+
+	delete(r.emails, NormalizeEmail(r.users[i].Email))
+	r.emails[key] = id
+	user.ID = id
+	user.Version++
+	user.DeletedAt = nil
+	r.users[i] = user
+	return &user, nil
 }
 
-// Delete remove um usuário
-func (r *UserRepository) Delete(id int) error {
+// SetActive altera o estado ativo de um usuário
+func (r *UserRepository) SetActive(ctx context.Context, id int, active bool) (*models.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	i := r.find(id, false)
+	if i < 0 {
+		return nil, ErrUserNotFound
+	}
+	r.users[i].Active = active
+	r.users[i].Version++
+	user := r.users[i]
+	return &user, nil
+}
+
+// Delete move um usuário para a lixeira
+func (r *UserRepository) Delete(ctx context.Context, id int, version int, deletedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id, false)
+	if i < 0 {
+		return ErrUserNotFound
+	}
+	if r.users[i].Version != version {
+		return ErrVersionConflict
+	}
+	r.users[i].DeletedAt = &deletedAt
+	delete(r.emails, NormalizeEmail(r.users[i].Email))
+	return nil
+}
+
+// Restore retira um usuário da lixeira, desde que seu email não tenha sido
+// reutilizado por outro usuário
+func (r *UserRepository) Restore(ctx context.Context, id int) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(id, true)
+	if i < 0 {
+		return nil, ErrUserNotFound
+	}
+	key := NormalizeEmail(r.users[i].Email)
+	if _, taken := r.emails[key]; taken {
+		return nil, ErrEmailExists
+	}
+
+	r.users[i].DeletedAt = nil
+	r.emails[key] = id
+	user := r.users[i]
+	return &user, nil
+}
+
+// Purge remove definitivamente os usuários excluídos antes de before
+func (r *UserRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.users[:0]
+	for _, u := range r.users {
+		if u.DeletedAt == nil || !u.DeletedAt.Before(before) {
+			kept = append(kept, u)
+		}
+	}
+	purged := len(r.users) - len(kept)
+	r.users = kept
+	return purged, nil
+}
+
+// find retorna a posição do usuário com o ID, dentro ou fora da lixeira
+// conforme deleted, ou -1 se não existir. Deve ser chamado com o lock adquirido.
+func (r *UserRepository) find(id int, deleted bool) int {
 	for i := range r.users {
-		if r.users[i].ID == id {
-			r.users = append(r.users[:i], r.users[i+1:]...)
-			return nil
+		if r.users[i].ID == id && (r.users[i].DeletedAt != nil) == deleted {
+			return i
 		}
 	}
-	return ErrUserNotFound
+	return -1
+}
+
+// userID retorna o ID do usuário, usado como desempate nas ordenações
+func userID(u models.User) int {
+	return u.ID
 }
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var (
+	ErrVariantNotFound = errors.New("variante não encontrada")
+	ErrSKUExists       = errors.New("SKU já utilizado por outra variante")
+	// ErrVariantRequired indica uma operação de estoque sem variante em um
+	// produto cujo estoque é controlado pelas variantes
+	ErrVariantRequired = errors.New("informe a variante")
+	// ErrVariantReserved indica uma variante com reservas vigentes
+	ErrVariantReserved = errors.New("a variante tem reservas vigentes")
+)
+
+// ListVariants retorna as variantes do produto, ordenadas por ID
+func (r *ProductRepository) ListVariants(ctx context.Context, productID int) ([]models.Variant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.find(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	variants := []models.Variant{}
+	for _, v := range r.variants {
+		if v.ProductID == productID {
+			variants = append(variants, cloneVariant(v))
+		}
+	}
+	return variants, nil
+}
+
+// GetVariant retorna uma variante do produto
+func (r *ProductRepository) GetVariant(ctx context.Context, productID, variantID int) (*models.Variant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.find(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	j := r.findVariant(productID, variantID)
+	if j < 0 {
+		return nil, ErrVariantNotFound
+	}
+	variant := cloneVariant(r.variants[j])
+	return &variant, nil
+}
+
+// CreateVariant grava uma variante, somando seu estoque ao do produto
+func (r *ProductRepository) CreateVariant(ctx context.Context, variant models.Variant, productVersion int, change StockChange) (*models.Variant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.lockProductVersion(variant.ProductID, productVersion)
+	if err != nil {
+		return nil, err
+	}
+	if r.skuInUse(variant.SKU, 0) {
+		return nil, ErrSKUExists
+	}
+
+	variant.ID = r.nextVariantID
+	r.nextVariantID++
+	variant = cloneVariant(variant)
+	r.variants = append(r.variants, variant)
+	r.products[i].Stock += variant.Stock
+	r.products[i].Version++
+	r.record(variant.ProductID, variant.ID, variant.Stock, r.products[i].Stock, change)
+	created := cloneVariant(variant)
+	return &created, nil
+}
+
+// UpdateVariant substitui uma variante, aplicando ao produto a diferença de estoque
+func (r *ProductRepository) UpdateVariant(ctx context.Context, variant models.Variant, productVersion int, change StockChange) (*models.Variant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.lockProductVersion(variant.ProductID, productVersion)
+	if err != nil {
+		return nil, err
+	}
+	j := r.findVariant(variant.ProductID, variant.ID)
+	if j < 0 {
+		return nil, ErrVariantNotFound
+	}
+	if r.skuInUse(variant.SKU, variant.ID) {
+		return nil, ErrSKUExists
+	}
+	delta := variant.Stock - r.variants[j].Stock
+	if delta < 0 && variant.Stock < r.reservedVariant(variant.ID, change.At) {
+		return nil, ErrInsufficientStock
+	}
+
+	r.variants[j] = cloneVariant(variant)
+	r.products[i].Stock += delta
+	r.products[i].Version++
+	r.record(variant.ProductID, variant.ID, delta, r.products[i].Stock, change)
+	updated := cloneVariant(variant)
+	return &updated, nil
+}
+
+// DeleteVariant remove uma variante sem reservas vigentes, retirando seu estoque do produto
+func (r *ProductRepository) DeleteVariant(ctx context.Context, productID, variantID int, productVersion int, change StockChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.lockProductVersion(productID, productVersion)
+	if err != nil {
+		return err
+	}
+	j := r.findVariant(productID, variantID)
+	if j < 0 {
+		return ErrVariantNotFound
+	}
+	if r.reservedVariant(variantID, change.At) > 0 {
+		return ErrVariantReserved
+	}
+
+	stock := r.variants[j].Stock
+	r.variants = append(r.variants[:j], r.variants[j+1:]...)
+	r.products[i].Stock -= stock
+	r.products[i].Version++
+	r.record(productID, variantID, -stock, r.products[i].Stock, change)
+	return nil
+}
+
+// AdjustVariantStock soma delta ao estoque da variante e ao do produto;
+// retiradas respeitam as reservas vigentes da variante e entradas não podem
+// levar o estoque do produto, que inclui o da variante, além de math.MaxInt
+func (r *ProductRepository) AdjustVariantStock(ctx context.Context, productID, variantID int, delta int, change StockChange) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(productID, false)
+	if i < 0 {
+		return nil, ErrProductNotFound
+	}
+	j := r.findVariant(productID, variantID)
+	if j < 0 {
+		return nil, ErrVariantNotFound
+	}
+	if delta < 0 && r.variants[j].Stock+delta < r.reservedVariant(variantID, change.At) {
+		return nil, ErrInsufficientStock
+	}
+	if delta > 0 && r.products[i].Stock > math.MaxInt-delta {
+		return nil, ErrStockOverflow
+	}
+	r.variants[j].Stock += delta
+	r.products[i].Stock += delta
+	r.products[i].Version++
+	r.record(productID, variantID, delta, r.products[i].Stock, change)
+	product := cloneProduct(r.products[i])
+	return &product, nil
+}
+
+// lockProductVersion retorna a posição do produto fora da lixeira se version
+// for a versão atual, ou o erro correspondente. Deve ser chamado com o lock
+// de escrita adquirido.
+func (r *ProductRepository) lockProductVersion(id, version int) (int, error) {
+	i := r.find(id, false)
+	if i < 0 {
+		return -1, ErrProductNotFound
+	}
+	if r.products[i].Version != version {
+		return -1, ErrVersionConflict
+	}
+	return i, nil
+}
+
+// findVariant retorna a posição da variante do produto, ou -1. Deve ser
+// chamado com o lock adquirido.
+func (r *ProductRepository) findVariant(productID, variantID int) int {
+	for j := range r.variants {
+		if r.variants[j].ID == variantID && r.variants[j].ProductID == productID {
+			return j
+		}
+	}
+	return -1
+}
+
+// skuInUse indica se o SKU pertence a outra variante, sem diferenciar
+// maiúsculas. Deve ser chamado com o lock adquirido.
+func (r *ProductRepository) skuInUse(sku string, exceptID int) bool {
+	for _, v := range r.variants {
+		if v.ID != exceptID && strings.EqualFold(v.SKU, sku) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedVariant soma as quantidades reservadas e vigentes da variante.
+// Deve ser chamado com o lock adquirido.
+func (r *ProductRepository) reservedVariant(variantID int, now time.Time) int {
+	total := 0
+	for _, reservation := range r.reservations {
+		if reservation.VariantID == variantID && reservation.Active(now) {
+			total += reservation.Quantity
+		}
+	}
+	return total
+}
+
+// cloneProduct copia o produto sem compartilhar os eixos de variação
+func cloneProduct(p models.Product) models.Product {
+	options := make([]models.ProductOption, len(p.Options))
+	for i, o := range p.Options {
+		options[i] = models.ProductOption{Name: o.Name, Values: append([]string{}, o.Values...)}
+	}
+	p.Options = options
+	return p
+}
+
+// cloneVariant copia a variante sem compartilhar as opções nem o preço
+func cloneVariant(v models.Variant) models.Variant {
+	options := make(map[string]string, len(v.Options))
+	for k, value := range v.Options {
+		options[k] = value
+	}
+	v.Options = options
+	if v.PriceOverride != nil {
+		price := *v.PriceOverride
+		v.PriceOverride = &price
+	}
+	return v
+}
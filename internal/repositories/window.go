@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// sortByKeys ordena os itens pelos campos informados, desempatando pelo ID
+func sortByKeys[T any](items []T, fields []SortField, keys func(T) []interface{}, id func(T) int) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if c := compareKeys(fields, keys(items[i]), keys(items[j])); c != 0 {
+			return c < 0
+		}
+		return id(items[i]) < id(items[j])
+	})
+}
+
+// applyWindow aplica a janela sobre itens já filtrados e ordenados pelos campos informados
+func applyWindow[T any](items []T, window Window, fields []SortField, keys func(T) []interface{}, id func(T) int) []T {
+	if window.After != nil {
+		start := len(items)
+		for i := range items {
+			if isAfter(fields, keys(items[i]), id(items[i]), window.After) {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	}
+
+	start := window.Offset
+	switch {
+	case start < 0:
+		start = 0
+	case start > len(items):
+		start = len(items)
+	}
+	end := len(items)
+	if window.Limit > 0 && start+window.Limit < end {
+		end = start + window.Limit
+	}
+
+	page := make([]T, end-start)
+	copy(page, items[start:end])
+	return page
+}
+
+// isAfter indica se o registro vem depois da posição do keyset na ordenação
+func isAfter(fields []SortField, values []interface{}, id int, after *models.Keyset) bool {
+	if c := compareKeys(fields, values, after.Values); c != 0 {
+		return c > 0
+	}
+	return id > after.ID
+}
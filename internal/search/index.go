@@ -0,0 +1,209 @@
+// Package search implementa um índice invertido em memória para busca textual
+// com normalização de acentos, tokenização e pontuação de relevância.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Field identifica um campo indexado e o peso de suas ocorrências na relevância
+type Field int
+
+// Campos indexados, do mais relevante para o menos relevante
+const (
+	FieldName Field = iota
+	FieldCategory
+	FieldDescription
+	fieldCount
+)
+
+// fieldWeights define o peso de cada campo na pontuação
+var fieldWeights = [fieldCount]float64{
+	FieldName:        3,
+	FieldCategory:    2,
+	FieldDescription: 1,
+}
+
+// prefixWeight reduz a pontuação de termos encontrados apenas por prefixo
+const prefixWeight = 0.5
+
+// minPrefixLength evita que prefixos muito curtos casem com todo o vocabulário
+const minPrefixLength = 3
+
+// stopwords são palavras frequentes em português que não ajudam a ranquear
+var stopwords = map[string]bool{
+	"a": true, "o": true, "e": true, "as": true, "os": true, "de": true, "da": true,
+	"do": true, "das": true, "dos": true, "em": true, "no": true, "na": true,
+	"com": true, "sem": true, "para": true, "por": true, "um": true, "uma": true,
+}
+
+// Document representa o conteúdo textual indexado de um registro
+type Document [fieldCount]string
+
+// Hit representa um registro encontrado e sua pontuação de relevância
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index é um índice invertido seguro para uso concorrente
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int]*[fieldCount]int
+	terms    map[int][]string
+}
+
+// NewIndex cria um índice vazio
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]*[fieldCount]int),
+		terms:    make(map[int][]string),
+	}
+}
+
+// Put indexa (ou reindexa) o documento do registro id
+func (ix *Index) Put(id int, doc Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+
+	seen := make(map[string]bool)
+	for field, text := range doc {
+		for _, term := range Tokenize(text) {
+			docs, ok := ix.postings[term]
+			if !ok {
+				docs = make(map[int]*[fieldCount]int)
+				ix.postings[term] = docs
+			}
+			counts, ok := docs[id]
+			if !ok {
+				counts = &[fieldCount]int{}
+				docs[id] = counts
+			}
+			counts[field]++
+
+			if !seen[term] {
+				seen[term] = true
+				ix.terms[id] = append(ix.terms[id], term)
+			}
+		}
+	}
+}
+
+// Remove retira o registro id do índice
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+// remove retira o registro do índice; o chamador deve deter o lock de escrita
+func (ix *Index) remove(id int) {
+	for _, term := range ix.terms[id] {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.terms, id)
+}
+
+// Search retorna os registros que contêm algum termo da consulta, ordenados
+// por relevância decrescente e, em caso de empate, por ID crescente.
+// Cada termo pontua tf × peso do campo × idf; termos que só casam por
+// prefixo (ex.: "tecl" → "teclado") pontuam com peso reduzido.
+func (ix *Index) Search(query string) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	total := float64(len(ix.terms))
+	scores := make(map[int]float64)
+	for _, term := range uniqueTerms(Tokenize(query)) {
+		ix.score(scores, term, 1, total)
+
+		if len([]rune(term)) < minPrefixLength {
+			continue
+		}
+		for candidate := range ix.postings {
+			if candidate != term && strings.HasPrefix(candidate, term) {
+				ix.score(scores, candidate, prefixWeight, total)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// score acumula a pontuação de um termo nos documentos que o contêm
+func (ix *Index) score(scores map[int]float64, term string, weight, total float64) {
+	docs := ix.postings[term]
+	if len(docs) == 0 {
+		return
+	}
+
+	idf := math.Log(1 + total/float64(len(docs)))
+	for id, counts := range docs {
+		for field, count := range counts {
+			if count > 0 {
+				scores[id] += weight * fieldWeights[field] * float64(count) * idf
+			}
+		}
+	}
+}
+
+// Normalize converte o texto para minúsculas e remove acentos ("Eletrônicos" → "eletronicos")
+func Normalize(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// Tokenize normaliza o texto e o divide em termos alfanuméricos, descartando stopwords
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, f := range fields {
+		if !stopwords[f] {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+// uniqueTerms remove termos repetidos preservando a ordem
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
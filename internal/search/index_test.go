@@ -0,0 +1,75 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"Eletrônicos", "eletronicos"},
+		{"ELETRÔNICOS", "eletronicos"},
+		{"eletronicos", "eletronicos"},
+		{"Ação Çedilha Pão Über", "acao cedilha pao uber"},
+		{"Mouse Logitech MX-3", "mouse logitech mx-3"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q): esperado %q, obtido %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Teclado Mecânico com LED", []string{"teclado", "mecanico", "led"}},
+		{"Cabo USB-C, 2m", []string{"cabo", "usb", "c", "2m"}},
+		{"de a o", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q): esperado %q, obtido %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestSearchFoldsAccentsAndCase(t *testing.T) {
+	ix := NewIndex()
+	ix.Put(1, Document{FieldName: "Fone Bluetooth", FieldCategory: "Eletrônicos"})
+	ix.Put(2, Document{FieldName: "ELETRÔNICOS em geral"})
+	ix.Put(3, Document{FieldName: "Cadeira", FieldCategory: "Móveis"})
+
+	for _, query := range []string{"eletronicos", "Eletrônicos", "ELETRONICOS", "eletrôn"} {
+		hits := ix.Search(query)
+		if len(hits) != 2 || hits[0].ID != 2 || hits[1].ID != 1 {
+			t.Errorf("Search(%q): esperado o produto 2 (nome) antes do 1 (categoria), obtido %+v", query, hits)
+		}
+	}
+	if hits := ix.Search("moveis"); len(hits) != 1 || hits[0].ID != 3 {
+		t.Errorf("Search(%q): esperado apenas o produto 3, obtido %+v", "moveis", hits)
+	}
+	if hits := ix.Search("el"); len(hits) != 0 {
+		t.Errorf("Search(%q): prefixos curtos não devem casar, obtido %+v", "el", hits)
+	}
+}
+
+func TestSearchFollowsUpdatesAndRemovals(t *testing.T) {
+	ix := NewIndex()
+	ix.Put(1, Document{FieldName: "Monitor"})
+	ix.Put(1, Document{FieldName: "Teclado"})
+	ix.Put(2, Document{FieldName: "Teclado sem fio"})
+	ix.Remove(2)
+
+	if hits := ix.Search("monitor"); len(hits) != 0 {
+		t.Errorf("Search após reindexar: esperado nenhum resultado, obtido %+v", hits)
+	}
+	if hits := ix.Search("teclado"); len(hits) != 1 || hits[0].ID != 1 {
+		t.Errorf("Search após remover: esperado apenas o produto 1, obtido %+v", hits)
+	}
+}
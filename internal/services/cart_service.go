@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+var (
+	ErrInvalidCartData  = errors.New("dados do carrinho inválidos")
+	ErrCartItemNotFound = errors.New("item não encontrado no carrinho")
+)
+
+// maxCartItems limita a quantidade de itens distintos (produto e variante) em um carrinho
+const maxCartItems = 100
+
+// CartService contém a lógica de negócio dos carrinhos de compras. Os itens
+// guardam o preço do produto no momento em que foram adicionados, e cada
+// leitura os compara com o catálogo atual para apontar pendências.
+type CartService struct {
+	repo     repositories.CartStore
+	users    *UserService
+	products *ProductService
+	ttl      time.Duration
+	// mu serializa as alterações, que leem e regravam o carrinho inteiro
+	mu sync.Mutex
+}
+
+// NewCartService cria uma nova instância do serviço de carrinhos; ttl é a
+// janela de inatividade após a qual um carrinho expira
+func NewCartService(repo repositories.CartStore, users *UserService, products *ProductService, ttl time.Duration) *CartService {
+	return &CartService{repo: repo, users: users, products: products, ttl: ttl}
+}
+
+// Get retorna o carrinho do usuário com as pendências de cada item; sem
+// carrinho vigente, retorna um carrinho vazio
+func (s *CartService) Get(ctx context.Context, userID int) (*models.Cart, error) {
+	cart, err := s.load(ctx, userID, cartNow())
+	if err != nil {
+		return nil, err
+	}
+	return s.annotate(ctx, cart)
+}
+
+// AddItem adiciona um produto ativo ao carrinho, registrando seu nome e preço
+// atuais; em produtos com variações, a variante é obrigatória e define preço
+// e estoque. A quantidade é limitada ao estoque disponível, descontadas as
+// reservas vigentes. Se o item já estiver no carrinho, a quantidade é somada
+// e o preço registrado passa a ser o atual.
+func (s *CartService) AddItem(ctx context.Context, userID int, req models.CartItemRequest) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := cartNow()
+	cart, err := s.load(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var v validator
+	v.positive("quantity", req.Quantity)
+	v.atMost("quantity", req.Quantity, maxStockQuantity)
+	var product *models.Product
+	var variant *models.Variant
+	if req.ProductID <= 0 {
+		v.add("product_id", CodeRequired, "field.required")
+	} else {
+		product, err = s.products.GetByID(ctx, req.ProductID)
+		switch {
+		case errors.Is(err, repositories.ErrProductNotFound):
+			v.add("product_id", CodeNotFound, "field.not_found")
+		case err != nil:
+			return nil, err
+		case !product.Active:
+			v.add("product_id", CodeInactive, "field.inactive")
+		case len(cart.Items) > 0 && product.Price.Currency != cart.Total.Currency:
+			v.add("product_id", CodeCurrencyMismatch, "field.currency_mismatch", product.Price.Currency, cart.Total.Currency)
+		}
+		if product != nil {
+			if variant, err = s.products.itemVariant(ctx, &v, "variant_id", *product, req.VariantID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	i := cartItemIndex(cart, req.ProductID, req.VariantID)
+	if i < 0 && len(cart.Items) >= maxCartItems {
+		v.add("product_id", CodeTooMany, "field.too_many_items", maxCartItems)
+	}
+	if err := v.err(ErrInvalidCartData); err != nil {
+		return nil, err
+	}
+
+	if i < 0 {
+		cart.Items = append(cart.Items, models.CartItem{ProductID: product.ID, VariantID: req.VariantID, AddedAt: now})
+		i = len(cart.Items) - 1
+	}
+	item := &cart.Items[i]
+	stock, price := product.Stock, product.Price
+	if variant != nil {
+		stock, price = variant.Stock, variant.Price
+		item.SKU = variant.SKU
+	}
+	available, err := s.products.available(ctx, product.ID, req.VariantID, stock)
+	if err != nil {
+		return nil, err
+	}
+	if available < item.Quantity+req.Quantity {
+		return nil, i18n.Errorf(ErrInsufficientStock, "cart_stock", available)
+	}
+	item.Name = product.Name
+	item.UnitPrice = price
+	item.Quantity += req.Quantity
+	cart.Total.Currency = product.Price.Currency
+	if err := checkCartTotal(cart, i); err != nil {
+		return nil, err
+	}
+
+	return s.save(ctx, cart, now)
+}
+
+// UpdateItem altera a quantidade de um item, identificado pelo produto e pela
+// variante (zero nos produtos sem variações), mantendo o preço registrado.
+// Aumentos são limitados ao estoque disponível do produto ou da variante.
+func (s *CartService) UpdateItem(ctx context.Context, userID, productID, variantID int, req models.CartItemUpdateRequest) (*models.Cart, error) {
+	var v validator
+	v.positive("quantity", req.Quantity)
+	v.atMost("quantity", req.Quantity, maxStockQuantity)
+	if err := v.err(ErrInvalidCartData); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := cartNow()
+	cart, err := s.load(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	i := cartItemIndex(cart, productID, variantID)
+	if i < 0 {
+		return nil, ErrCartItemNotFound
+	}
+
+	if req.Quantity > cart.Items[i].Quantity {
+		available, err := s.itemAvailable(ctx, cart.Items[i])
+		if err != nil && !errors.Is(err, repositories.ErrProductNotFound) && !errors.Is(err, ErrVariantNotFound) {
+			return nil, err
+		}
+		if err == nil && available < req.Quantity {
+			return nil, i18n.Errorf(ErrInsufficientStock, "cart_stock", available)
+		}
+	}
+	cart.Items[i].Quantity = req.Quantity
+	if err := checkCartTotal(cart, i); err != nil {
+		return nil, err
+	}
+
+	return s.save(ctx, cart, now)
+}
+
+// RemoveItem retira um item do carrinho, identificado como em UpdateItem
+func (s *CartService) RemoveItem(ctx context.Context, userID, productID, variantID int) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := cartNow()
+	cart, err := s.load(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	i := cartItemIndex(cart, productID, variantID)
+	if i < 0 {
+		return nil, ErrCartItemNotFound
+	}
+	cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+
+	return s.save(ctx, cart, now)
+}
+
+// Clear descarta o carrinho do usuário e retorna um carrinho vazio
+func (s *CartService) Clear(ctx context.Context, userID int) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := cartNow()
+	cart, err := s.load(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Delete(ctx, userID); err != nil && !errors.Is(err, repositories.ErrCartNotFound) {
+		return nil, err
+	}
+	return s.emptyCart(cart.UserID, now), nil
+}
+
+// PurgeExpired remove os carrinhos que já expiraram
+func (s *CartService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.repo.PurgeExpired(ctx, cartNow())
+}
+
+// load retorna o carrinho vigente do usuário ou um carrinho vazio; o usuário
+// precisa existir fora da lixeira
+func (s *CartService) load(ctx context.Context, userID int, now time.Time) (*models.Cart, error) {
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	cart, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, repositories.ErrCartNotFound) || (err == nil && cart.Expired(now)) {
+		return s.emptyCart(userID, now), nil
+	}
+	return cart, err
+}
+
+// save renova a expiração do carrinho e o grava, retornando-o com as pendências
+func (s *CartService) save(ctx context.Context, cart *models.Cart, now time.Time) (*models.Cart, error) {
+	cart.UpdatedAt = now
+	cart.ExpiresAt = now.Add(s.ttl)
+	saved, err := s.repo.Save(ctx, *cart)
+	if err != nil {
+		return nil, err
+	}
+	return s.annotate(ctx, saved)
+}
+
+// annotate compara cada item com o produto (ou a variante) atual, preenchendo
+// o preço atual e as pendências (preço alterado, produto inativo, excluído ou
+// sem estoque disponível)
+func (s *CartService) annotate(ctx context.Context, cart *models.Cart) (*models.Cart, error) {
+	cart.HasIssues = false
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		item.CurrentPrice, item.Issues = nil, nil
+
+		product, err := s.products.GetByID(ctx, item.ProductID)
+		var variant *models.Variant
+		if err == nil && item.VariantID != 0 {
+			variant, err = s.products.GetVariant(ctx, item.ProductID, item.VariantID)
+		}
+		if errors.Is(err, repositories.ErrProductNotFound) || errors.Is(err, ErrVariantNotFound) {
+			item.Issues = append(item.Issues, models.CartIssueUnavailable)
+			cart.HasIssues = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		price, stock, active := product.Price, product.Stock, product.Active
+		if variant != nil {
+			price, stock, active = variant.Price, variant.Stock, active && variant.Active
+		}
+		available, err := s.products.available(ctx, item.ProductID, item.VariantID, stock)
+		if err != nil {
+			return nil, err
+		}
+		item.CurrentPrice = &price
+		if price != item.UnitPrice {
+			item.Issues = append(item.Issues, models.CartIssuePriceChanged)
+		}
+		if !active {
+			item.Issues = append(item.Issues, models.CartIssueInactive)
+		}
+		switch {
+		case available <= 0:
+			item.Issues = append(item.Issues, models.CartIssueOutOfStock)
+		case available < item.Quantity:
+			item.Issues = append(item.Issues, models.CartIssueInsufficientStock)
+		}
+		if len(item.Issues) > 0 {
+			cart.HasIssues = true
+		}
+	}
+	return cart, nil
+}
+
+// emptyCart cria um carrinho sem itens que expira ttl após now
+func (s *CartService) emptyCart(userID int, now time.Time) *models.Cart {
+	return &models.Cart{
+		UserID:    userID,
+		Items:     []models.CartItem{},
+		Total:     models.Money{Currency: models.DefaultCurrency},
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+}
+
+// itemAvailable retorna o estoque disponível do produto do item ou, se
+// houver, da variante
+func (s *CartService) itemAvailable(ctx context.Context, item models.CartItem) (int, error) {
+	stock := 0
+	if item.VariantID != 0 {
+		variant, err := s.products.GetVariant(ctx, item.ProductID, item.VariantID)
+		if err != nil {
+			return 0, err
+		}
+		stock = variant.Stock
+	} else {
+		product, err := s.products.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return 0, err
+		}
+		stock = product.Stock
+	}
+	return s.products.available(ctx, item.ProductID, item.VariantID, stock)
+}
+
+// cartItemIndex retorna o índice do item do produto e da variante no carrinho ou -1
+func cartItemIndex(cart *models.Cart, productID, variantID int) int {
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID && cart.Items[i].VariantID == variantID {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkCartTotal verifica se os subtotais e o total do carrinho cabem em
+// int64, registrando a violação na quantidade do item changed
+func checkCartTotal(cart *models.Cart, changed int) error {
+	var v validator
+	total := models.Money{Currency: cart.Total.Currency}
+	for i, item := range cart.Items {
+		if i != changed {
+			total = v.subtotal("quantity", total, item.UnitPrice, item.Quantity)
+		}
+	}
+	v.subtotal("quantity", total, cart.Items[changed].UnitPrice, cart.Items[changed].Quantity)
+	return v.err(ErrInvalidCartData)
+}
+
+// cartNow retorna o instante atual com a precisão gravada nos carrinhos
+func cartNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+// reserve retém quantity unidades do produto fora do carrinho
+func (f *serviceFixture) reserve(t *testing.T, productID, quantity int) {
+	t.Helper()
+	if _, err := f.products.Reserve(context.Background(), productID, models.StockRequest{Quantity: quantity}); err != nil {
+		t.Fatalf("Reserve(%d, %d): %v", productID, quantity, err)
+	}
+}
+
+// changeProduct aplica change ao produto diretamente no repositório
+func (f *serviceFixture) changeProduct(t *testing.T, productID int, change func(*models.Product)) {
+	t.Helper()
+	ctx := context.Background()
+	product, err := f.stock.GetByID(ctx, productID)
+	if err != nil {
+		t.Fatalf("GetByID(%d): %v", productID, err)
+	}
+	change(product)
+	if _, err := f.stock.Update(ctx, productID, *product, repositories.StockChange{Type: models.MovementAdjustment, At: time.Now().UTC()}); err != nil {
+		t.Fatalf("Update(%d): %v", productID, err)
+	}
+}
+
+func TestCartLimitsItemsToAvailableStock(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	stock := f.stockOf(t, 2)
+	f.reserve(t, 2, stock-2)
+
+	if _, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: 2, Quantity: 3}); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("AddItem além do disponível: esperado ErrInsufficientStock, obtido %v", err)
+	}
+	cart, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: 2, Quantity: 1})
+	if err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if cart.HasIssues {
+		t.Errorf("AddItem: pendências inesperadas %+v", cart.Items)
+	}
+	if _, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: 2, Quantity: 2}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("AddItem somando além do disponível: esperado ErrInsufficientStock, obtido %v", err)
+	}
+	if _, err := f.carts.UpdateItem(ctx, 1, 2, 0, models.CartItemUpdateRequest{Quantity: 3}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("UpdateItem além do disponível: esperado ErrInsufficientStock, obtido %v", err)
+	}
+	cart, err = f.carts.UpdateItem(ctx, 1, 2, 0, models.CartItemUpdateRequest{Quantity: 2})
+	if err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if cart.Items[0].Quantity != 2 {
+		t.Errorf("UpdateItem: esperado quantidade 2, obtido %d", cart.Items[0].Quantity)
+	}
+}
+
+func TestCartRejectsTotalsBeyondInt64(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	var ids [2]int
+	for i := range ids {
+		product, err := f.stock.Create(ctx, models.Product{
+			Name:       fmt.Sprintf("Servidor %d", i+1),
+			Price:      models.Money{Amount: maxMoneyAmount, Currency: "BRL"},
+			CategoryID: 1,
+			Stock:      maxStockQuantity,
+			Active:     true,
+		}, repositories.StockChange{Type: models.MovementAdjustment, At: time.Now().UTC()})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids[i] = product.ID
+	}
+
+	var validation *ValidationError
+	if _, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: ids[0], Quantity: math.MaxInt}); !errors.As(err, &validation) {
+		t.Errorf("AddItem com quantidade enorme: esperado *ValidationError, obtido %v", err)
+	}
+	if _, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: ids[0], Quantity: 100_000_000}); !errors.As(err, &validation) || !errors.Is(err, ErrInvalidCartData) {
+		t.Fatalf("AddItem além do limite: esperado *ValidationError, obtido %v", err)
+	}
+	if len(validation.Fields) != 1 || validation.Fields[0].Field != "quantity" || validation.Fields[0].Code != CodeTooLarge {
+		t.Errorf("AddItem além do limite: violações inesperadas %+v", validation.Fields)
+	}
+
+	if _, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: ids[0], Quantity: 50_000_000}); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if _, err := f.carts.AddItem(ctx, 1, models.CartItemRequest{ProductID: ids[1], Quantity: 1}); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if _, err := f.carts.UpdateItem(ctx, 1, ids[1], 0, models.CartItemUpdateRequest{Quantity: 50_000_000}); !errors.As(err, &validation) {
+		t.Errorf("UpdateItem com total além do limite: esperado *ValidationError, obtido %v", err)
+	}
+	cart, err := f.carts.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if cart.Items[1].Quantity != 1 || cart.Total.Amount != 50_000_001*maxMoneyAmount {
+		t.Errorf("Get: carrinho alterado %+v", cart)
+	}
+}
+
+func TestCartIssues(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	for _, item := range []models.CartItemRequest{
+		{ProductID: 1, Quantity: 1},
+		{ProductID: 2, Quantity: 1},
+		{ProductID: 3, Quantity: 5},
+		{ProductID: 4, Quantity: 2},
+	} {
+		if _, err := f.carts.AddItem(ctx, 1, item); err != nil {
+			t.Fatalf("AddItem(%d): %v", item.ProductID, err)
+		}
+	}
+
+	f.changeProduct(t, 1, func(p *models.Product) { p.Price.Amount += 1000 })
+	f.changeProduct(t, 2, func(p *models.Product) { p.Active = false })
+	f.reserve(t, 3, f.stockOf(t, 3)-4)
+	f.reserve(t, 4, f.stockOf(t, 4))
+	cart, err := f.carts.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	want := map[int][]string{
+		1: {models.CartIssuePriceChanged},
+		2: {models.CartIssueInactive},
+		3: {models.CartIssueInsufficientStock},
+		4: {models.CartIssueOutOfStock},
+	}
+	for _, item := range cart.Items {
+		if !reflect.DeepEqual(item.Issues, want[item.ProductID]) {
+			t.Errorf("Get: produto %d com pendências %v, esperado %v", item.ProductID, item.Issues, want[item.ProductID])
+		}
+	}
+	if !cart.HasIssues {
+		t.Error("Get: esperado has_issues")
+	}
+	if price := cart.Items[0].CurrentPrice; price == nil || price.Amount != cart.Items[0].UnitPrice.Amount+1000 {
+		t.Errorf("Get: preço atual inesperado %+v", price)
+	}
+
+	product, err := f.stock.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if err := f.stock.Delete(ctx, 1, product.Version, time.Now().UTC()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	cart, err = f.carts.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := cart.Items[0].Issues; !reflect.DeepEqual(got, []string{models.CartIssueUnavailable}) || cart.Items[0].CurrentPrice != nil {
+		t.Errorf("Get: produto excluído com pendências %v e preço %+v, esperado unavailable", got, cart.Items[0].CurrentPrice)
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/search"
+)
+
+var (
+	ErrInvalidCategoryData = errors.New("dados da categoria inválidos")
+	// ErrCategoryNotEmpty indica que a categoria ainda tem subcategorias ou produtos
+	ErrCategoryNotEmpty = errors.New("categoria não está vazia")
+	// ErrCategoryNotFound e ErrSlugExists são os mesmos valores do repositório,
+	// para que errors.Is funcione em qualquer camada
+	ErrCategoryNotFound = repositories.ErrCategoryNotFound
+	ErrSlugExists       = repositories.ErrSlugExists
+)
+
+// defaultCategorySlug identifica a categoria dos produtos cadastrados sem categoria
+const defaultCategorySlug = "geral"
+
+// slugPattern define o formato aceito para o slug de uma categoria
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// CategoryService contém a lógica de negócio das categorias de produtos
+type CategoryService struct {
+	repo     repositories.CategoryStore
+	products repositories.ProductStore
+	// mu serializa as alterações, para que a verificação de ciclos e de
+	// categorias vazias veja a árvore que será gravada
+	mu sync.Mutex
+}
+
+// NewCategoryService cria uma nova instância do serviço de categorias
+func NewCategoryService(repo repositories.CategoryStore, products repositories.ProductStore) *CategoryService {
+	return &CategoryService{repo: repo, products: products}
+}
+
+// GetTree retorna as categorias raiz com suas subcategorias aninhadas e a
+// quantidade de produtos de cada uma
+func (s *CategoryService) GetTree(ctx context.Context) ([]models.Category, error) {
+	categories, counts, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := []models.Category{}
+	for _, c := range categories {
+		if c.ParentID == nil || categoryIndex(categories, *c.ParentID) < 0 {
+			roots = append(roots, categoryNode(categories, counts, c))
+		}
+	}
+	return roots, nil
+}
+
+// GetByID retorna uma categoria com suas subcategorias aninhadas
+func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	if id <= 0 {
+		return nil, ErrInvalidCategoryData
+	}
+
+	categories, counts, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := categoryIndex(categories, id)
+	if i < 0 {
+		return nil, ErrCategoryNotFound
+	}
+	node := categoryNode(categories, counts, categories[i])
+	return &node, nil
+}
+
+// Create cria uma categoria; sem slug, ele é gerado a partir do nome
+func (s *CategoryService) Create(ctx context.Context, req models.CategoryRequest) (*models.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	category, err := applyCategoryRequest(models.Category{}, req, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, created.ID)
+}
+
+// Update substitui nome, slug e pai de uma categoria. A nova posição não pode
+// estar dentro da própria subárvore, e uma mudança de nome é propagada aos produtos.
+func (s *CategoryService) Update(ctx context.Context, id int, req models.CategoryRequest) (*models.Category, error) {
+	if id <= 0 {
+		return nil, ErrInvalidCategoryData
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := categoryIndex(categories, id)
+	if i < 0 {
+		return nil, ErrCategoryNotFound
+	}
+	existing := categories[i]
+	category, err := applyCategoryRequest(existing, req, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Update(ctx, id, category); err != nil {
+		return nil, err
+	}
+	if category.Name != existing.Name {
+		if err := s.products.RenameCategory(ctx, id, category.Name); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetByID(ctx, id)
+}
+
+// Delete remove uma categoria sem subcategorias e sem produtos, incluindo os
+// que estão na lixeira
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidCategoryData
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	if categoryIndex(categories, id) < 0 {
+		return ErrCategoryNotFound
+	}
+	if children := len(subtreeIDs(categories, id)) - 1; children > 0 {
+		return i18n.Errorf(ErrCategoryNotEmpty, "category_has_children", children)
+	}
+
+	counts, err := s.products.CountByCategory(ctx, true)
+	if err != nil {
+		return err
+	}
+	if counts[id] > 0 {
+		return i18n.Errorf(ErrCategoryNotEmpty, "category_has_products", counts[id])
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// load retorna todas as categorias e a quantidade de produtos fora da lixeira de cada uma
+func (s *CategoryService) load(ctx context.Context) ([]models.Category, map[int]int, error) {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := s.products.CountByCategory(ctx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return categories, counts, nil
+}
+
+// applyCategoryRequest valida a requisição contra as categorias existentes e
+// copia seus campos para a categoria. Todas as violações são reportadas
+// juntas em um *ValidationError.
+func applyCategoryRequest(category models.Category, req models.CategoryRequest, categories []models.Category) (models.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	slug := strings.TrimSpace(req.Slug)
+	if slug == "" {
+		slug = slugify(name)
+	}
+
+	var v validator
+	v.length("name", name, 1, maxCategoryLength)
+	if name != "" || slug != "" {
+		switch {
+		case utf8.RuneCountInString(slug) > maxCategoryLength:
+			v.add("slug", CodeTooLong, "field.too_long", maxCategoryLength)
+		case !slugPattern.MatchString(slug):
+			v.add("slug", CodeInvalidFormat, "field.invalid_slug")
+		}
+	}
+	if req.ParentID != nil {
+		switch {
+		case categoryIndex(categories, *req.ParentID) < 0:
+			v.add("parent_id", CodeNotFound, "field.not_found")
+		case category.ID != 0 && containsID(subtreeIDs(categories, category.ID), *req.ParentID):
+			v.add("parent_id", CodeCycle, "field.cycle")
+		}
+	}
+	if err := v.err(ErrInvalidCategoryData); err != nil {
+		return category, err
+	}
+
+	category.Name = name
+	category.Slug = slug
+	category.ParentID = req.ParentID
+	return category, nil
+}
+
+// categoryNode monta a categoria com suas subcategorias aninhadas, em ordem
+// de ID, e as quantidades de produtos
+func categoryNode(categories []models.Category, counts map[int]int, c models.Category) models.Category {
+	c.ProductCount = counts[c.ID]
+	c.TotalProductCount = c.ProductCount
+	c.Children = []models.Category{}
+	for _, child := range categories {
+		if child.ParentID != nil && *child.ParentID == c.ID && child.ID != c.ID {
+			node := categoryNode(categories, counts, child)
+			c.TotalProductCount += node.TotalProductCount
+			c.Children = append(c.Children, node)
+		}
+	}
+	return c
+}
+
+// subtreeIDs retorna o ID da categoria seguido dos IDs de todas as suas subcategorias
+func subtreeIDs(categories []models.Category, id int) []int {
+	ids := []int{id}
+	for i := 0; i < len(ids); i++ {
+		for _, c := range categories {
+			if c.ParentID != nil && *c.ParentID == ids[i] && !containsID(ids, c.ID) {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// findCategory retorna a categoria cujo slug ou nome corresponde a ref,
+// ignorando acentos e maiúsculas, ou nil se nenhuma corresponder
+func findCategory(categories []models.Category, ref string) *models.Category {
+	slug := slugify(ref)
+	name := search.Normalize(strings.TrimSpace(ref))
+	for i := range categories {
+		if categories[i].Slug == slug || search.Normalize(categories[i].Name) == name {
+			return &categories[i]
+		}
+	}
+	return nil
+}
+
+// categoryIndex retorna a posição da categoria com o ID ou -1
+func categoryIndex(categories []models.Category, id int) int {
+	for i := range categories {
+		if categories[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsID indica se id está entre os IDs informados
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// slugify gera um slug a partir do texto: sem acentos, em minúsculas e com
+// hífens no lugar de espaços e pontuação
+func slugify(text string) string {
+	return strings.Join(strings.FieldsFunc(search.Normalize(text), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}), "-")
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// basket é uma cesta em avaliação: os itens com o preço atual do catálogo, a
+// categoria do produto de cada um e o contexto usado nas regras das promoções
+type basket struct {
+	items      []models.EvaluationItem
+	categories []int
+	currency   string
+	userID     int
+	// userUses é a quantidade de resgates vigentes do usuário por promoção
+	userUses map[int]int
+	now      time.Time
+}
+
+// discountCandidate é uma promoção aplicável à cesta e os índices dos itens elegíveis
+type discountCandidate struct {
+	promotion models.Promotion
+	items     []int
+}
+
+// discountPlan é o resultado do cálculo de uma combinação de promoções
+type discountPlan struct {
+	applied []models.AppliedPromotion
+	// shares são os descontos de cada item da cesta, por promoção
+	shares [][]models.ItemDiscount
+	total  int64
+	// idle são as promoções que não descontaram nada, porque os itens
+	// elegíveis já estavam inteiramente descontados
+	idle []models.Promotion
+}
+
+// eligibleItems retorna os índices dos itens da cesta no escopo da promoção.
+// scope contém as categorias da promoção e todas as suas subcategorias.
+func eligibleItems(p models.Promotion, scope map[int]bool, b basket) []int {
+	var items []int
+	for i, item := range b.items {
+		all := len(p.CategoryIDs) == 0 && len(p.ProductIDs) == 0
+		if all || containsID(p.ProductIDs, item.ProductID) || scope[b.categories[i]] {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+// promotionRejection retorna o motivo (models.Rejection*) pelo qual a promoção
+// não se aplica à cesta, ou vazio se ela for aplicável aos itens elegíveis
+func promotionRejection(p models.Promotion, items []int, b basket) string {
+	quantity := 0
+	for _, i := range items {
+		quantity += b.items[i].Quantity
+	}
+	switch {
+	case !p.Active:
+		return models.RejectionInactive
+	case p.StartsAt != nil && b.now.Before(*p.StartsAt):
+		return models.RejectionNotStarted
+	case p.EndsAt != nil && !b.now.Before(*p.EndsAt):
+		return models.RejectionExpired
+	case p.MaxUses > 0 && p.Uses >= p.MaxUses:
+		return models.RejectionExhausted
+	case p.MaxUsesPerUser > 0 && b.userID == 0:
+		return models.RejectionUserRequired
+	case p.MaxUsesPerUser > 0 && b.userUses[p.ID] >= p.MaxUsesPerUser:
+		return models.RejectionUserLimit
+	case p.Type == models.DiscountFixed && p.Amount != nil && p.Amount.Currency != b.currency:
+		return models.RejectionCurrency
+	case len(items) == 0:
+		return models.RejectionNoEligible
+	case quantity < p.MinQuantity:
+		return models.RejectionMinQuantity
+	}
+	return ""
+}
+
+// bestPlan escolhe a combinação de promoções com o maior desconto: todas as
+// cumulativas juntas ou cada não cumulativa sozinha. Em caso de empate, vencem
+// as cumulativas e, entre as não cumulativas, a de menor ID. Retorna também
+// as promoções preteridas.
+func bestPlan(b basket, candidates []discountCandidate) (discountPlan, []models.Promotion) {
+	var stackable, exclusive []discountCandidate
+	for _, c := range candidates {
+		if c.promotion.Stackable {
+			stackable = append(stackable, c)
+		} else {
+			exclusive = append(exclusive, c)
+		}
+	}
+	// os cupons chegam depois das promoções automáticas, então os candidatos
+	// não estão necessariamente em ordem de ID
+	sort.SliceStable(exclusive, func(i, j int) bool {
+		return exclusive[i].promotion.ID < exclusive[j].promotion.ID
+	})
+
+	best, chosen := planDiscounts(b, stackable), stackable
+	for _, c := range exclusive {
+		if plan := planDiscounts(b, []discountCandidate{c}); plan.total > best.total {
+			best, chosen = plan, []discountCandidate{c}
+		}
+	}
+
+	var passed []models.Promotion
+	for _, c := range candidates {
+		if !containsCandidate(chosen, c.promotion.ID) {
+			passed = append(passed, c.promotion)
+		}
+	}
+	return best, passed
+}
+
+// planDiscounts aplica as promoções em sequência, sempre sobre o valor ainda
+// não descontado de cada item: primeiro as percentuais, depois as de valor
+// fixo, cada grupo em ordem de ID
+func planDiscounts(b basket, candidates []discountCandidate) discountPlan {
+	ordered := append([]discountCandidate(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, c := ordered[i].promotion, ordered[j].promotion
+		if (a.Type == models.DiscountPercentage) != (c.Type == models.DiscountPercentage) {
+			return a.Type == models.DiscountPercentage
+		}
+		return a.ID < c.ID
+	})
+
+	remaining := make([]int64, len(b.items))
+	for i, item := range b.items {
+		remaining[i] = item.Subtotal.Amount
+	}
+	plan := discountPlan{shares: make([][]models.ItemDiscount, len(b.items))}
+	for _, c := range ordered {
+		shares := discountShares(c, remaining)
+		var amount int64
+		for _, i := range c.items {
+			if shares[i] == 0 {
+				continue
+			}
+			remaining[i] -= shares[i]
+			amount += shares[i]
+			plan.shares[i] = append(plan.shares[i], models.ItemDiscount{
+				PromotionID: c.promotion.ID,
+				Amount:      models.Money{Amount: shares[i], Currency: b.currency},
+			})
+		}
+		if amount == 0 {
+			plan.idle = append(plan.idle, c.promotion)
+			continue
+		}
+		plan.total += amount
+		plan.applied = append(plan.applied, models.AppliedPromotion{
+			PromotionID: c.promotion.ID,
+			Name:        c.promotion.Name,
+			Code:        c.promotion.Code,
+			Type:        c.promotion.Type,
+			Amount:      models.Money{Amount: amount, Currency: b.currency},
+		})
+	}
+	return plan
+}
+
+// discountShares calcula o desconto da promoção em cada item elegível, sem
+// ultrapassar o valor restante do item. O percentual é arredondado para a
+// unidade menor mais próxima em cada item; o valor fixo é limitado ao total
+// restante dos itens elegíveis e repartido na proporção desse valor, com os
+// centavos da divisão atribuídos aos primeiros itens.
+func discountShares(c discountCandidate, remaining []int64) map[int]int64 {
+	shares := make(map[int]int64, len(c.items))
+	if c.promotion.Type == models.DiscountPercentage {
+		for _, i := range c.items {
+			shares[i] = minAmount(percentOf(remaining[i], int64(c.promotion.Percentage)), remaining[i])
+		}
+		return shares
+	}
+
+	var base int64
+	for _, i := range c.items {
+		base += remaining[i]
+	}
+	if base == 0 || c.promotion.Amount == nil {
+		return shares
+	}
+	off := minAmount(c.promotion.Amount.Amount, base)
+	left := off
+	for _, i := range c.items {
+		shares[i] = proportion(off, remaining[i], base)
+		left -= shares[i]
+	}
+	for _, i := range c.items {
+		if left == 0 {
+			break
+		}
+		if shares[i] < remaining[i] {
+			shares[i]++
+			left--
+		}
+	}
+	return shares
+}
+
+// containsCandidate indica se a promoção está entre os candidatos
+func containsCandidate(candidates []discountCandidate, promotionID int) bool {
+	for _, c := range candidates {
+		if c.promotion.ID == promotionID {
+			return true
+		}
+	}
+	return false
+}
+
+// percentOf retorna percentage% de amount, arredondado para o valor mais
+// próximo, sem multiplicar amount inteiro (o que poderia estourar int64)
+func percentOf(amount, percentage int64) int64 {
+	return amount/100*percentage + (amount%100*percentage+50)/100
+}
+
+// proportion retorna amount × part / whole, truncado; o produto é calculado
+// em 128 bits, então não estoura int64. Exige 0 <= part <= whole e amount >= 0.
+func proportion(amount, part, whole int64) int64 {
+	hi, lo := bits.Mul64(uint64(amount), uint64(part))
+	quotient, _ := bits.Div64(hi, lo, uint64(whole))
+	return int64(quotient)
+}
+
+// minAmount retorna o menor de dois valores
+func minAmount(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
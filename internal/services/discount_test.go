@@ -0,0 +1,275 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+var discountNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testBasket monta uma cesta em BRL com um item por subtotal, em centavos
+func testBasket(subtotals ...int64) basket {
+	b := basket{currency: "BRL", userID: 1, now: discountNow}
+	for i, subtotal := range subtotals {
+		b.items = append(b.items, models.EvaluationItem{
+			ProductID: i + 1,
+			Quantity:  1,
+			UnitPrice: models.Money{Amount: subtotal, Currency: "BRL"},
+			Subtotal:  models.Money{Amount: subtotal, Currency: "BRL"},
+		})
+		b.categories = append(b.categories, 1)
+	}
+	return b
+}
+
+// percentOff é uma promoção percentual sobre os itens informados
+func percentOff(id, percentage int, stackable bool, items ...int) discountCandidate {
+	return discountCandidate{
+		promotion: models.Promotion{ID: id, Type: models.DiscountPercentage, Percentage: percentage, Stackable: stackable, Active: true},
+		items:     items,
+	}
+}
+
+// amountOff é uma promoção de valor fixo, em centavos, sobre os itens informados
+func amountOff(id int, cents int64, stackable bool, items ...int) discountCandidate {
+	return discountCandidate{
+		promotion: models.Promotion{ID: id, Type: models.DiscountFixed, Amount: &models.Money{Amount: cents, Currency: "BRL"}, Stackable: stackable, Active: true},
+		items:     items,
+	}
+}
+
+func TestDiscountShares(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate discountCandidate
+		remaining []int64
+		want      []int64
+	}{
+		{"percentual arredonda meio centavo para cima", percentOff(1, 10, true, 0), []int64{1995}, []int64{200}},
+		{"percentual arredonda para baixo", percentOff(1, 10, true, 0), []int64{1994}, []int64{199}},
+		{"percentual arredonda cada item", percentOff(1, 15, true, 0, 1), []int64{333, 1001}, []int64{50, 150}},
+		{"percentual de 100% zera o item", percentOff(1, 100, true, 0), []int64{999}, []int64{999}},
+		{"percentual ignora itens fora do escopo", percentOff(1, 50, true, 1), []int64{1000, 1000}, []int64{0, 500}},
+		{"percentual sobre item já descontado", percentOff(1, 50, true, 0), []int64{0}, []int64{0}},
+		{"fixo repartido na proporção do valor", amountOff(1, 1000, true, 0, 1), []int64{3000, 1000}, []int64{750, 250}},
+		{"fixo atribui os centavos da divisão aos primeiros itens", amountOff(1, 100, true, 0, 1, 2), []int64{333, 333, 334}, []int64{34, 33, 33}},
+		{"fixo com centavos que pulam item zerado", amountOff(1, 2, true, 0, 1, 2), []int64{0, 1, 1}, []int64{0, 1, 1}},
+		{"fixo maior que o subtotal é limitado", amountOff(1, 5000, true, 0, 1), []int64{1000, 2000}, []int64{1000, 2000}},
+		{"fixo sem valor restante", amountOff(1, 1000, true, 0), []int64{0}, []int64{0}},
+		{"fixo só nos itens do escopo", amountOff(1, 1000, true, 1), []int64{500, 800}, []int64{0, 800}},
+		{"percentual sobre valor perto do limite", percentOff(1, 10, true, 0), []int64{math.MaxInt64}, []int64{922337203685477581}},
+		{"fixo repartido sobre valores perto do limite", amountOff(1, 1000000000000000001, true, 0, 1), []int64{3000000000000000000, 6000000000000000000}, []int64{333333333333333334, 666666666666666667}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := discountShares(tt.candidate, tt.remaining)
+			got := make([]int64, len(tt.remaining))
+			for i, share := range shares {
+				got[i] = share
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discountShares: esperado %v, obtido %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPlanDiscounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		basket     basket
+		candidates []discountCandidate
+		applied    []int
+		amounts    []int64
+		idle       []int
+		total      int64
+	}{
+		{
+			name:       "percentual antes do fixo, independente do ID",
+			basket:     testBasket(10000),
+			candidates: []discountCandidate{amountOff(1, 1000, true, 0), percentOff(2, 10, true, 0)},
+			applied:    []int{2, 1},
+			amounts:    []int64{1000, 1000},
+			total:      2000,
+		},
+		{
+			name:       "percentuais em sequência sobre o valor restante",
+			basket:     testBasket(1000),
+			candidates: []discountCandidate{percentOff(2, 50, true, 0), percentOff(1, 10, true, 0)},
+			applied:    []int{1, 2},
+			amounts:    []int64{100, 450},
+			total:      550,
+		},
+		{
+			name:       "percentual arredondado item a item",
+			basket:     testBasket(1995, 1994),
+			candidates: []discountCandidate{percentOff(1, 10, true, 0, 1)},
+			applied:    []int{1},
+			amounts:    []int64{399},
+			total:      399,
+		},
+		{
+			name:       "fixo maior que o subtotal deixa o seguinte ocioso",
+			basket:     testBasket(3000),
+			candidates: []discountCandidate{amountOff(1, 5000, true, 0), amountOff(2, 100, true, 0)},
+			applied:    []int{1},
+			amounts:    []int64{3000},
+			idle:       []int{2},
+			total:      3000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planDiscounts(tt.basket, tt.candidates)
+			var applied []int
+			var amounts []int64
+			for _, a := range plan.applied {
+				applied = append(applied, a.PromotionID)
+				amounts = append(amounts, a.Amount.Amount)
+			}
+			if !reflect.DeepEqual(applied, tt.applied) || !reflect.DeepEqual(amounts, tt.amounts) {
+				t.Errorf("planDiscounts: esperado %v com %v, obtido %v com %v", tt.applied, tt.amounts, applied, amounts)
+			}
+			if got := promotionIDs(plan.idle); !reflect.DeepEqual(got, tt.idle) {
+				t.Errorf("planDiscounts: esperado ociosas %v, obtido %v", tt.idle, got)
+			}
+			if plan.total != tt.total {
+				t.Errorf("planDiscounts: esperado total %d, obtido %d", tt.total, plan.total)
+			}
+
+			// os descontos por item somam o total e nunca passam do subtotal
+			var sum int64
+			for i, shares := range plan.shares {
+				var item int64
+				for _, share := range shares {
+					item += share.Amount.Amount
+				}
+				if item > tt.basket.items[i].Subtotal.Amount {
+					t.Errorf("planDiscounts: item %d descontado em %d, acima do subtotal", i, item)
+				}
+				sum += item
+			}
+			if sum != plan.total {
+				t.Errorf("planDiscounts: descontos por item somam %d, total %d", sum, plan.total)
+			}
+		})
+	}
+}
+
+func TestBestPlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []discountCandidate
+		applied    []int
+		passed     []int
+		total      int64
+	}{
+		{
+			name:       "cumulativas juntas superam a não cumulativa",
+			candidates: []discountCandidate{percentOff(1, 10, true, 0), percentOff(2, 10, true, 0), percentOff(3, 15, false, 0)},
+			applied:    []int{1, 2},
+			passed:     []int{3},
+			total:      1900,
+		},
+		{
+			name:       "não cumulativa supera as cumulativas",
+			candidates: []discountCandidate{percentOff(1, 10, true, 0), percentOff(2, 10, true, 0), percentOff(3, 25, false, 0)},
+			applied:    []int{3},
+			passed:     []int{1, 2},
+			total:      2500,
+		},
+		{
+			name:       "empate favorece as cumulativas",
+			candidates: []discountCandidate{amountOff(1, 2000, false, 0), percentOff(2, 20, true, 0)},
+			applied:    []int{2},
+			passed:     []int{1},
+			total:      2000,
+		},
+		{
+			name:       "empate entre não cumulativas favorece o menor ID",
+			candidates: []discountCandidate{amountOff(4, 1000, false, 0), amountOff(3, 1000, false, 0)},
+			applied:    []int{3},
+			passed:     []int{4},
+			total:      1000,
+		},
+		{
+			name:       "não cumulativa maior que o subtotal é limitada",
+			candidates: []discountCandidate{amountOff(1, 50000, false, 0), percentOff(2, 50, true, 0)},
+			applied:    []int{1},
+			passed:     []int{2},
+			total:      10000,
+		},
+		{
+			name:       "sem candidatos",
+			candidates: nil,
+			total:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, passed := bestPlan(testBasket(10000), tt.candidates)
+			var applied []int
+			for _, a := range plan.applied {
+				applied = append(applied, a.PromotionID)
+			}
+			if !reflect.DeepEqual(applied, tt.applied) {
+				t.Errorf("bestPlan: esperado aplicadas %v, obtido %v", tt.applied, applied)
+			}
+			if got := promotionIDs(passed); !reflect.DeepEqual(got, tt.passed) {
+				t.Errorf("bestPlan: esperado preteridas %v, obtido %v", tt.passed, got)
+			}
+			if plan.total != tt.total {
+				t.Errorf("bestPlan: esperado total %d, obtido %d", tt.total, plan.total)
+			}
+		})
+	}
+}
+
+func TestPromotionRejection(t *testing.T) {
+	started := discountNow.Add(-time.Hour)
+	later := discountNow.Add(time.Hour)
+	tests := []struct {
+		name      string
+		promotion models.Promotion
+		userID    int
+		userUses  map[int]int
+		items     []int
+		want      string
+	}{
+		{"aplicável", models.Promotion{ID: 1, Active: true}, 1, nil, []int{0}, ""},
+		{"inativa", models.Promotion{ID: 1}, 1, nil, []int{0}, models.RejectionInactive},
+		{"ainda não começou", models.Promotion{ID: 1, Active: true, StartsAt: &later}, 1, nil, []int{0}, models.RejectionNotStarted},
+		{"termina no instante da avaliação", models.Promotion{ID: 1, Active: true, StartsAt: &started, EndsAt: &discountNow}, 1, nil, []int{0}, models.RejectionExpired},
+		{"resgates esgotados", models.Promotion{ID: 1, Active: true, MaxUses: 5, Uses: 5}, 1, nil, []int{0}, models.RejectionExhausted},
+		{"limite por usuário sem usuário", models.Promotion{ID: 1, Active: true, MaxUsesPerUser: 1}, 0, nil, []int{0}, models.RejectionUserRequired},
+		{"limite por usuário atingido", models.Promotion{ID: 1, Active: true, MaxUsesPerUser: 2}, 1, map[int]int{1: 2}, []int{0}, models.RejectionUserLimit},
+		{"limite por usuário disponível", models.Promotion{ID: 1, Active: true, MaxUsesPerUser: 2}, 1, map[int]int{1: 1, 2: 5}, []int{0}, ""},
+		{"valor fixo em outra moeda", models.Promotion{ID: 1, Active: true, Type: models.DiscountFixed, Amount: &models.Money{Amount: 100, Currency: "USD"}}, 1, nil, []int{0}, models.RejectionCurrency},
+		{"sem itens elegíveis", models.Promotion{ID: 1, Active: true}, 1, nil, nil, models.RejectionNoEligible},
+		{"quantidade mínima não atingida", models.Promotion{ID: 1, Active: true, MinQuantity: 3}, 1, nil, []int{0, 1}, models.RejectionMinQuantity},
+		{"quantidade mínima atingida", models.Promotion{ID: 1, Active: true, MinQuantity: 2}, 1, nil, []int{0, 1}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBasket(1000, 2000)
+			b.userID = tt.userID
+			b.userUses = tt.userUses
+			if got := promotionRejection(tt.promotion, tt.items, b); got != tt.want {
+				t.Errorf("promotionRejection: esperado %q, obtido %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// promotionIDs retorna os IDs das promoções, na ordem
+func promotionIDs(promotions []models.Promotion) []int {
+	var ids []int
+	for _, p := range promotions {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
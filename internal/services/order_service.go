@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/cursor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+var (
+	ErrInvalidOrderData  = errors.New("dados do pedido inválidos")
+	ErrInvalidTransition = errors.New("transição de status inválida")
+	// ErrOrderNotFound é o mesmo valor do repositório, para que errors.Is
+	// funcione em qualquer camada
+	ErrOrderNotFound = repositories.ErrOrderNotFound
+)
+
+// maxOrderItems limita a quantidade de itens de um pedido
+const maxOrderItems = 100
+
+// orderStatuses são os status aceitos no filtro da listagem de pedidos
+var orderStatuses = []string{models.OrderPending, models.OrderPaid, models.OrderShipped, models.OrderCancelled, models.OrderRefunded}
+
+// OrderService contém a lógica de negócio para pedidos. O estoque é
+// movimentado exclusivamente pelo serviço de produtos: a criação reserva os
+// itens, o pagamento confirma as reservas e o cancelamento devolve o estoque.
+type OrderService struct {
+	repo       repositories.OrderStore
+	users      *UserService
+	products   *ProductService
+	promotions *PromotionService
+	cursors    *cursor.Codec
+	// transitions serializa as mudanças de status, que combinam operações de
+	// estoque com a gravação do pedido e não podem ser intercaladas
+	transitions sync.Mutex
+}
+
+// NewOrderService cria uma nova instância do serviço de pedidos
+func NewOrderService(repo repositories.OrderStore, users *UserService, products *ProductService, promotions *PromotionService, cursors *cursor.Codec) *OrderService {
+	return &OrderService{repo: repo, users: users, products: products, promotions: promotions, cursors: cursors}
+}
+
+// GetAll retorna uma página de pedidos filtrados e ordenados conforme os
+// parâmetros (user_id, status e sort), o total encontrado e o cursor da próxima página
+func (s *OrderService) GetAll(ctx context.Context, params url.Values, page models.Pagination) ([]models.Order, models.PageInfo, error) {
+	filter, sort, err := ParseOrderQuery(params)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	query := repositories.OrderQuery{Filter: filter, Sort: sort}
+
+	sortKey := repositories.SortKey(query.Sort)
+	window, err := pageWindow(s.cursors, page, sortKey)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	query.Window = window
+
+	orders, total, err := s.repo.List(ctx, query)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return finishPage(s.cursors, orders, total, page, func(o models.Order) models.Keyset {
+		return models.Keyset{Sort: sortKey, Values: repositories.OrderSortValues(o, query.Sort), ID: o.ID}
+	})
+}
+
+// GetByID retorna um pedido pelo ID
+func (s *OrderService) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	if id <= 0 {
+		return nil, ErrInvalidOrderData
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create valida a requisição, calcula os descontos, reserva o estoque de cada
+// item, registra os resgates das promoções aplicadas e grava o pedido como
+// pendente. Preços e nomes vêm do catálogo no momento da criação; itens de
+// produtos com variações usam o preço e o estoque da variante. Se algum passo
+// falhar, as reservas e os resgates já feitos são liberados, e falhas dessa
+// liberação são retornadas junto com o erro original.
+func (s *OrderService) Create(ctx context.Context, req models.OrderRequest) (*models.Order, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	evaluation, err := s.validateOrder(ctx, req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	order := models.Order{
+		UserID:    req.UserID,
+		Status:    models.OrderPending,
+		Items:     make([]models.OrderItem, 0, len(evaluation.Items)),
+		Subtotal:  evaluation.Subtotal,
+		Discount:  evaluation.Discount,
+		Total:     evaluation.Total,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i, evaluated := range evaluation.Items {
+		reservation, err := s.products.Reserve(ctx, evaluated.ProductID, models.StockRequest{Quantity: evaluated.Quantity, VariantID: evaluated.VariantID})
+		if err != nil {
+			return nil, errors.Join(i18n.Errorf(err, "order_item", i, evaluated.ProductID), s.releaseItems(ctx, order.Items))
+		}
+		order.Items = append(order.Items, models.OrderItem{
+			ProductID:     evaluated.ProductID,
+			VariantID:     evaluated.VariantID,
+			SKU:           evaluated.SKU,
+			Name:          evaluated.Name,
+			Quantity:      evaluated.Quantity,
+			UnitPrice:     evaluated.UnitPrice,
+			Subtotal:      evaluated.Subtotal,
+			ReservationID: reservation.ID,
+		})
+	}
+
+	order.Discounts, err = s.promotions.redeem(ctx, req.UserID, evaluation, now)
+	if err != nil {
+		return nil, errors.Join(err, s.releaseItems(ctx, order.Items))
+	}
+
+	created, err := s.repo.Create(ctx, order)
+	if err != nil {
+		return nil, errors.Join(err, s.releaseItems(ctx, order.Items), s.promotions.release(ctx, order.Discounts))
+	}
+	return created, nil
+}
+
+// validateOrder verifica o usuário, os itens e os cupons da requisição e
+// retorna a avaliação da cesta com os descontos. Cupons que não se aplicam
+// são violações; todas são reportadas juntas em um *ValidationError.
+func (s *OrderService) validateOrder(ctx context.Context, req models.OrderRequest, now time.Time) (*models.Evaluation, error) {
+	var v validator
+
+	if req.UserID <= 0 {
+		v.add("user_id", CodeRequired, "field.required")
+	} else if user, err := s.users.GetByID(ctx, req.UserID); errors.Is(err, repositories.ErrUserNotFound) {
+		v.add("user_id", CodeNotFound, "field.not_found")
+	} else if err != nil {
+		return nil, err
+	} else if !user.Active {
+		v.add("user_id", CodeInactive, "field.inactive")
+	}
+
+	products, variants, err := validateItems(ctx, &v, s.products, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	codes := couponCodes(&v, req.Coupons)
+	if err := v.err(ErrInvalidOrderData); err != nil {
+		return nil, err
+	}
+
+	evaluation, err := s.promotions.evaluate(ctx, req.UserID, req.Items, products, variants, codes, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, rejected := range evaluation.Rejected {
+		if i := couponIndex(codes, rejected.Code); i >= 0 {
+			v.add(fmt.Sprintf("coupons[%d]", i), CodeNotApplicable, "field.coupon_rejected", i18n.Text("rejection."+rejected.Reason))
+		}
+	}
+	if err := v.err(ErrInvalidOrderData); err != nil {
+		return nil, err
+	}
+	return evaluation, nil
+}
+
+// validateItems verifica os itens de um pedido ou cesta, registrando as
+// violações em v, e retorna os produtos e as variantes (nulas nos produtos
+// sem variações) de cada item. Todos os produtos devem estar na mesma moeda, e
+// os subtotais e a sua soma devem caber em int64.
+func validateItems(ctx context.Context, v *validator, products *ProductService, items []models.OrderItemRequest) ([]models.Product, []*models.Variant, error) {
+	switch {
+	case len(items) == 0:
+		v.add("items", CodeRequired, "field.required")
+	case len(items) > maxOrderItems:
+		v.add("items", CodeTooMany, "field.too_many_items", maxOrderItems)
+	}
+
+	found := make([]models.Product, len(items))
+	variants := make([]*models.Variant, len(items))
+	seen := make(map[[2]int]bool, len(items))
+	currency := ""
+	var total models.Money
+	for i, item := range items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.positive(field+".quantity", item.Quantity)
+		v.atMost(field+".quantity", item.Quantity, maxStockQuantity)
+
+		key := [2]int{item.ProductID, item.VariantID}
+		switch {
+		case item.ProductID <= 0:
+			v.add(field+".product_id", CodeRequired, "field.required")
+			continue
+		case seen[key]:
+			v.add(field+".product_id", CodeDuplicate, "field.duplicate")
+			continue
+		}
+		seen[key] = true
+
+		product, err := products.GetByID(ctx, item.ProductID)
+		switch {
+		case errors.Is(err, repositories.ErrProductNotFound):
+			v.add(field+".product_id", CodeNotFound, "field.not_found")
+			continue
+		case err != nil:
+			return nil, nil, err
+		case !product.Active:
+			v.add(field+".product_id", CodeInactive, "field.inactive")
+		case currency == "":
+			currency = product.Price.Currency
+		case product.Price.Currency != currency:
+			v.add(field+".product_id", CodeCurrencyMismatch, "field.currency_mismatch", product.Price.Currency, currency)
+		}
+		found[i] = *product
+		if variants[i], err = products.itemVariant(ctx, v, field+".variant_id", *product, item.VariantID); err != nil {
+			return nil, nil, err
+		}
+		price := product.Price
+		if variants[i] != nil {
+			price = variants[i].Price
+		}
+		if item.Quantity > 0 && item.Quantity <= maxStockQuantity {
+			total = v.subtotal(field+".quantity", total, price, item.Quantity)
+		}
+	}
+	return found, variants, nil
+}
+
+// Pay marca um pedido pendente como pago, confirmando as reservas de estoque.
+// Itens cuja reserva expirou são retirados diretamente do estoque; sem
+// estoque suficiente, o pagamento é recusado com ErrInsufficientStock.
+func (s *OrderService) Pay(ctx context.Context, id int) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderPaid)
+}
+
+// Ship marca um pedido pago como enviado
+func (s *OrderService) Ship(ctx context.Context, id int) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderShipped)
+}
+
+// Cancel cancela um pedido pendente ou pago, devolvendo o estoque: reservas
+// são liberadas e itens já retirados voltam ao estoque como devolução. Os
+// resgates de promoções também são liberados e deixam de contar nos limites.
+// O status é gravado antes, então uma nova tentativa nunca devolve o estoque
+// duas vezes; falhas ao devolver são retornadas e não desfazem o cancelamento.
+func (s *OrderService) Cancel(ctx context.Context, id int) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderCancelled)
+}
+
+// Refund marca um pedido enviado como reembolsado. O estoque não é alterado:
+// a volta das mercadorias é registrada como uma entrada do tipo return. Os
+// resgates de promoções são mantidos.
+func (s *OrderService) Refund(ctx context.Context, id int) (*models.Order, error) {
+	return s.transition(ctx, id, models.OrderRefunded)
+}
+
+// transition aplica os efeitos de estoque da mudança de status e grava o novo
+// status. O pagamento retira o estoque antes da gravação e o estorna se ela
+// falhar; o cancelamento grava o status antes de devolver o que o pedido retém.
+func (s *OrderService) transition(ctx context.Context, id int, to string) (*models.Order, error) {
+	if id <= 0 {
+		return nil, ErrInvalidOrderData
+	}
+
+	s.transitions.Lock()
+	defer s.transitions.Unlock()
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !models.CanTransition(order.Status, to) {
+		return nil, i18n.Errorf(ErrInvalidTransition, "order_transition", order.Status, to)
+	}
+
+	pay := order.Status == models.OrderPending && to == models.OrderPaid
+	if pay {
+		if err := s.commitItems(ctx, *order); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.UpdateStatus(ctx, id, order.Version, to, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		if pay {
+			// o pedido continua pendente, então o estoque retirado volta
+			return nil, errors.Join(err, s.restoreItems(ctx, *order, order.Items))
+		}
+		return nil, err
+	}
+	if to == models.OrderCancelled {
+		if err := s.cancelItems(ctx, *order); err != nil {
+			return nil, i18n.Errorf(err, "order_cancel_incomplete", order.ID)
+		}
+	}
+	return updated, nil
+}
+
+// cancelItems devolve o que um pedido cancelado retinha: as reservas de um
+// pedido pendente ou o estoque de um pedido pago, e os resgates das
+// promoções. Tenta tudo mesmo que algo falhe.
+func (s *OrderService) cancelItems(ctx context.Context, order models.Order) error {
+	var err error
+	if order.Status == models.OrderPaid {
+		err = s.returnItems(ctx, order)
+	} else {
+		err = s.releaseItems(ctx, order.Items)
+	}
+	return errors.Join(err, s.promotions.release(ctx, order.Discounts))
+}
+
+// commitItems confirma as reservas dos itens; reservas expiradas dão lugar a
+// uma retirada direta. Em caso de falha, os itens já retirados são estornados,
+// e falhas do estorno são retornadas junto com o erro original.
+func (s *OrderService) commitItems(ctx context.Context, order models.Order) error {
+	for i, item := range order.Items {
+		_, err := s.products.CommitReservation(ctx, item.ReservationID)
+		if errors.Is(err, ErrReservationNotFound) {
+			_, err = s.products.DecrementStock(ctx, item.ProductID, models.StockRequest{
+				Quantity:  item.Quantity,
+				Type:      models.MovementSale,
+				Reason:    fmt.Sprintf("pedido %d", order.ID),
+				VariantID: item.VariantID,
+			})
+		}
+		if err != nil {
+			return errors.Join(i18n.Errorf(err, "order_item", i, item.ProductID), s.restoreItems(ctx, order, order.Items[:i]))
+		}
+	}
+	return nil
+}
+
+// restoreItems estorna ao estoque itens já retirados do pedido, tentando
+// todos mesmo que algum falhe
+func (s *OrderService) restoreItems(ctx context.Context, order models.Order, items []models.OrderItem) error {
+	var errs []error
+	for i, item := range items {
+		_, err := s.products.IncrementStock(ctx, item.ProductID, models.StockRequest{
+			Quantity:  item.Quantity,
+			Type:      models.MovementAdjustment,
+			Reason:    fmt.Sprintf("estorno do pedido %d", order.ID),
+			VariantID: item.VariantID,
+		})
+		if err != nil {
+			errs = append(errs, i18n.Errorf(err, "order_item", i, item.ProductID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// releaseItems libera as reservas dos itens, tentando todas mesmo que alguma
+// falhe; reservas já expiradas são ignoradas
+func (s *OrderService) releaseItems(ctx context.Context, items []models.OrderItem) error {
+	var errs []error
+	for i, item := range items {
+		err := s.products.ReleaseReservation(ctx, item.ReservationID)
+		if err != nil && !errors.Is(err, ErrReservationNotFound) {
+			errs = append(errs, i18n.Errorf(err, "order_item", i, item.ProductID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// returnItems devolve ao estoque os itens de um pedido pago, tentando todos
+// mesmo que algum falhe; produtos e variantes que não existem mais fora da
+// lixeira são ignorados
+func (s *OrderService) returnItems(ctx context.Context, order models.Order) error {
+	var errs []error
+	for i, item := range order.Items {
+		_, err := s.products.IncrementStock(ctx, item.ProductID, models.StockRequest{
+			Quantity:  item.Quantity,
+			Type:      models.MovementReturn,
+			Reason:    fmt.Sprintf("pedido %d cancelado", order.ID),
+			VariantID: item.VariantID,
+		})
+		if err != nil && !errors.Is(err, repositories.ErrProductNotFound) && !errors.Is(err, ErrVariantNotFound) {
+			errs = append(errs, i18n.Errorf(err, "order_item", i, item.ProductID))
+		}
+	}
+	return errors.Join(errs...)
+}
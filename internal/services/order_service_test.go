@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/cursor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+var errInjected = errors.New("falha injetada")
+
+// failingOrders é um OrderStore cuja gravação de status falha enquanto err
+// estiver definido
+type failingOrders struct {
+	repositories.OrderStore
+	err error
+}
+
+func (f *failingOrders) UpdateStatus(ctx context.Context, id int, version int, status string, updatedAt time.Time) (*models.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.OrderStore.UpdateStatus(ctx, id, version, status, updatedAt)
+}
+
+// failingProducts é um ProductStore cujas entradas de estoque no produto
+// productID falham enquanto err estiver definido
+type failingProducts struct {
+	repositories.ProductStore
+	productID int
+	err       error
+}
+
+func (f *failingProducts) AdjustStock(ctx context.Context, id int, delta int, change repositories.StockChange) (*models.Product, error) {
+	if f.err != nil && id == f.productID && delta > 0 {
+		return nil, f.err
+	}
+	return f.ProductStore.AdjustStock(ctx, id, delta, change)
+}
+
+// serviceFixture reúne os serviços ligados aos repositórios em memória, com
+// os pontos de falha de pedidos e de estoque
+type serviceFixture struct {
+	orders     *failingOrders
+	stock      *failingProducts
+	products   *ProductService
+	promotions *PromotionService
+	carts      *CartService
+	service    *OrderService
+}
+
+func newServiceFixture(t *testing.T) *serviceFixture {
+	t.Helper()
+	cursors := cursor.NewCodec([]byte("segredo de teste"))
+	categories := repositories.NewCategoryRepository()
+	f := &serviceFixture{
+		orders: &failingOrders{OrderStore: repositories.NewOrderRepository()},
+		stock:  &failingProducts{ProductStore: repositories.NewProductRepository()},
+	}
+	users := NewUserService(repositories.NewUserRepository(), cursors)
+	f.products = NewProductService(f.stock, categories, cursors, time.Hour)
+	f.carts = NewCartService(repositories.NewCartRepository(), users, f.products, time.Hour)
+	f.promotions = NewPromotionService(repositories.NewPromotionRepository(), categories, users, f.products, f.carts)
+	f.service = NewOrderService(f.orders, users, f.products, f.promotions, cursors)
+	return f
+}
+
+// stockOf retorna o estoque atual do produto
+func (f *serviceFixture) stockOf(t *testing.T, productID int) int {
+	t.Helper()
+	product, err := f.products.GetByID(context.Background(), productID)
+	if err != nil {
+		t.Fatalf("GetByID(%d): %v", productID, err)
+	}
+	return product.Stock
+}
+
+// newOrder cria um pedido pendente dos produtos 1 (2 unidades) e 2 (3
+// unidades) com uma promoção automática, e retorna o pedido e a promoção
+func (f *serviceFixture) newOrder(t *testing.T) (*models.Order, *models.Promotion) {
+	t.Helper()
+	ctx := context.Background()
+	active := true
+	promotion, err := f.promotions.Create(ctx, models.PromotionRequest{
+		Name:       "Dez por cento",
+		Type:       models.DiscountPercentage,
+		Percentage: 10,
+		Stackable:  true,
+		Active:     &active,
+	})
+	if err != nil {
+		t.Fatalf("Create promoção: %v", err)
+	}
+	order, err := f.service.Create(ctx, models.OrderRequest{
+		UserID: 1,
+		Items:  []models.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("Create pedido: %v", err)
+	}
+	if len(order.Discounts) != 1 || order.Discounts[0].PromotionID != promotion.ID {
+		t.Fatalf("Create pedido: esperado o desconto da promoção %d, obtido %+v", promotion.ID, order.Discounts)
+	}
+	return order, promotion
+}
+
+// assertOrder verifica o status do pedido, o estoque dos produtos 1 e 2 e os
+// resgates vigentes da promoção
+func (f *serviceFixture) assertOrder(t *testing.T, step string, orderID int, status string, stock1, stock2 int, promotionID, uses int) {
+	t.Helper()
+	ctx := context.Background()
+	order, err := f.service.GetByID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("%s: GetByID: %v", step, err)
+	}
+	if order.Status != status {
+		t.Errorf("%s: esperado status %s, obtido %s", step, status, order.Status)
+	}
+	if got1, got2 := f.stockOf(t, 1), f.stockOf(t, 2); got1 != stock1 || got2 != stock2 {
+		t.Errorf("%s: esperado estoque %d e %d, obtido %d e %d", step, stock1, stock2, got1, got2)
+	}
+	promotion, err := f.promotions.GetByID(ctx, promotionID)
+	if err != nil {
+		t.Fatalf("%s: GetByID promoção: %v", step, err)
+	}
+	if promotion.Uses != uses {
+		t.Errorf("%s: esperado %d resgates, obtido %d", step, uses, promotion.Uses)
+	}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	stock1, stock2 := f.stockOf(t, 1), f.stockOf(t, 2)
+	order, promotion := f.newOrder(t)
+	f.assertOrder(t, "Create", order.ID, models.OrderPending, stock1, stock2, promotion.ID, 1)
+
+	if _, err := f.service.Ship(ctx, order.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Ship pendente: esperado ErrInvalidTransition, obtido %v", err)
+	}
+	if _, err := f.service.Pay(ctx, order.ID); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	f.assertOrder(t, "Pay", order.ID, models.OrderPaid, stock1-2, stock2-3, promotion.ID, 1)
+	for _, item := range order.Items {
+		if _, err := f.products.GetReservation(ctx, item.ReservationID); !errors.Is(err, ErrReservationNotFound) {
+			t.Errorf("Pay: esperado a reserva %d confirmada, obtido %v", item.ReservationID, err)
+		}
+	}
+
+	if _, err := f.service.Cancel(ctx, order.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	f.assertOrder(t, "Cancel", order.ID, models.OrderCancelled, stock1, stock2, promotion.ID, 0)
+	if _, err := f.service.Cancel(ctx, order.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Cancel repetido: esperado ErrInvalidTransition, obtido %v", err)
+	}
+	f.assertOrder(t, "Cancel repetido", order.ID, models.OrderCancelled, stock1, stock2, promotion.ID, 0)
+}
+
+func TestOrderPayRestoresStockWhenSaveFails(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	stock1, stock2 := f.stockOf(t, 1), f.stockOf(t, 2)
+	order, promotion := f.newOrder(t)
+
+	f.orders.err = errInjected
+	if _, err := f.service.Pay(ctx, order.ID); !errors.Is(err, errInjected) {
+		t.Fatalf("Pay: esperado a falha injetada, obtido %v", err)
+	}
+	f.assertOrder(t, "Pay com falha", order.ID, models.OrderPending, stock1, stock2, promotion.ID, 1)
+
+	// as reservas foram confirmadas, então o novo pagamento retira o estoque diretamente
+	f.orders.err = nil
+	if _, err := f.service.Pay(ctx, order.ID); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	f.assertOrder(t, "Pay", order.ID, models.OrderPaid, stock1-2, stock2-3, promotion.ID, 1)
+}
+
+func TestOrderCancelPaidLeavesNothingBehindWhenSaveFails(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	stock1, stock2 := f.stockOf(t, 1), f.stockOf(t, 2)
+	order, promotion := f.newOrder(t)
+	if _, err := f.service.Pay(ctx, order.ID); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+
+	f.orders.err = errInjected
+	if _, err := f.service.Cancel(ctx, order.ID); !errors.Is(err, errInjected) {
+		t.Fatalf("Cancel: esperado a falha injetada, obtido %v", err)
+	}
+	f.assertOrder(t, "Cancel com falha", order.ID, models.OrderPaid, stock1-2, stock2-3, promotion.ID, 1)
+
+	f.orders.err = nil
+	if _, err := f.service.Cancel(ctx, order.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	f.assertOrder(t, "Cancel", order.ID, models.OrderCancelled, stock1, stock2, promotion.ID, 0)
+}
+
+func TestOrderCancelPaidReportsItemsNotReturned(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	stock1, stock2 := f.stockOf(t, 1), f.stockOf(t, 2)
+	order, promotion := f.newOrder(t)
+	if _, err := f.service.Pay(ctx, order.ID); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+
+	// a devolução do primeiro item falha; as demais continuam
+	f.stock.productID, f.stock.err = 1, errInjected
+	if _, err := f.service.Cancel(ctx, order.ID); !errors.Is(err, errInjected) {
+		t.Fatalf("Cancel: esperado a falha injetada, obtido %v", err)
+	}
+	f.assertOrder(t, "Cancel com falha", order.ID, models.OrderCancelled, stock1-2, stock2, promotion.ID, 0)
+
+	// o cancelamento já foi gravado, então repeti-lo não devolve nada de novo
+	f.stock.err = nil
+	if _, err := f.service.Cancel(ctx, order.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Cancel repetido: esperado ErrInvalidTransition, obtido %v", err)
+	}
+	f.assertOrder(t, "Cancel repetido", order.ID, models.OrderCancelled, stock1-2, stock2, promotion.ID, 0)
+}
+
+func TestOrderCancelPendingKeepsReservationsWhenSaveFails(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	stock1, stock2 := f.stockOf(t, 1), f.stockOf(t, 2)
+	order, promotion := f.newOrder(t)
+
+	f.orders.err = errInjected
+	if _, err := f.service.Cancel(ctx, order.ID); !errors.Is(err, errInjected) {
+		t.Fatalf("Cancel: esperado a falha injetada, obtido %v", err)
+	}
+	f.assertOrder(t, "Cancel com falha", order.ID, models.OrderPending, stock1, stock2, promotion.ID, 1)
+	for _, item := range order.Items {
+		if _, err := f.products.GetReservation(ctx, item.ReservationID); err != nil {
+			t.Errorf("Cancel com falha: esperado a reserva %d vigente, obtido %v", item.ReservationID, err)
+		}
+	}
+
+	f.orders.err = nil
+	if _, err := f.service.Cancel(ctx, order.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	f.assertOrder(t, "Cancel", order.ID, models.OrderCancelled, stock1, stock2, promotion.ID, 0)
+	for _, item := range order.Items {
+		if _, err := f.products.GetReservation(ctx, item.ReservationID); !errors.Is(err, ErrReservationNotFound) {
+			t.Errorf("Cancel: esperado a reserva %d liberada, obtido %v", item.ReservationID, err)
+		}
+	}
+}
+
+func TestValidateItemsRejectsAmountsBeyondInt64(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	var ids [2]int
+	for i := range ids {
+		product, err := f.stock.Create(ctx, models.Product{
+			Name:       fmt.Sprintf("Servidor %d", i+1),
+			Price:      models.Money{Amount: maxMoneyAmount, Currency: "BRL"},
+			CategoryID: 1,
+			Stock:      maxStockQuantity,
+			Active:     true,
+		}, repositories.StockChange{Type: models.MovementAdjustment, At: time.Now().UTC()})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids[i] = product.ID
+	}
+
+	tests := []struct {
+		name  string
+		items []models.OrderItemRequest
+		field string
+		max   interface{}
+	}{
+		{"quantidade acima do estoque máximo", []models.OrderItemRequest{{ProductID: 1, Quantity: 9223372036854775}}, "items[0].quantity", maxStockQuantity},
+		{"subtotal acima do limite", []models.OrderItemRequest{{ProductID: ids[0], Quantity: 100_000_000}}, "items[0].quantity", int64(92233720)},
+		{"soma acima do limite", []models.OrderItemRequest{{ProductID: ids[0], Quantity: 50_000_000}, {ProductID: ids[1], Quantity: 50_000_000}}, "items[1].quantity", int64(42233720)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.promotions.Evaluate(ctx, models.BasketRequest{Items: tt.items})
+			var validation *ValidationError
+			if !errors.As(err, &validation) || !errors.Is(err, ErrInvalidBasketData) {
+				t.Fatalf("Evaluate: esperado *ValidationError, obtido %v", err)
+			}
+			if len(validation.Fields) != 1 || validation.Fields[0].Field != tt.field || validation.Fields[0].Code != CodeTooLarge || validation.Fields[0].Args[0] != tt.max {
+				t.Errorf("Evaluate: esperado %s em %s com máximo %v, obtido %+v", CodeTooLarge, tt.field, tt.max, validation.Fields)
+			}
+
+			_, err = f.service.Create(ctx, models.OrderRequest{UserID: 1, Items: tt.items})
+			if !errors.As(err, &validation) || !errors.Is(err, ErrInvalidOrderData) {
+				t.Errorf("Create: esperado *ValidationError, obtido %v", err)
+			}
+		})
+	}
+	if got := f.stockOf(t, ids[0]); got != maxStockQuantity {
+		t.Errorf("Create: esperado o estoque intacto, obtido %d", got)
+	}
+}
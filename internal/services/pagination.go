@@ -0,0 +1,48 @@
+package services
+
+import (
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/cursor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+// ErrInvalidCursor indica um cursor adulterado, expirado ou emitido para outra ordenação
+var ErrInvalidCursor = cursor.ErrInvalidCursor
+
+// pageWindow traduz a paginação da API em uma janela de repositório.
+// Um item extra é solicitado para descobrir se existe uma próxima página.
+func pageWindow(codec *cursor.Codec, page models.Pagination, sort string) (repositories.Window, error) {
+	window := repositories.Window{Offset: page.Offset(), Limit: page.Limit}
+
+	if page.Cursor != "" {
+		keyset, err := codec.Decode(page.Cursor)
+		if err != nil {
+			return window, err
+		}
+		if keyset.Sort != sort {
+			return window, ErrInvalidCursor
+		}
+		window.After = keyset
+	}
+
+	if window.Limit > 0 {
+		window.Limit++
+	}
+	return window, nil
+}
+
+// finishPage remove o item extra e emite o cursor da próxima página, se houver
+func finishPage[T any](codec *cursor.Codec, items []T, total int, page models.Pagination, keyset func(T) models.Keyset) ([]T, models.PageInfo, error) {
+	info := models.PageInfo{Total: total}
+	if page.Limit <= 0 || len(items) <= page.Limit {
+		return items, info, nil
+	}
+
+	items = items[:page.Limit]
+	next, err := codec.Encode(keyset(items[len(items)-1]))
+	if err != nil {
+		return nil, info, err
+	}
+	info.NextCursor = next
+	return items, info, nil
+}
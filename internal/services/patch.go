@@ -0,0 +1,29 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/patch"
+)
+
+// applyPatch aplica o patch à representação editável atual e decodifica o
+// resultado em dst. Campos desconhecidos ou com tipo errado resultam em um
+// *ValidationError associado a invalid.
+func applyPatch(current interface{}, mediaType string, body []byte, dst interface{}, invalid error) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	patched, err := patch.Apply(mediaType, doc, body)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err, invalid)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+// pricesSortKey identifica os cursores do histórico de preços, que é sempre
+// percorrido em ordem cronológica
+const pricesSortKey = "price_changes"
+
+var (
+	ErrInvalidScheduleData = errors.New("dados do agendamento de preço inválidos")
+	// ErrScheduleNotFound, ErrScheduleOverlap e ErrScheduleClosed são os
+	// mesmos valores do repositório
+	ErrScheduleNotFound = repositories.ErrScheduleNotFound
+	ErrScheduleOverlap  = repositories.ErrScheduleOverlap
+	ErrScheduleClosed   = repositories.ErrScheduleClosed
+)
+
+// priceClock guarda o instante da próxima mudança de agendamento de preço,
+// evitando consultar o store a cada leitura de produto
+type priceClock struct {
+	mu sync.Mutex
+	// known indica se next reflete os agendamentos gravados
+	known bool
+	// next é nulo quando não há agendamentos em aberto
+	next *time.Time
+}
+
+// GetPriceHistory retorna uma página do histórico de preços de um produto, em
+// ordem cronológica
+func (s *ProductService) GetPriceHistory(ctx context.Context, productID int, page models.Pagination) ([]models.PriceChange, models.PageInfo, error) {
+	if productID <= 0 {
+		return nil, models.PageInfo{}, ErrInvalidProductData
+	}
+	if err := s.syncPrices(ctx); err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	window, err := pageWindow(s.cursors, page, pricesSortKey)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	changes, total, err := s.repo.ListPriceChanges(ctx, productID, window)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return finishPage(s.cursors, changes, total, page, func(c models.PriceChange) models.Keyset {
+		return models.Keyset{Sort: pricesSortKey, ID: c.ID}
+	})
+}
+
+// GetPriceSchedules retorna os agendamentos de preço de um produto, ordenados por início
+func (s *ProductService) GetPriceSchedules(ctx context.Context, productID int) ([]models.PriceSchedule, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	if err := s.syncPrices(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPriceSchedules(ctx, productID)
+}
+
+// SchedulePrice agenda uma alteração do preço de um produto para starts_at,
+// opcionalmente revertida em ends_at. O preço deve estar na moeda do produto
+// e o período não pode se sobrepor ao de outro agendamento em aberto.
+func (s *ProductService) SchedulePrice(ctx context.Context, productID int, req models.PriceScheduleRequest) (*models.PriceSchedule, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := priceNow()
+	var v validator
+	requested := req.Price
+	// sem moeda, o preço agendado usa a moeda do produto
+	if strings.TrimSpace(requested.Currency) == "" {
+		requested.Currency = product.Price.Currency
+	}
+	price := v.money("price", requested)
+	if price.Currency != "" && price.Currency != product.Price.Currency {
+		v.add("price.currency", CodeCurrencyMismatch, "field.product_currency", price.Currency, product.Price.Currency)
+	}
+	startsAt := v.timestamp("starts_at", req.StartsAt, true)
+	endsAt := v.timestamp("ends_at", req.EndsAt, false)
+	if startsAt != nil && !startsAt.After(now) {
+		v.add("starts_at", CodeNotAllowed, "field.must_be_future")
+	}
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		v.add("ends_at", CodeNotAllowed, "field.after_starts_at")
+	}
+	v.length("reason", req.Reason, 0, maxReasonLength)
+	if err := v.err(ErrInvalidScheduleData); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.repo.CreatePriceSchedule(ctx, models.PriceSchedule{
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  *startsAt,
+		EndsAt:    endsAt,
+		Reason:    req.Reason,
+		Actor:     actor.FromContext(ctx),
+		CreatedAt: now,
+	})
+	s.forgetPriceSchedules()
+	return schedule, err
+}
+
+// CancelPriceSchedule cancela um agendamento de preço em aberto. Se ele já
+// estiver em vigor, o preço anterior é restaurado, a menos que o preço tenha
+// sido alterado depois do início; agendamentos concluídos ou cancelados
+// resultam em ErrScheduleClosed.
+func (s *ProductService) CancelPriceSchedule(ctx context.Context, productID, scheduleID int) (*models.PriceSchedule, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	if scheduleID <= 0 {
+		return nil, ErrScheduleNotFound
+	}
+	if err := s.syncPrices(ctx); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.repo.CancelPriceSchedule(ctx, productID, scheduleID, actor.FromContext(ctx), priceNow())
+	s.forgetPriceSchedules()
+	return schedule, err
+}
+
+// ApplyPriceSchedules aplica os inícios e términos de agendamentos de preço
+// devidos e retorna quantos foram processados; é executado periodicamente
+// pelo agendador do servidor
+func (s *ProductService) ApplyPriceSchedules(ctx context.Context) (int, error) {
+	s.prices.mu.Lock()
+	defer s.prices.mu.Unlock()
+	return s.applyPriceSchedules(ctx, priceNow())
+}
+
+// syncPrices aplica os agendamentos devidos antes de uma leitura, para que os
+// produtos reflitam o preço efetivo no instante da requisição mesmo entre
+// duas execuções do agendador. Sem mudanças devidas, não consulta o store.
+func (s *ProductService) syncPrices(ctx context.Context) error {
+	now := priceNow()
+	s.prices.mu.Lock()
+	defer s.prices.mu.Unlock()
+
+	if s.prices.known && (s.prices.next == nil || now.Before(*s.prices.next)) {
+		return nil
+	}
+	_, err := s.applyPriceSchedules(ctx, now)
+	return err
+}
+
+// applyPriceSchedules aplica os agendamentos devidos até now e atualiza o
+// instante da próxima mudança. Deve ser chamado com s.prices.mu adquirido.
+func (s *ProductService) applyPriceSchedules(ctx context.Context, now time.Time) (int, error) {
+	applied, err := s.repo.ApplyPriceSchedules(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	next, err := s.repo.NextPriceScheduleAt(ctx)
+	if err != nil {
+		return applied, err
+	}
+	s.prices.next = next
+	s.prices.known = true
+	return applied, nil
+}
+
+// forgetPriceSchedules descarta o instante da próxima mudança, que é
+// recalculado na próxima leitura
+func (s *ProductService) forgetPriceSchedules() {
+	s.prices.mu.Lock()
+	defer s.prices.mu.Unlock()
+	s.prices.known = false
+}
+
+// getProduct retorna um produto com o preço efetivo no instante da requisição
+func (s *ProductService) getProduct(ctx context.Context, id int) (*models.Product, error) {
+	if err := s.syncPrices(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// checkSchedules verifica se a moeda do produto continua compatível com os
+// agendamentos em aberto, registrando as violações em v
+func checkSchedules(v *validator, price models.Money, schedules []models.PriceSchedule) {
+	conflicts := 0
+	for _, schedule := range schedules {
+		if schedule.Open() && price.Currency != "" && schedule.Price.Currency != price.Currency {
+			conflicts++
+		}
+	}
+	if conflicts > 0 {
+		v.add("price.currency", CodeInUse, "field.in_use_by_schedules", conflicts)
+	}
+}
+
+// priceNow retorna o instante das operações de preço, na precisão persistida
+func priceNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
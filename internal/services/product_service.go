@@ -1,116 +1,342 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/cursor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
 	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
 	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
 )
 
 var (
 	ErrInvalidProductData = errors.New("dados do produto inválidos")
-	ErrInsufficientStock  = errors.New("estoque insuficiente")
+	ErrProductInactive    = errors.New("produto inativo")
+	// ErrInsufficientStock e ErrReservationNotFound são os mesmos valores do
+	// repositório, para que errors.Is funcione em qualquer camada
+	ErrInsufficientStock   = repositories.ErrInsufficientStock
+	ErrReservationNotFound = repositories.ErrReservationNotFound
 )
 
 // ProductService contém a lógica de negócio para produtos
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo       repositories.ProductStore
+	categories repositories.CategoryStore
+	cursors    *cursor.Codec
+	// reservationTTL é o tempo de vida de uma reserva de estoque
+	reservationTTL time.Duration
+	prices         priceClock
 }
 
 // NewProductService cria uma nova instância do serviço de produtos
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo repositories.ProductStore, categories repositories.CategoryStore, cursors *cursor.Codec, reservationTTL time.Duration) *ProductService {
+	return &ProductService{repo: repo, categories: categories, cursors: cursors, reservationTTL: reservationTTL}
 }
 
-// GetAll retorna todos os produtos
-func (s *ProductService) GetAll() []models.Product {
-	return s.repo.GetAll()
+// GetAll retorna uma página de produtos filtrados e ordenados conforme os
+// parâmetros (category, recursive, active, currency, min_price, max_price,
+// in_stock e sort), o total encontrado e o cursor da próxima página
+func (s *ProductService) GetAll(ctx context.Context, params url.Values, page models.Pagination) ([]models.Product, models.PageInfo, error) {
+	filter, scope, sort, err := ParseProductQuery(params)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	if scope.Ref != "" {
+		categories, err := s.categories.List(ctx)
+		if err != nil {
+			return nil, models.PageInfo{}, err
+		}
+		category := findCategory(categories, scope.Ref)
+		if category == nil {
+			return nil, models.PageInfo{}, i18n.Errorf(ErrInvalidQuery, "query_unknown_category", scope.Ref)
+		}
+		filter.CategoryIDs = scope.ids(categories, category.ID)
+	}
+	return s.list(ctx, repositories.ProductQuery{Filter: filter, Sort: sort}, page)
+}
+
+// GetTrash retorna uma página dos produtos na lixeira, ordenada por ?sort=
+func (s *ProductService) GetTrash(ctx context.Context, params url.Values, page models.Pagination) ([]models.Product, models.PageInfo, error) {
+	sort, err := ParseTrashQuery(params, repositories.ProductSortColumns)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return s.list(ctx, repositories.ProductQuery{Filter: repositories.ProductFilter{Deleted: true}, Sort: sort}, page)
 }
 
-// GetByID retorna um produto pelo ID
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
+// GetByID retorna um produto pelo ID, com o preço efetivo no instante da requisição
+func (s *ProductService) GetByID(ctx context.Context, id int) (*models.Product, error) {
 	if id <= 0 {
 		return nil, ErrInvalidProductData
 	}
-	return s.repo.GetByID(id)
+	return s.getProduct(ctx, id)
 }
 
-// GetByCategory retorna produtos por categoria
-func (s *ProductService) GetByCategory(category string) []models.Product {
-	return s.repo.GetByCategory(category)
+// GetByCategory retorna uma página de produtos da categoria identificada pelo
+// slug, aceitando os mesmos parâmetros de GetAll; com ?recursive=true, inclui
+// os produtos das subcategorias. A categoria do caminho prevalece sobre ?category=.
+func (s *ProductService) GetByCategory(ctx context.Context, slug string, params url.Values, page models.Pagination) ([]models.Product, models.PageInfo, error) {
+	filter, scope, sort, err := ParseProductQuery(params)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	category := findCategory(categories, slug)
+	if category == nil {
+		return nil, models.PageInfo{}, ErrCategoryNotFound
+	}
+	filter.CategoryIDs = scope.ids(categories, category.ID)
+	return s.list(ctx, repositories.ProductQuery{Filter: filter, Sort: sort}, page)
 }
 
-// Create cria um novo produto
-func (s *ProductService) Create(req models.ProductRequest) (*models.Product, error) {
-	if req.Name == "" || req.Price <= 0 {
-		return nil, ErrInvalidProductData
+// Search busca produtos pelo texto de ?q= em nome, descrição e categoria,
+// ignorando acentos e maiúsculas, e retorna os resultados ordenados por relevância.
+// Produtos inativos são omitidos, exceto com ?include_inactive=true.
+func (s *ProductService) Search(ctx context.Context, params url.Values, page models.Pagination) ([]models.ProductSearchResult, models.PageInfo, error) {
+	text, filter, err := ParseSearchQuery(params)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	if page.Cursor != "" {
+		return nil, models.PageInfo{}, i18n.Errorf(ErrInvalidQuery, "query_search_cursor")
 	}
 
-	if req.Stock < 0 {
-		return nil, ErrInvalidProductData
+	if err := s.syncPrices(ctx); err != nil {
+		return nil, models.PageInfo{}, err
 	}
+	window := repositories.Window{Offset: page.Offset(), Limit: page.Limit}
+	results, total, err := s.repo.Search(ctx, text, filter, window)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return results, models.PageInfo{Total: total}, nil
+}
 
-	product := models.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
-		Category:    req.Category,
-		Active:      true,
+// Create cria um novo produto; com eixos de variação, o estoque inicial deve
+// ser zero e as unidades são cadastradas nas variantes
+func (s *ProductService) Create(ctx context.Context, req models.ProductRequest) (*models.Product, error) {
+	product, err := s.applyProductRequest(ctx, models.Product{Active: true}, nil, nil, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, product, stockChange(ctx, models.MovementAdjustment, "cadastro do produto"))
+}
+
+// Update substitui todos os campos editáveis de um produto existente; campos
+// omitidos assumem o valor vazio, exceto o estoque, que deve ser o atual (as
+// alterações passam por IncrementStock e DecrementStock). Com ifMatch não
+// nulo, a atualização só ocorre se a versão atual estiver entre as
+// informadas; caso contrário, ou se o registro mudar durante a operação,
+// retorna ErrVersionConflict
+func (s *ProductService) Update(ctx context.Context, id int, req models.ProductRequest, ifMatch []int) (*models.Product, error) {
+	existing, err := s.current(ctx, id, ifMatch)
+	if err != nil {
+		return nil, err
 	}
+	return s.replace(ctx, *existing, req)
+}
 
-	if product.Category == "" {
-		product.Category = "Geral"
+// Patch altera parcialmente um produto com um JSON Merge Patch (RFC 7396) ou
+// JSON Patch (RFC 6902), conforme mediaType. O patch é aplicado sobre os campos
+// editáveis; nulos e zeros explícitos são respeitados e o resultado é validado
+// como em Update.
+func (s *ProductService) Patch(ctx context.Context, id int, mediaType string, body []byte, ifMatch []int) (*models.Product, error) {
+	existing, err := s.current(ctx, id, ifMatch)
+	if err != nil {
+		return nil, err
 	}
 
-	created := s.repo.Create(product)
-	return &created, nil
+	base := productRequest(*existing)
+	var req models.ProductRequest
+	if err := applyPatch(base, mediaType, body, &req, ErrInvalidProductData); err != nil {
+		return nil, err
+	}
+	// um patch que altera apenas o nome legado da categoria a seleciona por ele
+	if req.Category != base.Category && req.CategoryID == base.CategoryID {
+		req.CategoryID = 0
+	}
+	return s.replace(ctx, *existing, req)
 }
 
-// Update atualiza um produto existente
-func (s *ProductService) Update(id int, req models.ProductRequest) (*models.Product, error) {
+// current retorna o produto a ser alterado, verificando a versão exigida em ifMatch
+func (s *ProductService) current(ctx context.Context, id int, ifMatch []int) (*models.Product, error) {
 	if id <= 0 {
 		return nil, ErrInvalidProductData
 	}
 
-	existing, err := s.repo.GetByID(id)
+	existing, err := s.getProduct(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(ifMatch, existing.Version); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
 
-	product := models.Product{
-		ID:          existing.ID,
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
-		Category:    req.Category,
-		Active:      existing.Active,
+// replace grava os campos editáveis da requisição sobre o produto existente,
+// verificando a compatibilidade com as variantes e os agendamentos de preço dele
+func (s *ProductService) replace(ctx context.Context, existing models.Product, req models.ProductRequest) (*models.Product, error) {
+	variants, err := s.repo.ListVariants(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.repo.ListPriceSchedules(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.applyProductRequest(ctx, existing, variants, schedules, req)
+	if err != nil {
+		return nil, err
 	}
+	return s.repo.Update(ctx, existing.ID, product, stockChange(ctx, models.MovementAdjustment, "atualização do produto"))
+}
+
+// applyProductRequest valida a requisição e copia seus campos para o produto.
+// A categoria é resolvida por category_id ou pelo slug ou nome em category;
+// sem nenhum deles, o produto fica na categoria padrão. Eixos de variação,
+// estoque e moeda são verificados contra as variantes existentes, e a moeda
+// também contra os agendamentos de preço em aberto. Em um produto existente,
+// o estoque informado deve ser o atual. Todas as violações são reportadas
+// juntas em um *ValidationError.
+func (s *ProductService) applyProductRequest(ctx context.Context, product models.Product, variants []models.Variant, schedules []models.PriceSchedule, req models.ProductRequest) (models.Product, error) {
+	name := strings.TrimSpace(req.Name)
+	ref := strings.TrimSpace(req.Category)
 
-	if product.Name == "" {
-		product.Name = existing.Name
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return product, err
 	}
-	if product.Description == "" {
-		product.Description = existing.Description
+
+	var v validator
+	v.length("name", name, minNameLength, maxNameLength)
+	v.length("description", req.Description, 0, maxDescriptionLength)
+	price := v.money("price", req.Price)
+	v.nonNegative("stock", req.Stock)
+	options := validateOptions(&v, req.Options)
+	checkVariants(&v, models.Product{Price: price, Stock: req.Stock, Options: options}, variants)
+	// após a criação, o estoque só muda pelas operações de estoque, que
+	// respeitam as reservas e registram o tipo e o motivo da movimentação
+	if product.ID != 0 && len(options) == 0 && req.Stock != product.Stock {
+		v.add("stock", CodeManagedByStock, "field.managed_by_stock", product.Stock)
 	}
-	if product.Price <= 0 {
-		product.Price = existing.Price
+	checkSchedules(&v, price, schedules)
+	var category *models.Category
+	switch {
+	case req.CategoryID != 0:
+		if i := categoryIndex(categories, req.CategoryID); i >= 0 {
+			category = &categories[i]
+		} else {
+			v.add("category_id", CodeNotFound, "field.not_found")
+		}
+	case ref != "":
+		if category = findCategory(categories, ref); category == nil {
+			v.add("category", CodeNotFound, "field.not_found")
+		}
+	default:
+		if category = findCategory(categories, defaultCategorySlug); category == nil {
+			v.add("category_id", CodeRequired, "field.required")
+		}
 	}
-	if product.Stock < 0 {
-		product.Stock = existing.Stock
+	if err := v.err(ErrInvalidProductData); err != nil {
+		return product, err
 	}
-	if product.Category == "" {
-		product.Category = existing.Category
+
+	product.Name = name
+	product.Description = req.Description
+	product.Price = price
+	product.Stock = req.Stock
+	product.Options = options
+	product.CategoryID = category.ID
+	product.Category = category.Name
+	return product, nil
+}
+
+// productRequest retorna os campos editáveis do produto
+func productRequest(p models.Product) models.ProductRequest {
+	return models.ProductRequest{
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       models.MoneyRequest{Amount: p.Price.Decimal(), Currency: p.Price.Currency},
+		Stock:       p.Stock,
+		CategoryID:  p.CategoryID,
+		Category:    p.Category,
+		Options:     p.Options,
 	}
+}
+
+// Activate reativa um produto; a operação é idempotente
+func (s *ProductService) Activate(ctx context.Context, id int) (*models.Product, error) {
+	return s.setActive(ctx, id, true)
+}
 
-	return s.repo.Update(id, product)
+// Deactivate desativa um produto, removendo-o das listagens padrão; a operação é idempotente
+func (s *ProductService) Deactivate(ctx context.Context, id int) (*models.Product, error) {
+	return s.setActive(ctx, id, false)
+}
+
+// setActive altera o estado ativo de um produto
+func (s *ProductService) setActive(ctx context.Context, id int, active bool) (*models.Product, error) {
+	if id <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	return s.repo.SetActive(ctx, id, active)
 }
 
-// Delete remove um produto
-func (s *ProductService) Delete(id int) error {
+// Delete move um produto para a lixeira, respeitando ifMatch como em Update
+func (s *ProductService) Delete(ctx context.Context, id int, ifMatch []int) error {
 	if id <= 0 {
 		return ErrInvalidProductData
 	}
-	return s.repo.Delete(id)
+
+	existing, err := s.getProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(ifMatch, existing.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, existing.Version, time.Now().UTC().Truncate(time.Second))
+}
+
+// Restore retira um produto da lixeira
+func (s *ProductService) Restore(ctx context.Context, id int) (*models.Product, error) {
+	if id <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+// Purge remove definitivamente os produtos que estão na lixeira há mais que retention
+func (s *ProductService) Purge(ctx context.Context, retention time.Duration) (int, error) {
+	return s.repo.Purge(ctx, time.Now().UTC().Add(-retention))
+}
+
+// list executa a consulta paginada e emite o cursor da próxima página
+func (s *ProductService) list(ctx context.Context, query repositories.ProductQuery, page models.Pagination) ([]models.Product, models.PageInfo, error) {
+	sortKey := repositories.SortKey(query.Sort)
+	window, err := pageWindow(s.cursors, page, sortKey)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	query.Window = window
+
+	if err := s.syncPrices(ctx); err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	products, total, err := s.repo.List(ctx, query)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return finishPage(s.cursors, products, total, page, func(p models.Product) models.Keyset {
+		return models.Keyset{Sort: sortKey, Values: repositories.ProductSortValues(p, query.Sort), ID: p.ID}
+	})
 }
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+var (
+	ErrInvalidPromotionData = errors.New("dados da promoção inválidos")
+	ErrInvalidBasketData    = errors.New("dados da cesta inválidos")
+	// ErrPromotionNotFound, ErrCouponCodeExists, ErrPromotionExhausted e
+	// ErrPromotionUserLimit são os mesmos valores do repositório
+	ErrPromotionNotFound  = repositories.ErrPromotionNotFound
+	ErrCouponCodeExists   = repositories.ErrCouponCodeExists
+	ErrPromotionExhausted = repositories.ErrPromotionExhausted
+	ErrPromotionUserLimit = repositories.ErrPromotionUserLimit
+)
+
+// Limites das promoções e dos cupons
+const (
+	maxCouponLength  = 32
+	maxCoupons       = 5
+	maxPromotionRefs = 100
+	maxPercentage    = 100
+)
+
+// couponPattern define o formato aceito para o código de um cupom, já em maiúsculas
+var couponPattern = regexp.MustCompile(`^[A-Z0-9]+([-_][A-Z0-9]+)*$`)
+
+// discountTypes são os tipos de desconto aceitos para uma promoção
+var discountTypes = []string{models.DiscountPercentage, models.DiscountFixed}
+
+// PromotionService contém a lógica de negócio das promoções e cupons e o
+// cálculo dos descontos de uma cesta. Os preços vêm sempre do catálogo.
+type PromotionService struct {
+	repo       repositories.PromotionStore
+	categories repositories.CategoryStore
+	users      *UserService
+	products   *ProductService
+	carts      *CartService
+}
+
+// NewPromotionService cria uma nova instância do serviço de promoções
+func NewPromotionService(repo repositories.PromotionStore, categories repositories.CategoryStore, users *UserService, products *ProductService, carts *CartService) *PromotionService {
+	return &PromotionService{repo: repo, categories: categories, users: users, products: products, carts: carts}
+}
+
+// GetAll retorna todas as promoções, ordenadas por ID
+func (s *PromotionService) GetAll(ctx context.Context) ([]models.Promotion, error) {
+	return s.repo.List(ctx)
+}
+
+// GetByID retorna uma promoção pelo ID
+func (s *PromotionService) GetByID(ctx context.Context, id int) (*models.Promotion, error) {
+	if id <= 0 {
+		return nil, ErrInvalidPromotionData
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create valida e cria uma nova promoção
+func (s *PromotionService) Create(ctx context.Context, req models.PromotionRequest) (*models.Promotion, error) {
+	now := promotionNow()
+	promotion, err := s.applyPromotionRequest(ctx, models.Promotion{CreatedAt: now}, req)
+	if err != nil {
+		return nil, err
+	}
+	promotion.UpdatedAt = now
+	return s.repo.Create(ctx, promotion)
+}
+
+// Update valida e substitui os dados de uma promoção; os resgates já
+// registrados são mantidos e continuam contando para os limites
+func (s *PromotionService) Update(ctx context.Context, id int, req models.PromotionRequest) (*models.Promotion, error) {
+	existing, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	promotion, err := s.applyPromotionRequest(ctx, *existing, req)
+	if err != nil {
+		return nil, err
+	}
+	promotion.UpdatedAt = promotionNow()
+	return s.repo.Update(ctx, id, promotion)
+}
+
+// Delete remove uma promoção e seus resgates; os pedidos mantêm a cópia do desconto
+func (s *PromotionService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidPromotionData
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Evaluate calcula os descontos das promoções vigentes e dos cupons
+// informados sobre uma cesta de produtos, sem registrar resgates
+func (s *PromotionService) Evaluate(ctx context.Context, req models.BasketRequest) (*models.Evaluation, error) {
+	var v validator
+	if req.UserID < 0 {
+		v.add("user_id", CodeNotFound, "field.not_found")
+	} else if req.UserID > 0 {
+		if _, err := s.users.GetByID(ctx, req.UserID); errors.Is(err, repositories.ErrUserNotFound) {
+			v.add("user_id", CodeNotFound, "field.not_found")
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	products, variants, err := validateItems(ctx, &v, s.products, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	codes := couponCodes(&v, req.Coupons)
+	if err := v.err(ErrInvalidBasketData); err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, req.UserID, req.Items, products, variants, codes, promotionNow())
+}
+
+// EvaluateCart calcula os descontos sobre os itens do carrinho do usuário,
+// pelos preços atuais do catálogo; itens indisponíveis são reportados como
+// violações, como na criação do pedido
+func (s *PromotionService) EvaluateCart(ctx context.Context, userID int, req models.CouponsRequest) (*models.Evaluation, error) {
+	cart, err := s.carts.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	basket := models.BasketRequest{UserID: userID, Items: make([]models.OrderItemRequest, len(cart.Items)), Coupons: req.Coupons}
+	for i, item := range cart.Items {
+		basket.Items[i] = models.OrderItemRequest{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity}
+	}
+	return s.Evaluate(ctx, basket)
+}
+
+// evaluate calcula os descontos de uma cesta já validada. As promoções
+// automáticas são consideradas sempre e as de cupom apenas quando informadas;
+// cupons que não se aplicam e promoções preteridas pelas regras de acumulação
+// são listados em Rejected com o motivo.
+func (s *PromotionService) evaluate(ctx context.Context, userID int, items []models.OrderItemRequest, products []models.Product, variants []*models.Variant, codes []string, now time.Time) (*models.Evaluation, error) {
+	b := basket{
+		items:      make([]models.EvaluationItem, len(items)),
+		categories: make([]int, len(items)),
+		currency:   products[0].Price.Currency,
+		userID:     userID,
+		userUses:   map[int]int{},
+		now:        now,
+	}
+	subtotal := models.Money{Currency: b.currency}
+	for i, req := range items {
+		item := models.EvaluationItem{
+			ProductID: products[i].ID,
+			Name:      products[i].Name,
+			Quantity:  req.Quantity,
+			UnitPrice: products[i].Price,
+		}
+		if variant := variants[i]; variant != nil {
+			item.VariantID = variant.ID
+			item.SKU = variant.SKU
+			item.UnitPrice = variant.Price
+		}
+		var err error
+		if item.Subtotal, err = item.UnitPrice.Times(req.Quantity); err != nil {
+			return nil, err
+		}
+		item.Subtotal.Currency = b.currency
+		if subtotal, err = subtotal.Plus(item.Subtotal); err != nil {
+			return nil, err
+		}
+		b.items[i] = item
+		b.categories[i] = products[i].CategoryID
+	}
+	if userID > 0 {
+		uses, err := s.repo.UserRedemptions(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		b.userUses = uses
+	}
+
+	promotions, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := &models.Evaluation{UserID: userID, Promotions: []models.AppliedPromotion{}, Rejected: []models.RejectedPromotion{}}
+	var candidates []discountCandidate
+	consider := func(p models.Promotion) {
+		scope := make(map[int]bool)
+		for _, id := range p.CategoryIDs {
+			for _, sub := range subtreeIDs(categories, id) {
+				scope[sub] = true
+			}
+		}
+		eligible := eligibleItems(p, scope, b)
+		if reason := promotionRejection(p, eligible, b); reason != "" {
+			if p.Code != "" {
+				evaluation.Rejected = append(evaluation.Rejected, models.RejectedPromotion{PromotionID: p.ID, Code: p.Code, Reason: reason})
+			}
+			return
+		}
+		candidates = append(candidates, discountCandidate{promotion: p, items: eligible})
+	}
+	for _, p := range promotions {
+		if p.Code == "" {
+			consider(p)
+		}
+	}
+	for _, code := range codes {
+		p, err := s.repo.GetByCode(ctx, code)
+		if errors.Is(err, repositories.ErrPromotionNotFound) {
+			evaluation.Rejected = append(evaluation.Rejected, models.RejectedPromotion{Code: code, Reason: models.RejectionNotFound})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		consider(*p)
+	}
+
+	plan, passed := bestPlan(b, candidates)
+	for _, p := range passed {
+		evaluation.Rejected = append(evaluation.Rejected, models.RejectedPromotion{PromotionID: p.ID, Code: p.Code, Reason: models.RejectionNotStackable})
+	}
+	for _, p := range plan.idle {
+		if p.Code != "" {
+			evaluation.Rejected = append(evaluation.Rejected, models.RejectedPromotion{PromotionID: p.ID, Code: p.Code, Reason: models.RejectionNoEligible})
+		}
+	}
+	evaluation.Promotions = append(evaluation.Promotions, plan.applied...)
+
+	evaluation.Items = b.items
+	evaluation.Subtotal = subtotal
+	evaluation.Discount = models.Money{Currency: b.currency}
+	for i := range evaluation.Items {
+		item := &evaluation.Items[i]
+		item.Discounts = append([]models.ItemDiscount{}, plan.shares[i]...)
+		item.Discount = models.Money{Currency: b.currency}
+		for _, d := range item.Discounts {
+			item.Discount.Amount += d.Amount.Amount
+		}
+		item.Total = models.Money{Amount: item.Subtotal.Amount - item.Discount.Amount, Currency: b.currency}
+		evaluation.Discount.Amount += item.Discount.Amount
+	}
+	evaluation.Total = models.Money{Amount: evaluation.Subtotal.Amount - evaluation.Discount.Amount, Currency: b.currency}
+	return evaluation, nil
+}
+
+// redeem registra os resgates das promoções aplicadas na avaliação e retorna
+// os descontos do pedido. Se algum limite tiver sido atingido desde a
+// avaliação, nenhum resgate é registrado e o erro do repositório é retornado.
+func (s *PromotionService) redeem(ctx context.Context, userID int, evaluation *models.Evaluation, now time.Time) ([]models.OrderDiscount, error) {
+	discounts := []models.OrderDiscount{}
+	if len(evaluation.Promotions) == 0 {
+		return discounts, nil
+	}
+
+	redemptions := make([]models.Redemption, len(evaluation.Promotions))
+	for i, applied := range evaluation.Promotions {
+		redemptions[i] = models.Redemption{PromotionID: applied.PromotionID, UserID: userID, CreatedAt: now}
+	}
+	redeemed, err := s.repo.Redeem(ctx, redemptions)
+	if err != nil {
+		return nil, err
+	}
+	for i, applied := range evaluation.Promotions {
+		discounts = append(discounts, models.OrderDiscount{
+			PromotionID:  applied.PromotionID,
+			Name:         applied.Name,
+			Code:         applied.Code,
+			Amount:       applied.Amount,
+			RedemptionID: redeemed[i].ID,
+		})
+	}
+	return discounts, nil
+}
+
+// release libera os resgates dos descontos de um pedido, tentando todos mesmo
+// que algum falhe; resgates que já não existem, como os de promoções
+// removidas, são ignorados
+func (s *PromotionService) release(ctx context.Context, discounts []models.OrderDiscount) error {
+	var errs []error
+	for _, d := range discounts {
+		err := s.repo.ReleaseRedemption(ctx, d.RedemptionID)
+		if err != nil && !errors.Is(err, repositories.ErrRedemptionNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyPromotionRequest valida a requisição e copia seus campos para a
+// promoção. Todas as violações são reportadas juntas em um *ValidationError.
+func (s *PromotionService) applyPromotionRequest(ctx context.Context, promotion models.Promotion, req models.PromotionRequest) (models.Promotion, error) {
+	name := strings.TrimSpace(req.Name)
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+
+	var v validator
+	v.length("name", name, minNameLength, maxNameLength)
+	switch {
+	case utf8.RuneCountInString(code) > maxCouponLength:
+		v.add("code", CodeTooLong, "field.too_long", maxCouponLength)
+	case code != "" && !couponPattern.MatchString(code):
+		v.add("code", CodeInvalidFormat, "field.invalid_coupon_code")
+	}
+
+	v.oneOf("type", req.Type, discountTypes)
+	var amount *models.Money
+	switch req.Type {
+	case models.DiscountPercentage:
+		switch {
+		case req.Percentage <= 0:
+			v.add("percentage", CodeMustBePositive, "field.must_be_positive")
+		case req.Percentage > maxPercentage:
+			v.add("percentage", CodeNotAllowed, "field.max_value", maxPercentage)
+		}
+		if req.Amount != nil {
+			v.add("amount", CodeNotAllowed, "field.not_for_type", req.Type)
+		}
+	case models.DiscountFixed:
+		if req.Amount == nil {
+			v.add("amount", CodeRequired, "field.required")
+		} else {
+			money := v.money("amount", *req.Amount)
+			amount = &money
+		}
+		if req.Percentage != 0 {
+			v.add("percentage", CodeNotAllowed, "field.not_for_type", req.Type)
+		}
+	}
+
+	v.nonNegative("min_quantity", req.MinQuantity)
+	v.nonNegative("max_uses", req.MaxUses)
+	v.nonNegative("max_uses_per_user", req.MaxUsesPerUser)
+	startsAt := v.timestamp("starts_at", req.StartsAt, false)
+	endsAt := v.timestamp("ends_at", req.EndsAt, false)
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		v.add("ends_at", CodeNotAllowed, "field.after_starts_at")
+	}
+
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return promotion, err
+	}
+	if err := promotionRefs(&v, "category_ids", req.CategoryIDs, func(id int) (bool, error) {
+		return categoryIndex(categories, id) >= 0, nil
+	}); err != nil {
+		return promotion, err
+	}
+	if err := promotionRefs(&v, "product_ids", req.ProductIDs, func(id int) (bool, error) {
+		_, err := s.products.GetByID(ctx, id)
+		if errors.Is(err, repositories.ErrProductNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}); err != nil {
+		return promotion, err
+	}
+
+	if err := v.err(ErrInvalidPromotionData); err != nil {
+		return promotion, err
+	}
+
+	promotion.Name = name
+	promotion.Code = code
+	promotion.Type = req.Type
+	promotion.Percentage = req.Percentage
+	promotion.Amount = amount
+	promotion.CategoryIDs = append([]int{}, req.CategoryIDs...)
+	promotion.ProductIDs = append([]int{}, req.ProductIDs...)
+	promotion.MinQuantity = req.MinQuantity
+	promotion.StartsAt = startsAt
+	promotion.EndsAt = endsAt
+	promotion.MaxUses = req.MaxUses
+	promotion.MaxUsesPerUser = req.MaxUsesPerUser
+	promotion.Stackable = req.Stackable
+	promotion.Active = req.Active == nil || *req.Active
+	return promotion, nil
+}
+
+// promotionRefs verifica uma lista de IDs do escopo de uma promoção: no
+// máximo maxPromotionRefs, sem repetições e todos existentes segundo exists
+func promotionRefs(v *validator, field string, ids []int, exists func(id int) (bool, error)) error {
+	if len(ids) > maxPromotionRefs {
+		v.add(field, CodeTooMany, "field.too_many_items", maxPromotionRefs)
+		return nil
+	}
+	for i, id := range ids {
+		ref := fmt.Sprintf("%s[%d]", field, i)
+		if containsID(ids[:i], id) {
+			v.add(ref, CodeDuplicate, "field.duplicate")
+			continue
+		}
+		found, err := exists(id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			v.add(ref, CodeNotFound, "field.not_found")
+		}
+	}
+	return nil
+}
+
+// couponCodes normaliza os cupons informados (sem espaços, em maiúsculas),
+// registrando em v os vazios, os repetidos e o excesso
+func couponCodes(v *validator, coupons []string) []string {
+	if len(coupons) > maxCoupons {
+		v.add("coupons", CodeTooMany, "field.too_many_items", maxCoupons)
+		return nil
+	}
+	codes := make([]string, 0, len(coupons))
+	for i, coupon := range coupons {
+		field := fmt.Sprintf("coupons[%d]", i)
+		code := strings.ToUpper(strings.TrimSpace(coupon))
+		switch {
+		case code == "":
+			v.add(field, CodeRequired, "field.required")
+		case couponIndex(codes, code) >= 0:
+			v.add(field, CodeDuplicate, "field.duplicate")
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// couponIndex retorna a posição do código entre os cupons informados ou -1
+func couponIndex(codes []string, code string) int {
+	for i, c := range codes {
+		if c != "" && c == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// promotionNow retorna o instante das operações de promoção, na precisão persistida
+func promotionNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
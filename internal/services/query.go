@@ -0,0 +1,356 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+// ErrInvalidQuery indica parâmetros de filtro ou ordenação inválidos
+var ErrInvalidQuery = errors.New("consulta inválida")
+
+// paginationParams são tratados pela camada de paginação e ignorados pelos filtros
+var paginationParams = map[string]bool{"page": true, "limit": true, "cursor": true}
+
+// sortFieldPattern define o formato aceito para cada campo de ordenação
+var sortFieldPattern = regexp.MustCompile(`^-?[a-z_]+$`)
+
+// queryParams facilita a leitura validada dos parâmetros de uma listagem
+type queryParams struct {
+	values url.Values
+}
+
+// checkKnown rejeita parâmetros desconhecidos ou repetidos
+func (q queryParams) checkKnown(known ...string) error {
+	allowed := make(map[string]bool, len(known))
+	for _, k := range known {
+		allowed[k] = true
+	}
+
+	var unknown []string
+	for key, values := range q.values {
+		if paginationParams[key] {
+			continue
+		}
+		if !allowed[key] {
+			unknown = append(unknown, key)
+			continue
+		}
+		if len(values) > 1 {
+			return i18n.Errorf(ErrInvalidQuery, "query_repeated_param", key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		sort.Strings(known)
+		return i18n.Errorf(ErrInvalidQuery, "query_unknown_params", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// string retorna o valor do parâmetro sem espaços nas bordas
+func (q queryParams) string(key string) string {
+	return strings.TrimSpace(q.values.Get(key))
+}
+
+// bool lê um parâmetro booleano opcional
+func (q queryParams) bool(key string) (*bool, error) {
+	raw := q.string(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, i18n.Errorf(ErrInvalidQuery, "query_invalid_bool", key, raw)
+	}
+	return &v, nil
+}
+
+// active lê o filtro de estado. Sem ?active=, registros inativos são
+// excluídos, a menos que ?include_inactive=true seja informado.
+func (q queryParams) active() (*bool, error) {
+	active, err := q.bool("active")
+	if err != nil || active != nil {
+		return active, err
+	}
+
+	includeInactive, err := q.bool("include_inactive")
+	if err != nil {
+		return nil, err
+	}
+	if includeInactive != nil && *includeInactive {
+		return nil, nil
+	}
+
+	onlyActive := true
+	return &onlyActive, nil
+}
+
+// currency lê uma moeda ISO 4217 opcional entre as suportadas
+func (q queryParams) currency(key string) (string, error) {
+	currency := strings.ToUpper(q.string(key))
+	if currency == "" {
+		return "", nil
+	}
+	if _, ok := models.CurrencyExponent(currency); !ok {
+		return "", i18n.Errorf(ErrInvalidQuery, "query_unknown_currency", key, currency)
+	}
+	return currency, nil
+}
+
+// price lê um valor monetário opcional, não negativo, convertendo-o para
+// unidades menores da moeda informada
+func (q queryParams) price(key, currency string) (*int64, error) {
+	raw := q.string(key)
+	if raw == "" {
+		return nil, nil
+	}
+	money, err := models.ParseMoney(raw, currency)
+	if err != nil || money.Amount < 0 {
+		return nil, i18n.Errorf(ErrInvalidQuery, "query_invalid_price", key, raw)
+	}
+	return &money.Amount, nil
+}
+
+// id lê um ID opcional, inteiro e positivo; zero indica ausência
+func (q queryParams) id(key string) (int, error) {
+	raw := q.string(key)
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, i18n.Errorf(ErrInvalidQuery, "query_invalid_id", key, raw)
+	}
+	return id, nil
+}
+
+// oneOf lê um valor opcional entre os permitidos
+func (q queryParams) oneOf(key string, allowed []string) (string, error) {
+	raw := q.string(key)
+	if raw == "" {
+		return "", nil
+	}
+	for _, a := range allowed {
+		if raw == a {
+			return raw, nil
+		}
+	}
+	return "", i18n.Errorf(ErrInvalidQuery, "query_not_allowed", key, strings.Join(allowed, ", "), raw)
+}
+
+// sort lê a ordenação no formato "campo,-campo" validando contra os campos permitidos
+func (q queryParams) sort(columns map[string]string) ([]repositories.SortField, error) {
+	raw := q.string("sort")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var fields []repositories.SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if !sortFieldPattern.MatchString(part) {
+			return nil, i18n.Errorf(ErrInvalidQuery, "query_invalid_sort", part)
+		}
+
+		field := repositories.SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := columns[field.Field]; !ok {
+			return nil, i18n.Errorf(ErrInvalidQuery, "query_unknown_sort_field", field.Field, strings.Join(sortedKeys(columns), ", "))
+		}
+		if seen[field.Field] {
+			return nil, i18n.Errorf(ErrInvalidQuery, "query_repeated_sort_field", field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// timestamp lê um instante opcional em RFC 3339 ou como data (AAAA-MM-DD, meia-noite UTC)
+func (q queryParams) timestamp(key string) (*time.Time, error) {
+	raw := q.string(key)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, i18n.Errorf(ErrInvalidQuery, "query_invalid_date", key, raw)
+}
+
+// sortedKeys retorna as chaves do mapa em ordem alfabética
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CategoryScope é a categoria pedida em uma listagem de produtos, ainda não
+// resolvida: Ref é o slug ou nome informado e Recursive inclui as subcategorias
+type CategoryScope struct {
+	Ref       string
+	Recursive bool
+}
+
+// ids retorna os IDs das categorias abrangidas a partir da categoria resolvida
+func (c CategoryScope) ids(categories []models.Category, id int) []int {
+	if c.Recursive {
+		return subtreeIDs(categories, id)
+	}
+	return []int{id}
+}
+
+// ParseProductQuery converte os parâmetros de uma listagem de produtos em
+// filtro, categoria e ordenação, rejeitando campos desconhecidos e valores inválidos
+func ParseProductQuery(values url.Values) (repositories.ProductFilter, CategoryScope, []repositories.SortField, error) {
+	q := queryParams{values: values}
+	var filter repositories.ProductFilter
+	var scope CategoryScope
+
+	if err := q.checkKnown("category", "recursive", "active", "include_inactive", "currency", "min_price", "max_price", "in_stock", "sort"); err != nil {
+		return filter, scope, nil, err
+	}
+
+	scope.Ref = q.string("category")
+	recursive, err := q.bool("recursive")
+	if err != nil {
+		return filter, scope, nil, err
+	}
+	scope.Recursive = recursive != nil && *recursive
+	if filter.Active, err = q.active(); err != nil {
+		return filter, scope, nil, err
+	}
+	if filter.InStock, err = q.bool("in_stock"); err != nil {
+		return filter, scope, nil, err
+	}
+	if filter.Currency, err = q.currency("currency"); err != nil {
+		return filter, scope, nil, err
+	}
+	priceCurrency := filter.Currency
+	if priceCurrency == "" {
+		priceCurrency = models.DefaultCurrency
+	}
+	if filter.MinPrice, err = q.price("min_price", priceCurrency); err != nil {
+		return filter, scope, nil, err
+	}
+	if filter.MaxPrice, err = q.price("max_price", priceCurrency); err != nil {
+		return filter, scope, nil, err
+	}
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		filter.Currency = priceCurrency
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return filter, scope, nil, i18n.Errorf(ErrInvalidQuery, "query_price_range")
+	}
+
+	fields, err := q.sort(repositories.ProductSortColumns)
+	if err != nil {
+		return filter, scope, nil, err
+	}
+	return filter, scope, fields, nil
+}
+
+// ParseOrderQuery converte os parâmetros de uma listagem de pedidos em
+// filtro e ordenação, rejeitando campos desconhecidos e valores inválidos
+func ParseOrderQuery(values url.Values) (repositories.OrderFilter, []repositories.SortField, error) {
+	q := queryParams{values: values}
+	var filter repositories.OrderFilter
+
+	if err := q.checkKnown("user_id", "status", "sort"); err != nil {
+		return filter, nil, err
+	}
+
+	var err error
+	if filter.UserID, err = q.id("user_id"); err != nil {
+		return filter, nil, err
+	}
+	if filter.Status, err = q.oneOf("status", orderStatuses); err != nil {
+		return filter, nil, err
+	}
+
+	fields, err := q.sort(repositories.OrderSortColumns)
+	if err != nil {
+		return filter, nil, err
+	}
+	return filter, fields, nil
+}
+
+// ParseUserQuery converte os parâmetros de uma listagem de usuários em
+// filtro e ordenação, rejeitando campos desconhecidos e valores inválidos
+func ParseUserQuery(values url.Values) (repositories.UserFilter, []repositories.SortField, error) {
+	q := queryParams{values: values}
+	var filter repositories.UserFilter
+
+	if err := q.checkKnown("role", "active", "include_inactive", "email_domain", "created_after", "created_before", "sort"); err != nil {
+		return filter, nil, err
+	}
+
+	var err error
+	filter.Role = q.string("role")
+	filter.EmailDomain = strings.TrimPrefix(q.string("email_domain"), "@")
+	if filter.Active, err = q.active(); err != nil {
+		return filter, nil, err
+	}
+	if filter.CreatedAfter, err = q.timestamp("created_after"); err != nil {
+		return filter, nil, err
+	}
+	if filter.CreatedBefore, err = q.timestamp("created_before"); err != nil {
+		return filter, nil, err
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return filter, nil, i18n.Errorf(ErrInvalidQuery, "query_date_range")
+	}
+
+	fields, err := q.sort(repositories.UserSortColumns)
+	if err != nil {
+		return filter, nil, err
+	}
+	return filter, fields, nil
+}
+
+// ParseSearchQuery converte os parâmetros de uma busca textual no termo
+// buscado e no filtro de estado dos produtos
+func ParseSearchQuery(values url.Values) (string, repositories.ProductFilter, error) {
+	q := queryParams{values: values}
+	var filter repositories.ProductFilter
+
+	if err := q.checkKnown("q", "include_inactive"); err != nil {
+		return "", filter, err
+	}
+
+	text := q.string("q")
+	if text == "" {
+		return "", filter, i18n.Errorf(ErrInvalidQuery, "query_search_term_required")
+	}
+
+	var err error
+	if filter.Active, err = q.active(); err != nil {
+		return "", filter, err
+	}
+	return text, filter, nil
+}
+
+// ParseTrashQuery converte os parâmetros de uma listagem da lixeira na
+// ordenação, validada contra os campos permitidos
+func ParseTrashQuery(values url.Values, columns map[string]string) ([]repositories.SortField, error) {
+	q := queryParams{values: values}
+	if err := q.checkKnown("sort"); err != nil {
+		return nil, err
+	}
+	return q.sort(columns)
+}
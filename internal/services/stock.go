@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/actor"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+const (
+	// maxReasonLength é o tamanho máximo do motivo de uma movimentação de estoque
+	maxReasonLength = 200
+	// maxStockQuantity é a maior quantidade de uma movimentação de estoque
+	maxStockQuantity = 1_000_000_000
+)
+
+// movementsSortKey identifica os cursores do histórico de estoque, que é
+// sempre percorrido em ordem cronológica
+const movementsSortKey = "stock_movements"
+
+var (
+	// incrementTypes são os tipos aceitos em uma entrada de estoque; o primeiro é o padrão
+	incrementTypes = []string{models.MovementPurchase, models.MovementReturn, models.MovementAdjustment}
+	// decrementTypes são os tipos aceitos em uma saída de estoque; o primeiro é o padrão
+	decrementTypes = []string{models.MovementSale, models.MovementAdjustment}
+)
+
+// IncrementStock adiciona req.Quantity unidades ao estoque de um produto, ou
+// da variante req.VariantID, registrando uma movimentação do tipo req.Type
+// (purchase, se omitido)
+func (s *ProductService) IncrementStock(ctx context.Context, id int, req models.StockRequest) (*models.Product, error) {
+	return s.adjustStock(ctx, id, req, 1, incrementTypes)
+}
+
+// DecrementStock retira req.Quantity unidades do estoque de um produto,
+// registrando uma movimentação do tipo req.Type (sale, se omitido). A
+// retirada é atômica e não consome unidades reservadas; sem estoque disponível
+// suficiente, retorna ErrInsufficientStock.
+func (s *ProductService) DecrementStock(ctx context.Context, id int, req models.StockRequest) (*models.Product, error) {
+	return s.adjustStock(ctx, id, req, -1, decrementTypes)
+}
+
+// adjustStock valida a operação e aplica a quantidade ao estoque com o sinal informado
+func (s *ProductService) adjustStock(ctx context.Context, id int, req models.StockRequest, sign int, types []string) (*models.Product, error) {
+	if id <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	if req.Type == "" {
+		req.Type = types[0]
+	}
+
+	var v validator
+	v.positive("quantity", req.Quantity)
+	v.atMost("quantity", req.Quantity, maxStockQuantity)
+	v.oneOf("type", req.Type, types)
+	v.length("reason", req.Reason, 0, maxReasonLength)
+	if err := v.err(ErrInvalidProductData); err != nil {
+		return nil, err
+	}
+	change := stockChange(ctx, req.Type, req.Reason)
+	if req.VariantID != 0 {
+		return s.repo.AdjustVariantStock(ctx, id, req.VariantID, sign*req.Quantity, change)
+	}
+	return s.repo.AdjustStock(ctx, id, sign*req.Quantity, change)
+}
+
+// Reserve retém req.Quantity unidades de um produto ativo, ou da variante
+// ativa req.VariantID, até a reserva ser confirmada, liberada ou expirar (após
+// o TTL configurado). Sem estoque disponível suficiente, retorna
+// ErrInsufficientStock.
+func (s *ProductService) Reserve(ctx context.Context, productID int, req models.StockRequest) (*models.StockReservation, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidProductData
+	}
+
+	var v validator
+	v.positive("quantity", req.Quantity)
+	if err := v.err(ErrInvalidProductData); err != nil {
+		return nil, err
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.Active {
+		return nil, ErrProductInactive
+	}
+	if req.VariantID != 0 {
+		variant, err := s.repo.GetVariant(ctx, productID, req.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		if !variant.Active {
+			return nil, i18n.Errorf(ErrProductInactive, "variant_inactive", variant.SKU)
+		}
+	}
+
+	now := stockNow()
+	return s.repo.Reserve(ctx, models.StockReservation{
+		ProductID: productID,
+		VariantID: req.VariantID,
+		Quantity:  req.Quantity,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.reservationTTL),
+	})
+}
+
+// GetReservation retorna uma reserva vigente; reservas expiradas resultam em
+// ErrReservationNotFound
+func (s *ProductService) GetReservation(ctx context.Context, id int) (*models.StockReservation, error) {
+	if id <= 0 {
+		return nil, ErrReservationNotFound
+	}
+	return s.repo.GetReservation(ctx, id, stockNow())
+}
+
+// CommitReservation confirma uma reserva vigente, retirando a quantidade do
+// estoque do produto como uma venda, e retorna o produto atualizado
+func (s *ProductService) CommitReservation(ctx context.Context, id int) (*models.Product, error) {
+	if id <= 0 {
+		return nil, ErrReservationNotFound
+	}
+	return s.repo.CommitReservation(ctx, id, stockChange(ctx, models.MovementSale, fmt.Sprintf("reserva %d", id)))
+}
+
+// ReleaseReservation libera uma reserva vigente, devolvendo a quantidade ao
+// estoque disponível
+func (s *ProductService) ReleaseReservation(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrReservationNotFound
+	}
+	return s.repo.ReleaseReservation(ctx, id, stockNow())
+}
+
+// available retorna o estoque disponível do produto ou, com variantID, da
+// variante: stock menos as reservas vigentes
+func (s *ProductService) available(ctx context.Context, productID, variantID, stock int) (int, error) {
+	reserved, err := s.repo.Reserved(ctx, productID, variantID, stockNow())
+	if err != nil {
+		return 0, err
+	}
+	return stock - reserved, nil
+}
+
+// GetMovements retorna uma página do histórico de estoque de um produto, em
+// ordem cronológica
+func (s *ProductService) GetMovements(ctx context.Context, productID int, page models.Pagination) ([]models.StockMovement, models.PageInfo, error) {
+	if productID <= 0 {
+		return nil, models.PageInfo{}, ErrInvalidProductData
+	}
+	window, err := pageWindow(s.cursors, page, movementsSortKey)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	movements, total, err := s.repo.ListMovements(ctx, productID, window)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return finishPage(s.cursors, movements, total, page, func(m models.StockMovement) models.Keyset {
+		return models.Keyset{Sort: movementsSortKey, ID: m.ID}
+	})
+}
+
+// stockChange descreve uma alteração de estoque feita agora pelo ator da requisição
+func stockChange(ctx context.Context, movementType, reason string) repositories.StockChange {
+	return repositories.StockChange{
+		Type:   movementType,
+		Reason: reason,
+		Actor:  actor.FromContext(ctx),
+		At:     stockNow(),
+	}
+}
+
+// stockNow retorna o instante das operações de estoque, na precisão persistida
+func stockNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
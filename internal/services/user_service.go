@@ -1,108 +1,220 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/cursor"
 	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
 	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
 )
 
 var (
 	ErrInvalidUserData = errors.New("dados do usuário inválidos")
-	ErrEmailExists     = errors.New("email já cadastrado")
+	// ErrEmailExists indica que o email já pertence a outro usuário
+	ErrEmailExists = repositories.ErrEmailExists
 )
 
 // UserService contém a lógica de negócio para usuários
 type UserService struct {
-	repo *repositories.UserRepository
+	repo    repositories.UserStore
+	cursors *cursor.Codec
 }
 
 // NewUserService cria uma nova instância do serviço de usuários
-func NewUserService(repo *repositories.UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo repositories.UserStore, cursors *cursor.Codec) *UserService {
+	return &UserService{repo: repo, cursors: cursors}
 }
 
-// GetAll retorna todos os usuários
-func (s *UserService) GetAll() []models.User {
-	return s.repo.GetAll()
+// GetAll retorna uma página de usuários filtrados e ordenados conforme os
+// parâmetros (role, active, email_domain, created_after, created_before e
+// sort), o total encontrado e o cursor da próxima página
+func (s *UserService) GetAll(ctx context.Context, params url.Values, page models.Pagination) ([]models.User, models.PageInfo, error) {
+	filter, sort, err := ParseUserQuery(params)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return s.list(ctx, repositories.UserQuery{Filter: filter, Sort: sort}, page)
+}
+
+// GetTrash retorna uma página dos usuários na lixeira, ordenada por ?sort=
+func (s *UserService) GetTrash(ctx context.Context, params url.Values, page models.Pagination) ([]models.User, models.PageInfo, error) {
+	sort, err := ParseTrashQuery(params, repositories.UserSortColumns)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return s.list(ctx, repositories.UserQuery{Filter: repositories.UserFilter{Deleted: true}, Sort: sort}, page)
 }
 
 // GetByID retorna um usuário pelo ID
-func (s *UserService) GetByID(id int) (*models.User, error) {
+func (s *UserService) GetByID(ctx context.Context, id int) (*models.User, error) {
 	if id <= 0 {
 		return nil, ErrInvalidUserData
 	}
-	return s.repo.GetByID(id)
+	return s.repo.GetByID(ctx, id)
 }
 
-// Create cria um novo usuário
-func (s *UserService) Create(req models.UserRequest) (*models.User, error) {
-	if req.Name == "" || req.Email == "" {
-		return nil, ErrInvalidUserData
+// Create cria um novo usuário. A unicidade do email é garantida pelo
+// repositório, que retorna ErrEmailExists se ele já estiver em uso.
+func (s *UserService) Create(ctx context.Context, req models.UserRequest) (*models.User, error) {
+	user, err := applyUserRequest(models.User{
+		Active:   true,
+		CreateAt: time.Now().UTC().Truncate(time.Second),
+	}, req)
+	if err != nil {
+		return nil, err
 	}
+	return s.repo.Create(ctx, user)
+}
 
-	// Verifica se o email já existe
-	users := s.repo.GetAll()
-	for _, u := range users {
-		if u.Email == req.Email {
-			return nil, ErrEmailExists
-		}
+// Update substitui todos os campos editáveis de um usuário existente; campos
+// omitidos assumem o valor vazio. Com ifMatch não nulo, a atualização só
+// ocorre se a versão atual estiver entre as informadas; caso contrário, ou se
+// o registro mudar durante a operação, retorna ErrVersionConflict
+func (s *UserService) Update(ctx context.Context, id int, req models.UserRequest, ifMatch []int) (*models.User, error) {
+	existing, err := s.current(ctx, id, ifMatch)
+	if err != nil {
+		return nil, err
 	}
+	return s.replace(ctx, *existing, req)
+}
 
-	user := models.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Role:     req.Role,
-		Active:   true,
-		CreateAt: time.Now().UTC().Format(time.RFC3339),
+// Patch altera parcialmente um usuário com um JSON Merge Patch (RFC 7396) ou
+// JSON Patch (RFC 6902), conforme mediaType. O patch é aplicado sobre os campos
+// editáveis; nulos explícitos são respeitados e o resultado é validado como em
+// Update.
+func (s *UserService) Patch(ctx context.Context, id int, mediaType string, body []byte, ifMatch []int) (*models.User, error) {
+	existing, err := s.current(ctx, id, ifMatch)
+	if err != nil {
+		return nil, err
 	}
 
-	if user.Role == "" {
-		user.Role = "user"
+	var req models.UserRequest
+	if err := applyPatch(userRequest(*existing), mediaType, body, &req, ErrInvalidUserData); err != nil {
+		return nil, err
 	}
-
-	created := s.repo.Create(user)
-	return &created, nil
+	return s.replace(ctx, *existing, req)
 }
 
-// Update atualiza um usuário existente
-func (s *UserService) Update(id int, req models.UserRequest) (*models.User, error) {
+// current retorna o usuário a ser alterado, verificando a versão exigida em ifMatch
+func (s *UserService) current(ctx context.Context, id int, ifMatch []int) (*models.User, error) {
 	if id <= 0 {
 		return nil, ErrInvalidUserData
 	}
 
-	existing, err := s.repo.GetByID(id)
+	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-
-	user := models.User{
-		ID:       existing.ID,
-		Name:     req.Name,
-		Email:    req.Email,
-		Role:     req.Role,
-		Active:   existing.Active,
-		CreateAt: existing.CreateAt,
+	if err := checkVersion(ifMatch, existing.Version); err != nil {
+		return nil, err
 	}
+	return existing, nil
+}
 
-	if user.Name == "" {
-		user.Name = existing.Name
+// replace grava os campos editáveis da requisição sobre o usuário existente
+func (s *UserService) replace(ctx context.Context, existing models.User, req models.UserRequest) (*models.User, error) {
+	user, err := applyUserRequest(existing, req)
+	if err != nil {
+		return nil, err
 	}
-	if user.Email == "" {
-		user.Email = existing.Email
+	return s.repo.Update(ctx, existing.ID, user)
+}
+
+// applyUserRequest valida a requisição e copia seus campos para o usuário.
+// Todas as violações são reportadas juntas em um *ValidationError.
+func applyUserRequest(user models.User, req models.UserRequest) (models.User, error) {
+	name := strings.TrimSpace(req.Name)
+	email := repositories.NormalizeEmail(req.Email)
+	role := req.Role
+	if role == "" {
+		role = "user"
 	}
-	if user.Role == "" {
-		user.Role = existing.Role
+
+	var v validator
+	v.length("name", name, minNameLength, maxNameLength)
+	v.email("email", email)
+	v.oneOf("role", role, userRoles)
+	if err := v.err(ErrInvalidUserData); err != nil {
+		return user, err
 	}
 
-	return s.repo.Update(id, user)
+	user.Name = name
+	user.Email = email
+	user.Role = role
+	return user, nil
+}
+
+// userRequest retorna os campos editáveis do usuário
+func userRequest(u models.User) models.UserRequest {
+	return models.UserRequest{Name: u.Name, Email: u.Email, Role: u.Role}
+}
+
+// Activate reativa um usuário; a operação é idempotente
+func (s *UserService) Activate(ctx context.Context, id int) (*models.User, error) {
+	return s.setActive(ctx, id, true)
+}
+
+// Deactivate desativa um usuário, removendo-o das listagens padrão; a operação é idempotente
+func (s *UserService) Deactivate(ctx context.Context, id int) (*models.User, error) {
+	return s.setActive(ctx, id, false)
+}
+
+// setActive altera o estado ativo de um usuário
+func (s *UserService) setActive(ctx context.Context, id int, active bool) (*models.User, error) {
+	if id <= 0 {
+		return nil, ErrInvalidUserData
+	}
+	return s.repo.SetActive(ctx, id, active)
 }
 
-// Delete remove um usuário
-func (s *UserService) Delete(id int) error {
+// Delete move um usuário para a lixeira, respeitando ifMatch como em Update
+func (s *UserService) Delete(ctx context.Context, id int, ifMatch []int) error {
 	if id <= 0 {
 		return ErrInvalidUserData
 	}
-	return s.repo.Delete(id)
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(ifMatch, existing.Version); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, existing.Version, time.Now().UTC().Truncate(time.Second))
+}
+
+// Restore retira um usuário da lixeira; retorna ErrEmailExists se o email
+// passou a ser usado por outro usuário
+func (s *UserService) Restore(ctx context.Context, id int) (*models.User, error) {
+	if id <= 0 {
+		return nil, ErrInvalidUserData
+	}
+	return s.repo.Restore(ctx, id)
+}
+
+// Purge remove definitivamente os usuários que estão na lixeira há mais que retention
+func (s *UserService) Purge(ctx context.Context, retention time.Duration) (int, error) {
+	return s.repo.Purge(ctx, time.Now().UTC().Add(-retention))
+}
+
+// list executa a consulta paginada e emite o cursor da próxima página
+func (s *UserService) list(ctx context.Context, query repositories.UserQuery, page models.Pagination) ([]models.User, models.PageInfo, error) {
+	sortKey := repositories.SortKey(query.Sort)
+	window, err := pageWindow(s.cursors, page, sortKey)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	query.Window = window
+
+	users, total, err := s.repo.List(ctx, query)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+	return finishPage(s.cursors, users, total, page, func(u models.User) models.Keyset {
+		return models.Keyset{Sort: sortKey, Values: repositories.UserSortValues(u, query.Sort), ID: u.ID}
+	})
 }
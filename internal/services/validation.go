@@ -0,0 +1,256 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/i18n"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+// Códigos das violações de validação
+const (
+	CodeRequired          = "required"
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeInvalidFormat     = "invalid_format"
+	CodeNotAllowed        = "not_allowed"
+	CodeMustBePositive    = "must_be_positive"
+	CodeTooLarge          = "too_large"
+	CodeTooManyDecimals   = "too_many_decimals"
+	CodeMustNotBeNegative = "must_not_be_negative"
+	CodeInvalidType       = "invalid_type"
+	CodeUnknownField      = "unknown_field"
+	CodeTooMany           = "too_many"
+	CodeDuplicate         = "duplicate"
+	CodeNotFound          = "not_found"
+	CodeInactive          = "inactive"
+	CodeCurrencyMismatch  = "currency_mismatch"
+	CodeCycle             = "cycle"
+	CodeManagedByVariants = "managed_by_variants"
+	CodeManagedByStock    = "managed_by_stock"
+	CodeInUse             = "in_use"
+	CodeNotApplicable     = "not_applicable"
+)
+
+// Limites das regras de validação
+const (
+	minNameLength        = 2
+	maxNameLength        = 100
+	maxEmailLength       = 254
+	maxDescriptionLength = 1000
+	maxCategoryLength    = 60
+
+	// maxMoneyAmount limita valores monetários, em unidades menores da
+	// moeda, para que subtotais e descontos caibam em int64
+	maxMoneyAmount = 99_999_999_999
+)
+
+// userRoles são os papéis aceitos para um usuário
+var userRoles = []string{"admin", "manager", "user"}
+
+// ValidationError reúne todas as violações encontradas em uma requisição.
+// Err identifica a entidade (ErrInvalidUserData ou ErrInvalidProductData),
+// então errors.Is continua funcionando com os erros sentinela.
+type ValidationError struct {
+	Err    error
+	Fields []models.FieldError
+}
+
+// Error resume as violações em uma única linha
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return e.Err.Error() + ": " + strings.Join(parts, "; ")
+}
+
+// Unwrap expõe o erro sentinela da entidade
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validator acumula as violações encontradas durante uma validação
+type validator struct {
+	fields []models.FieldError
+}
+
+// add registra uma violação; a mensagem é a de key no catálogo, formatada com args
+func (v *validator) add(field, code, key string, args ...interface{}) {
+	v.fields = append(v.fields, models.FieldError{
+		Field:      field,
+		Code:       code,
+		Message:    i18n.Message(i18n.Default, key, args...),
+		MessageKey: key,
+		Args:       args,
+	})
+}
+
+// length verifica o tamanho de um texto, em caracteres; min zero torna o campo opcional
+func (v *validator) length(field, value string, min, max int) {
+	n := utf8.RuneCountInString(value)
+	switch {
+	case n == 0 && min > 0:
+		v.add(field, CodeRequired, "field.required")
+	case n < min:
+		v.add(field, CodeTooShort, "field.too_short", min)
+	case n > max:
+		v.add(field, CodeTooLong, "field.too_long", max)
+	}
+}
+
+// email verifica se o valor é um endereço de email simples (sem nome de exibição)
+func (v *validator) email(field, value string) {
+	if value == "" {
+		v.add(field, CodeRequired, "field.required")
+		return
+	}
+	if len(value) > maxEmailLength {
+		v.add(field, CodeTooLong, "field.too_long", maxEmailLength)
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || !strings.Contains(value[strings.LastIndex(value, "@")+1:], ".") {
+		v.add(field, CodeInvalidFormat, "field.invalid_email")
+	}
+}
+
+// oneOf verifica se o valor está entre os permitidos
+func (v *validator) oneOf(field, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(field, CodeNotAllowed, "field.not_allowed", strings.Join(allowed, ", "))
+}
+
+// money verifica e converte um valor monetário positivo de até maxMoneyAmount;
+// sem moeda, assume models.DefaultCurrency. Moedas desconhecidas são
+// reportadas em field.currency.
+func (v *validator) money(field string, value models.MoneyRequest) models.Money {
+	amount := strings.TrimSpace(value.Amount)
+	currency := strings.ToUpper(strings.TrimSpace(value.Currency))
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	if amount == "" {
+		v.add(field, CodeRequired, "field.required")
+		return models.Money{}
+	}
+
+	money, err := models.ParseMoney(amount, currency)
+	switch {
+	case errors.Is(err, models.ErrUnknownCurrency):
+		v.add(field+".currency", CodeNotAllowed, "field.unknown_currency", currency)
+	case errors.Is(err, models.ErrTooManyDecimals):
+		exponent, _ := models.CurrencyExponent(currency)
+		v.add(field, CodeTooManyDecimals, "field.too_many_decimals", exponent)
+	case err != nil:
+		v.add(field, CodeInvalidFormat, "field.invalid_amount")
+	case money.Amount <= 0:
+		v.add(field, CodeMustBePositive, "field.must_be_positive")
+	case money.Amount > maxMoneyAmount:
+		v.add(field, CodeTooLarge, "field.too_large", models.Money{Amount: maxMoneyAmount, Currency: currency}.Decimal())
+	}
+	return money
+}
+
+// timestamp interpreta um instante no formato da RFC 3339, na precisão de
+// segundos; vazio resulta em nil, com violação se required
+func (v *validator) timestamp(field, value string, required bool) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if required {
+			v.add(field, CodeRequired, "field.required")
+		}
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.add(field, CodeInvalidFormat, "field.invalid_timestamp")
+		return nil
+	}
+	t = t.UTC().Truncate(time.Second)
+	return &t
+}
+
+// nonNegative verifica se o valor não é negativo
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, CodeMustNotBeNegative, "field.must_not_be_negative")
+	}
+}
+
+// atMost verifica se o valor não passa de max
+func (v *validator) atMost(field string, value, max int) {
+	if value > max {
+		v.add(field, CodeTooLarge, "field.too_large", max)
+	}
+}
+
+// subtotal soma price × quantity a total; se o resultado não couber em int64,
+// registra em field a maior quantidade que ainda caberia e retorna total
+func (v *validator) subtotal(field string, total, price models.Money, quantity int) models.Money {
+	subtotal, err := price.Times(quantity)
+	if err == nil {
+		if sum, err := total.Plus(subtotal); err == nil {
+			return sum
+		}
+	}
+	v.add(field, CodeTooLarge, "field.too_large", (math.MaxInt64-total.Amount)/price.Amount)
+	return total
+}
+
+// positive verifica se o valor é maior que zero
+func (v *validator) positive(field string, value int) {
+	if value <= 0 {
+		v.add(field, CodeMustBePositive, "field.must_be_positive")
+	}
+}
+
+// err retorna as violações como *ValidationError associado a base, ou nil se não houver
+func (v *validator) err(base error) error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Err: base, Fields: v.fields}
+}
+
+// decodeError converte um erro de decodificação JSON em violação de campo,
+// quando ele se refere a um campo específico
+func decodeError(err error, base error) error {
+	var v validator
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		v.add(typeErr.Field, CodeInvalidType, "field.invalid_type", jsonType(typeErr.Type.Kind().String()))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		v.add(field, CodeUnknownField, "field.unknown_field")
+	default:
+		return i18n.Errorf(base, "malformed_json", err.Error())
+	}
+	return v.err(base)
+}
+
+// jsonType converte o tipo Go esperado no nome traduzível do tipo JSON correspondente
+func jsonType(kind string) interface{} {
+	switch kind {
+	case "string":
+		return i18n.Text("type.string")
+	case "bool":
+		return i18n.Text("type.boolean")
+	case "int", "int64", "float64":
+		return i18n.Text("type.number")
+	default:
+		return kind
+	}
+}
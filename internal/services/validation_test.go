@@ -0,0 +1,79 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+)
+
+func TestValidatorMoney(t *testing.T) {
+	tests := []struct {
+		name  string
+		value models.MoneyRequest
+		want  models.Money
+		field string
+		code  string
+	}{
+		{"moeda padrão", models.MoneyRequest{Amount: "599.90"}, models.Money{Amount: 59990, Currency: "BRL"}, "", ""},
+		{"moeda em minúsculas", models.MoneyRequest{Amount: "10", Currency: "usd"}, models.Money{Amount: 1000, Currency: "USD"}, "", ""},
+		{"valor máximo", models.MoneyRequest{Amount: "999999999.99"}, models.Money{Amount: maxMoneyAmount, Currency: "BRL"}, "", ""},
+		{"acima do máximo", models.MoneyRequest{Amount: "1000000000.00"}, models.Money{}, "price", CodeTooLarge},
+		{"acima do máximo sem casas decimais", models.MoneyRequest{Amount: "100000000000", Currency: "JPY"}, models.Money{}, "price", CodeTooLarge},
+		{"maior valor representável", models.MoneyRequest{Amount: "92233720368547758.07"}, models.Money{}, "price", CodeTooLarge},
+		{"zero", models.MoneyRequest{Amount: "0"}, models.Money{}, "price", CodeMustBePositive},
+		{"negativo", models.MoneyRequest{Amount: "-1.00"}, models.Money{}, "price", CodeMustBePositive},
+		{"vazio", models.MoneyRequest{}, models.Money{}, "price", CodeRequired},
+		{"casas demais", models.MoneyRequest{Amount: "1.001"}, models.Money{}, "price", CodeTooManyDecimals},
+		{"moeda desconhecida", models.MoneyRequest{Amount: "1.00", Currency: "XYZ"}, models.Money{}, "price.currency", CodeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v validator
+			got := v.money("price", tt.value)
+			if tt.code == "" {
+				if len(v.fields) != 0 || got != tt.want {
+					t.Errorf("money: esperado %+v, obtido %+v com %+v", tt.want, got, v.fields)
+				}
+				return
+			}
+			if len(v.fields) != 1 || v.fields[0].Field != tt.field || v.fields[0].Code != tt.code {
+				t.Errorf("money: esperado %s em %s, obtido %+v", tt.code, tt.field, v.fields)
+			}
+		})
+	}
+}
+
+func TestValidatorSubtotal(t *testing.T) {
+	price := models.Money{Amount: maxMoneyAmount, Currency: "BRL"}
+	tests := []struct {
+		name     string
+		total    int64
+		quantity int
+		want     int64
+		max      int64
+	}{
+		{"soma o subtotal", 100, 2, 100 + 2*maxMoneyAmount, 0},
+		{"subtotal acima do limite", 0, 100_000_000, 0, 92233720},
+		{"soma acima do limite", 50_000_000 * maxMoneyAmount, 50_000_000, 50_000_000 * maxMoneyAmount, 42233720},
+		{"soma no limite", math.MaxInt64 - 2*maxMoneyAmount, 2, math.MaxInt64, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v validator
+			got := v.subtotal("quantity", models.Money{Amount: tt.total, Currency: "BRL"}, price, tt.quantity)
+			if got.Amount != tt.want {
+				t.Errorf("subtotal: esperado %d, obtido %d", tt.want, got.Amount)
+			}
+			if tt.max == 0 {
+				if len(v.fields) != 0 {
+					t.Errorf("subtotal: violações inesperadas %+v", v.fields)
+				}
+				return
+			}
+			if len(v.fields) != 1 || v.fields[0].Code != CodeTooLarge || v.fields[0].Args[0] != tt.max {
+				t.Errorf("subtotal: esperado %s com máximo %d, obtido %+v", CodeTooLarge, tt.max, v.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+var (
+	ErrInvalidVariantData = errors.New("dados da variante inválidos")
+	// ErrVariantNotFound, ErrSKUExists, ErrVariantRequired e ErrVariantReserved
+	// são os mesmos valores do repositório, para que errors.Is funcione em
+	// qualquer camada
+	ErrVariantNotFound = repositories.ErrVariantNotFound
+	ErrSKUExists       = repositories.ErrSKUExists
+	ErrVariantRequired = repositories.ErrVariantRequired
+	ErrVariantReserved = repositories.ErrVariantReserved
+)
+
+// Limites dos eixos de variação e das variantes
+const (
+	maxProductOptions = 3
+	maxOptionValues   = 30
+	maxOptionLength   = 40
+	maxSKULength      = 64
+)
+
+// skuPattern define o formato aceito para o SKU, já em maiúsculas
+var skuPattern = regexp.MustCompile(`^[A-Z0-9]+([-_.][A-Z0-9]+)*$`)
+
+// GetVariants retorna as variantes de um produto com o preço efetivo de cada uma
+func (s *ProductService) GetVariants(ctx context.Context, productID int) ([]models.Variant, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidProductData
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := s.repo.ListVariants(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		variants[i] = withVariantPrice(*product, variants[i])
+	}
+	return variants, nil
+}
+
+// GetVariant retorna uma variante de um produto com o preço efetivo
+func (s *ProductService) GetVariant(ctx context.Context, productID, variantID int) (*models.Variant, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidProductData
+	}
+	if variantID <= 0 {
+		return nil, ErrVariantNotFound
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := s.repo.GetVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	priced := withVariantPrice(*product, *variant)
+	return &priced, nil
+}
+
+// CreateVariant cria uma variante com um valor para cada eixo de variação do
+// produto. O estoque inicial é somado ao do produto; se o produto mudar
+// durante a operação, retorna ErrVersionConflict.
+func (s *ProductService) CreateVariant(ctx context.Context, productID int, req models.VariantRequest) (*models.Variant, error) {
+	product, variants, err := s.productWithVariants(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := applyVariantRequest(*product, variants, models.Variant{ProductID: productID}, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateVariant(ctx, variant, product.Version, stockChange(ctx, models.MovementAdjustment, "cadastro da variante "+variant.SKU))
+	if err != nil {
+		return nil, err
+	}
+	priced := withVariantPrice(*product, *created)
+	return &priced, nil
+}
+
+// UpdateVariant substitui todos os campos de uma variante; campos omitidos
+// assumem o valor vazio, e active omitido mantém a variante ativa. O estoque
+// não é alterado: stock deve ser o atual, e outros valores são recusados com
+// CodeManagedByStock.
+func (s *ProductService) UpdateVariant(ctx context.Context, productID, variantID int, req models.VariantRequest) (*models.Variant, error) {
+	product, variants, err := s.productWithVariants(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	i := variantIndex(variants, variantID)
+	if i < 0 {
+		return nil, ErrVariantNotFound
+	}
+	variant, err := applyVariantRequest(*product, variants, variants[i], req)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateVariant(ctx, variant, product.Version, stockChange(ctx, models.MovementAdjustment, "atualização da variante "+variant.SKU))
+	if err != nil {
+		return nil, err
+	}
+	priced := withVariantPrice(*product, *updated)
+	return &priced, nil
+}
+
+// DeleteVariant remove uma variante sem reservas vigentes, retirando seu
+// estoque do produto
+func (s *ProductService) DeleteVariant(ctx context.Context, productID, variantID int) error {
+	product, variants, err := s.productWithVariants(ctx, productID)
+	if err != nil {
+		return err
+	}
+	i := variantIndex(variants, variantID)
+	if i < 0 {
+		return ErrVariantNotFound
+	}
+	return s.repo.DeleteVariant(ctx, productID, variantID, product.Version, stockChange(ctx, models.MovementAdjustment, "exclusão da variante "+variants[i].SKU))
+}
+
+// productWithVariants retorna o produto e suas variantes
+func (s *ProductService) productWithVariants(ctx context.Context, productID int) (*models.Product, []models.Variant, error) {
+	if productID <= 0 {
+		return nil, nil, ErrInvalidProductData
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+	variants, err := s.repo.ListVariants(ctx, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return product, variants, nil
+}
+
+// itemVariant resolve a variante de um item de pedido ou de carrinho: ela é
+// obrigatória em produtos com eixos de variação e não existe nos demais. As
+// violações são registradas em v sob field e resultam em nil.
+func (s *ProductService) itemVariant(ctx context.Context, v *validator, field string, product models.Product, variantID int) (*models.Variant, error) {
+	switch {
+	case len(product.Options) > 0 && variantID == 0:
+		v.add(field, CodeRequired, "field.required")
+		return nil, nil
+	case variantID == 0:
+		return nil, nil
+	}
+
+	variant, err := s.GetVariant(ctx, product.ID, variantID)
+	switch {
+	case errors.Is(err, ErrVariantNotFound):
+		v.add(field, CodeNotFound, "field.not_found")
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case !variant.Active:
+		v.add(field, CodeInactive, "field.inactive")
+		return nil, nil
+	}
+	return variant, nil
+}
+
+// applyVariantRequest valida a requisição contra o produto e as demais
+// variantes e copia seus campos para a variante. Todas as violações são
+// reportadas juntas em um *ValidationError.
+func applyVariantRequest(product models.Product, variants []models.Variant, variant models.Variant, req models.VariantRequest) (models.Variant, error) {
+	sku := strings.ToUpper(strings.TrimSpace(req.SKU))
+
+	var v validator
+	switch {
+	case sku == "":
+		v.add("sku", CodeRequired, "field.required")
+	case len(sku) > maxSKULength:
+		v.add("sku", CodeTooLong, "field.too_long", maxSKULength)
+	case !skuPattern.MatchString(sku):
+		v.add("sku", CodeInvalidFormat, "field.invalid_sku")
+	}
+	v.nonNegative("stock", req.Stock)
+	// após a criação, o estoque da variante só muda pelas operações de
+	// estoque com variant_id, que respeitam as reservas
+	if variant.ID != 0 && req.Stock != variant.Stock {
+		v.add("stock", CodeManagedByStock, "field.managed_by_variant_stock", variant.ID, variant.Stock)
+	}
+
+	options := make(map[string]string, len(req.Options))
+	if len(product.Options) == 0 {
+		v.add("options", CodeNotAllowed, "field.no_product_options")
+	}
+	for _, axis := range product.Options {
+		value := strings.TrimSpace(req.Options[axis.Name])
+		switch {
+		case value == "":
+			v.add("options."+axis.Name, CodeRequired, "field.required")
+		case !containsString(axis.Values, value):
+			v.add("options."+axis.Name, CodeNotAllowed, "field.not_allowed", strings.Join(axis.Values, ", "))
+		default:
+			options[axis.Name] = value
+		}
+	}
+	for _, name := range sortedKeys(req.Options) {
+		if optionIndex(product.Options, name) < 0 {
+			v.add("options."+name, CodeUnknownField, "field.unknown_option")
+		}
+	}
+	if len(product.Options) > 0 && len(options) == len(product.Options) {
+		for _, other := range variants {
+			if other.ID != variant.ID && sameOptions(other.Options, options) {
+				v.add("options", CodeDuplicate, "field.duplicate_combination", other.SKU)
+				break
+			}
+		}
+	}
+
+	var override *models.Money
+	if req.PriceOverride != nil {
+		// sem moeda, o preço próprio usa a moeda do produto
+		requested := *req.PriceOverride
+		if strings.TrimSpace(requested.Currency) == "" {
+			requested.Currency = product.Price.Currency
+		}
+		price := v.money("price_override", requested)
+		if price.Currency != "" && price.Currency != product.Price.Currency {
+			v.add("price_override.currency", CodeCurrencyMismatch, "field.product_currency", price.Currency, product.Price.Currency)
+		}
+		override = &price
+	}
+	if err := v.err(ErrInvalidVariantData); err != nil {
+		return variant, err
+	}
+
+	variant.SKU = sku
+	variant.Options = options
+	variant.PriceOverride = override
+	variant.Stock = req.Stock
+	variant.Active = req.Active == nil || *req.Active
+	return variant, nil
+}
+
+// validateOptions normaliza os eixos de variação de um produto, registrando
+// as violações em v
+func validateOptions(v *validator, options []models.ProductOption) []models.ProductOption {
+	if len(options) > maxProductOptions {
+		v.add("options", CodeTooMany, "field.too_many_items", maxProductOptions)
+	}
+
+	normalized := make([]models.ProductOption, 0, len(options))
+	for i, option := range options {
+		field := fmt.Sprintf("options[%d]", i)
+		name := strings.TrimSpace(option.Name)
+		v.length(field+".name", name, 1, maxOptionLength)
+		if optionIndex(normalized, name) >= 0 {
+			v.add(field+".name", CodeDuplicate, "field.duplicate")
+		}
+
+		switch {
+		case len(option.Values) == 0:
+			v.add(field+".values", CodeRequired, "field.required")
+		case len(option.Values) > maxOptionValues:
+			v.add(field+".values", CodeTooMany, "field.too_many_items", maxOptionValues)
+		}
+		values := make([]string, 0, len(option.Values))
+		for j, value := range option.Values {
+			valueField := fmt.Sprintf("%s.values[%d]", field, j)
+			value = strings.TrimSpace(value)
+			v.length(valueField, value, 1, maxOptionLength)
+			if containsString(values, value) {
+				v.add(valueField, CodeDuplicate, "field.duplicate")
+			}
+			values = append(values, value)
+		}
+		normalized = append(normalized, models.ProductOption{Name: name, Values: values})
+	}
+	return normalized
+}
+
+// checkVariants verifica se os novos eixos, o estoque e a moeda do produto
+// são compatíveis com as variantes existentes: com eixos de variação, o
+// estoque deve ser a soma do estoque das variantes, cada variante deve ter
+// um valor válido para cada eixo e preços próprios fixam a moeda
+func checkVariants(v *validator, product models.Product, variants []models.Variant) {
+	if len(product.Options) > 0 {
+		total := 0
+		for _, variant := range variants {
+			total += variant.Stock
+		}
+		if product.Stock != total {
+			v.add("stock", CodeManagedByVariants, "field.managed_by_variants", total)
+		}
+	}
+
+	misfits, overrides := 0, 0
+	for _, variant := range variants {
+		if !fitsOptions(product.Options, variant.Options) {
+			misfits++
+		}
+		if variant.PriceOverride != nil && product.Price.Currency != "" && variant.PriceOverride.Currency != product.Price.Currency {
+			overrides++
+		}
+	}
+	if misfits > 0 {
+		v.add("options", CodeInUse, "field.in_use_by_variants", misfits)
+	}
+	if overrides > 0 {
+		v.add("price.currency", CodeInUse, "field.in_use_by_variants", overrides)
+	}
+}
+
+// withVariantPrice preenche o preço efetivo da variante: o preço próprio ou,
+// sem ele, o preço do produto
+func withVariantPrice(product models.Product, variant models.Variant) models.Variant {
+	variant.Price = product.Price
+	if variant.PriceOverride != nil {
+		variant.Price = *variant.PriceOverride
+	}
+	return variant
+}
+
+// fitsOptions indica se as opções da variante têm exatamente um valor válido
+// para cada eixo
+func fitsOptions(axes []models.ProductOption, options map[string]string) bool {
+	if len(options) != len(axes) {
+		return false
+	}
+	for _, axis := range axes {
+		if !containsString(axis.Values, options[axis.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameOptions indica se duas variantes têm a mesma combinação de valores
+func sameOptions(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if b[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// optionIndex retorna a posição do eixo com o nome, ou -1
+func optionIndex(options []models.ProductOption, name string) int {
+	for i := range options {
+		if options[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// variantIndex retorna a posição da variante com o ID, ou -1
+func variantIndex(variants []models.Variant, id int) int {
+	for i := range variants {
+		if variants[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsString indica se value está entre os valores informados
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
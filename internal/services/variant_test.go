@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/models"
+	"github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+)
+
+func TestUpdateVariantKeepsStock(t *testing.T) {
+	ctx := context.Background()
+	f := newServiceFixture(t)
+	product, err := f.stock.Create(ctx, models.Product{
+		Name:       "Teclado Variado",
+		Price:      models.Money{Amount: 19990, Currency: "BRL"},
+		CategoryID: 1,
+		Active:     true,
+		Options:    []models.ProductOption{{Name: "Cor", Values: []string{"Preto", "Branco"}}},
+	}, repositories.StockChange{Type: models.MovementAdjustment, At: time.Now().UTC()})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	variant, err := f.products.CreateVariant(ctx, product.ID, models.VariantRequest{SKU: "TEC-PRETO", Options: map[string]string{"Cor": "Preto"}, Stock: 5})
+	if err != nil {
+		t.Fatalf("CreateVariant: %v", err)
+	}
+
+	// omitir stock equivale a informar zero
+	_, err = f.products.UpdateVariant(ctx, product.ID, variant.ID, models.VariantRequest{SKU: "TEC-PRETO", Options: map[string]string{"Cor": "Preto"}})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || !errors.Is(err, ErrInvalidVariantData) {
+		t.Fatalf("UpdateVariant sem stock: esperado *ValidationError, obtido %v", err)
+	}
+	if len(validation.Fields) != 1 || validation.Fields[0].Field != "stock" || validation.Fields[0].Code != CodeManagedByStock {
+		t.Errorf("UpdateVariant sem stock: violações inesperadas %+v", validation.Fields)
+	}
+	if _, err := f.products.UpdateVariant(ctx, product.ID, variant.ID, models.VariantRequest{SKU: "TEC-PRETO", Options: map[string]string{"Cor": "Preto"}, Stock: 8}); !errors.As(err, &validation) {
+		t.Errorf("UpdateVariant com outro stock: esperado *ValidationError, obtido %v", err)
+	}
+
+	updated, err := f.products.UpdateVariant(ctx, product.ID, variant.ID, models.VariantRequest{SKU: "TEC-PRETO-2", Options: map[string]string{"Cor": "Preto"}, Stock: 5})
+	if err != nil {
+		t.Fatalf("UpdateVariant: %v", err)
+	}
+	if updated.SKU != "TEC-PRETO-2" || updated.Stock != 5 {
+		t.Errorf("UpdateVariant: variante inesperada %+v", updated)
+	}
+	if got := f.stockOf(t, product.ID); got != 5 {
+		t.Errorf("UpdateVariant: esperado estoque 5 no produto, obtido %d", got)
+	}
+}
@@ -0,0 +1,20 @@
+package services
+
+import "github.com/CristianSsousa/go-api-actions-ci-cd/internal/repositories"
+
+// ErrVersionConflict indica que o registro foi alterado desde a versão informada
+var ErrVersionConflict = repositories.ErrVersionConflict
+
+// checkVersion retorna ErrVersionConflict se a versão atual não estiver entre
+// as aceitas em ifMatch; ifMatch nulo dispensa a verificação
+func checkVersion(ifMatch []int, current int) error {
+	if ifMatch == nil {
+		return nil
+	}
+	for _, v := range ifMatch {
+		if v == current {
+			return nil
+		}
+	}
+	return ErrVersionConflict
+}